@@ -0,0 +1,211 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"math/bits"
+)
+
+// BitCounter is implemented by generators whose Int values are uniformly
+// distributed on a known count of low bits
+type BitCounter interface{ Bits() uint }
+
+// BitGenerator assembles full-precision values from the random bits of an
+// IntGenerator, the most significant bits of every value are used first
+//
+// unlike UniformGenerator, which divides Int() by the modulus, the values of
+// Float64 have 53 random bits whatever the width of the source and integer
+// ranges are unbiased
+type BitGenerator struct {
+	name GeneratorName
+	g    IntGenerator
+	bits uint
+	mask uint64
+}
+
+func (bg *BitGenerator) Name() string {
+	return string(bg.name)
+}
+
+func (bg *BitGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = bg.name
+	d["bits"] = bg.bits
+
+	describeSource(d, "source", bg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewBitGeneratorDefault() *BitGenerator {
+	return NewBitGeneratorWithBits(newDefaultGenerator(), 63)
+}
+
+// NewBitGenerator reads the count of random bits of generator from its Bits
+// method, generators which don't report it (or report 0) must be adapted with
+// NewBitGeneratorWithBits
+func NewBitGenerator(generator IntGenerator) (*BitGenerator, error) {
+	bc, ok := generator.(BitCounter)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%T doesn't report its bits count", generator)
+	}
+
+	n := bc.Bits()
+	if n < 1 || n > 64 {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%T produces %d uniform bits", generator, n)
+	}
+
+	return NewBitGeneratorWithBits(generator, n), nil
+}
+
+// NewBitGeneratorWithBits adapts a generator whose Int values are uniform on
+// the bits low bits, e.g. 32 for a congruential generator with modulus 2^32
+func NewBitGeneratorWithBits(generator IntGenerator, bits uint) *BitGenerator {
+	if bits < 1 || bits > 64 {
+		panic(fmt.Sprintf("bits count %d is out of range [1, 64]", bits))
+	}
+
+	return &BitGenerator{name: UniformBits, g: generator, bits: bits, mask: math.MaxUint64 >> (64 - bits)}
+}
+
+// next returns n random bits, 1 <= n <= 64
+func (bg *BitGenerator) next(n uint) uint64 {
+	v := uint64(0)
+
+	for got := uint(0); got < n; {
+		x := uint64(bg.g.Int()) & bg.mask
+
+		if r := n - got; r < bg.bits {
+			// the high bits of the last value complete the result
+			return v<<r | x>>(bg.bits-r)
+		}
+
+		v = v<<bg.bits | x
+		got += bg.bits
+	}
+
+	return v
+}
+
+func (bg *BitGenerator) Uint32() uint32 {
+	return uint32(bg.next(32))
+}
+
+func (bg *BitGenerator) Uint64() uint64 {
+	return bg.next(64)
+}
+
+// Int returns 63 random bits, BitGenerator is a BitCounter itself
+func (bg *BitGenerator) Int() int {
+	return int(bg.next(63))
+}
+
+func (bg *BitGenerator) Bits() uint {
+	return 63
+}
+
+// SourceBits returns the count of random bits of the source values
+func (bg *BitGenerator) SourceBits() uint {
+	return bg.bits
+}
+
+// Float64 returns values in [0, 1) on the grid of 2^53 values
+func (bg *BitGenerator) Float64() float64 {
+	return float64(bg.next(53)) / (1 << 53)
+}
+
+// OpenFloat64 returns values in (0, 1) on the grid of 2^53 values, 0 is
+// rejected, so the logarithm of the values is always finite
+func (bg *BitGenerator) OpenFloat64() float64 {
+	for {
+		if v := bg.next(53); v != 0 {
+			return float64(v) / (1 << 53)
+		}
+	}
+}
+
+// Intn returns unbiased values in [0, n) with Lemire's multiply-and-reject
+// method, panics if n <= 0
+func (bg *BitGenerator) Intn(n int) int {
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+
+	return int(bg.uint64n(uint64(n)))
+}
+
+// uint64n returns unbiased values in [0, n), n > 0
+func (bg *BitGenerator) uint64n(n uint64) uint64 {
+	hi, lo := bits.Mul64(bg.Uint64(), n)
+
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(bg.Uint64(), n)
+		}
+	}
+
+	return hi
+}
+
+// Float64Range returns values in [a, b), panics if the range is empty or not
+// finite
+func (bg *BitGenerator) Float64Range(a float64, b float64) float64 {
+	if !(a < b) || math.IsInf(a, 0) || math.IsInf(b, 0) || math.IsInf(b-a, 0) {
+		panic(fmt.Sprintf("invalid range [%v, %v)", a, b))
+	}
+
+	// rounding may reach b
+	if x := a + (b-a)*bg.Float64(); x < b {
+		return x
+	}
+
+	return math.Nextafter(b, a)
+}
+
+type bitState struct {
+	Name   GeneratorName   `json:"distributionName"`
+	Bits   uint            `json:"bits"`
+	Source json.RawMessage `json:"source"`
+}
+
+func (bg *BitGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(bg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(bitState{Name: UniformBits, Bits: bg.bits, Source: src})
+}
+
+func (bg *BitGenerator) UnmarshalBinary(data []byte) error {
+	var s bitState
+	if err := unmarshalState(data, UniformBits, &s); err != nil {
+		return err
+	}
+
+	if s.Bits < 1 || s.Bits > 64 {
+		return errors.Wrapf(ErrInvalidState, "bits count %d is out of range [1, 64]", s.Bits)
+	}
+
+	src, err := unmarshalSource(bg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	g, ok := src.(IntGenerator)
+	if !ok {
+		return errors.Wrap(ErrInvalidState, "source is not an int generator")
+	}
+
+	*bg = *NewBitGeneratorWithBits(g, s.Bits)
+
+	return nil
+}
@@ -14,7 +14,7 @@ func main() {
 	modulus := int(math.Pow(2, 32))
 	cg := generators.NewCongruentialGenerator(modulus, 1103515245, 12345, 0)
 	ug := generators.NewUniformGenerator(cg, modulus)
-	eg := generators.NewExponentialGenerator(ug, 345)
+	eg := generators.NewExponentialGenerator(ug, 1.0/345)
 
 	// second pair of independent CG and UG for normal distribution
 	cg2 := generators.NewCongruentialGenerator(modulus, 134775813, 1, 3)
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sinu5oid/generators"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"log"
+	"math"
+)
+
+func main() {
+	pointsCount := int(math.Pow(10, 4))
+	maxDimension := 8
+
+	modulus := int(math.Pow(2, 31))
+	multipliers := []int{65539, 1103515245, 1664525, 134775813, 69069}
+
+	ranks, err := generators.RankMultipliers(modulus, multipliers, maxDimension)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("spectral test, modulus %d\n", modulus)
+	for _, r := range ranks {
+		fmt.Printf("multiplier %d, min merit %.4f\n", r.Multiplier, r.MinMerit)
+
+		for _, d := range r.Dimensions {
+			fmt.Printf("\tt = %d\tν = %.2f\tμ = %.4f\n", d.Dimension, d.Nu, d.Merit)
+		}
+	}
+
+	for _, a := range []int{65539, 1103515245} {
+		cg := generators.NewCongruentialGenerator(modulus, a, 0, 1)
+
+		if err := renderLatticePlot2D(cg, modulus, pointsCount, fmt.Sprintf("lattice-2d-%d.png", a)); err != nil {
+			log.Panic(err)
+		}
+
+		// RANDU triples lie on 15 planes with normal (9, -6, 1),
+		// the view is chosen to look along them
+		if err := renderLatticePlot3D(cg, modulus, pointsCount, 0.59, 0.1, fmt.Sprintf("lattice-3d-%d.png", a)); err != nil {
+			log.Panic(err)
+		}
+	}
+}
+
+// renderLatticePlot2D plots pairs (x_i, x_{i+1}) of consecutive normalized outputs
+func renderLatticePlot2D(g generators.IntGenerator, modulus int, pointsCount int, filename string) error {
+	points := make(plotter.XYs, 0, pointsCount)
+
+	prev := float64(g.Int()) / float64(modulus)
+	for i := 0; i < pointsCount; i += 1 {
+		next := float64(g.Int()) / float64(modulus)
+		points = append(points, plotter.XY{X: prev, Y: next})
+		prev = next
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+	p.Title.Text = fmt.Sprintf("Consecutive pairs\n%s", g)
+	p.X.Label.Text = "x(i)"
+	p.Y.Label.Text = "x(i+1)"
+
+	s, err := plotter.NewScatter(points)
+	if err != nil {
+		return err
+	}
+	s.GlyphStyle.Radius = vg.Points(1)
+
+	p.Add(s)
+
+	fmt.Println("artifact:", filename)
+
+	return p.Save(10*vg.Inch, 10*vg.Inch, filename)
+}
+
+// renderLatticePlot3D plots triples (x_i, x_{i+1}, x_{i+2}) of consecutive
+// normalized outputs, projected orthographically after rotating the unit
+// cube around Z by azimuth and around X by elevation (radians)
+func renderLatticePlot3D(
+	g generators.IntGenerator,
+	modulus int,
+	pointsCount int,
+	azimuth float64,
+	elevation float64,
+	filename string,
+) error {
+	points := make(plotter.XYs, 0, pointsCount)
+
+	x, y := float64(g.Int())/float64(modulus), float64(g.Int())/float64(modulus)
+	for i := 0; i < pointsCount; i += 1 {
+		z := float64(g.Int()) / float64(modulus)
+
+		// rotate around Z
+		rx := x*math.Cos(azimuth) - y*math.Sin(azimuth)
+		ry := x*math.Sin(azimuth) + y*math.Cos(azimuth)
+
+		// rotate around X and drop the depth
+		py := ry*math.Sin(elevation) + z*math.Cos(elevation)
+
+		points = append(points, plotter.XY{X: rx, Y: py})
+		x, y = y, z
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+	p.Title.Text = fmt.Sprintf("Consecutive triples, azimuth %.2f, elevation %.2f\n%s", azimuth, elevation, g)
+	p.HideAxes()
+
+	s, err := plotter.NewScatter(points)
+	if err != nil {
+		return err
+	}
+	s.GlyphStyle.Radius = vg.Points(1)
+
+	p.Add(s)
+
+	fmt.Println("artifact:", filename)
+
+	return p.Save(10*vg.Inch, 10*vg.Inch, filename)
+}
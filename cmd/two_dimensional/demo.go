@@ -77,4 +77,60 @@ func main() {
 	if err != nil {
 		log.Panic(err)
 	}
+
+	// copulas with normal marginals
+	marginalX := generators.NewNormalGenerator(ug, ug2, 1, 0)
+	marginalY := generators.NewNormalGenerator(ug, ug2, 1, 0)
+
+	copulas := make(map[string]*generators.CopulaGenerator, 5)
+	copulas["gaussian (r = 0.7)"], err = generators.NewGaussianCopulaGenerator(ug, ug2, marginalX, marginalY, 0.7)
+	if err != nil {
+		log.Panic(err)
+	}
+	copulas["student-t (r = 0.7, nu = 3)"], err = generators.NewStudentTCopulaGenerator(ug, ug2, marginalX, marginalY, 0.7, 3)
+	if err != nil {
+		log.Panic(err)
+	}
+	copulas["clayton (theta = 2)"], err = generators.NewClaytonCopulaGenerator(ug, ug2, marginalX, marginalY, 2)
+	if err != nil {
+		log.Panic(err)
+	}
+	copulas["gumbel (theta = 2)"], err = generators.NewGumbelCopulaGenerator(ug, ug2, marginalX, marginalY, 2)
+	if err != nil {
+		log.Panic(err)
+	}
+	copulas["frank (theta = 8)"], err = generators.NewFrankCopulaGenerator(ug, ug2, marginalX, marginalY, 8)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	for title, cg := range copulas {
+		distr := make(generators.FloatPairs, 0, maxIterations)
+		for i := 0; i < maxIterations; i += 1 {
+			distr = append(distr, cg.TwoDimensionalFloat64s())
+		}
+
+		p, err := plot.New()
+		if err != nil {
+			fmt.Printf("can't create plot: %s\n", err)
+			return
+		}
+		p.Title.Text = "Copula: " + title
+		p.X.Label.Text = "X"
+		p.Y.Label.Text = "Y"
+		p.Add(plotter.NewGrid())
+
+		s, err := plotter.NewScatter(distr)
+		if err != nil {
+			log.Panic(err)
+		}
+		s.GlyphStyle.Color = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+		s.GlyphStyle.Radius = vg.Points(1)
+		p.Add(s)
+
+		err = p.Save(10*vg.Inch, 10*vg.Inch, fmt.Sprintf("copula-%s.png", cg.Family()))
+		if err != nil {
+			log.Panic(err)
+		}
+	}
 }
@@ -0,0 +1,436 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+)
+
+// WichmannHillGenerator is the Wichmann–Hill (AS 183) generator combining
+// three small multiplicative congruential generators
+type WichmannHillGenerator struct {
+	name    GeneratorName
+	initial [3]uint64
+	s       [3]uint64
+}
+
+var (
+	wichmannHillModuli      = [3]uint64{30269, 30307, 30323}
+	wichmannHillMultipliers = [3]uint64{171, 172, 170}
+)
+
+// NewWichmannHillGenerator takes three seeds, each of them is reduced by the
+// modulus of its component and must not become 0
+func NewWichmannHillGenerator(seed1 uint64, seed2 uint64, seed3 uint64) (*WichmannHillGenerator, error) {
+	g := &WichmannHillGenerator{name: WichmannHill}
+
+	for i, s := range [3]uint64{seed1, seed2, seed3} {
+		g.s[i] = s % wichmannHillModuli[i]
+		if g.s[i] == 0 {
+			return nil, errors.Wrapf(ErrInvalidParameters, "seed %d is a multiple of %d", i+1, wichmannHillModuli[i])
+		}
+	}
+
+	g.initial = g.s
+
+	return g, nil
+}
+
+func (g *WichmannHillGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *WichmannHillGenerator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seeds"] = g.initial
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Float64 returns values in [0, 1)
+func (g *WichmannHillGenerator) Float64() float64 {
+	sum := 0.0
+
+	for i := range g.s {
+		g.s[i] = g.s[i] * wichmannHillMultipliers[i] % wichmannHillModuli[i]
+		sum += float64(g.s[i]) / float64(wichmannHillModuli[i])
+	}
+
+	_, frac := math.Modf(sum)
+
+	return frac
+}
+
+// Int returns Float64() scaled to [0, 2^32)
+func (g *WichmannHillGenerator) Int() int {
+	return int(g.Float64() * (1 << 32))
+}
+
+const (
+	lEcuyerModulus1    = 2147483563
+	lEcuyerModulus2    = 2147483399
+	lEcuyerMultiplier1 = 40014
+	lEcuyerMultiplier2 = 40692
+)
+
+// LEcuyerGenerator is L'Ecuyer's (1988) combination of two multiplicative
+// congruential generators, its period is about 2.3*10^18
+type LEcuyerGenerator struct {
+	name    GeneratorName
+	initial [2]uint64
+	s1      uint64
+	s2      uint64
+}
+
+func NewLEcuyerGenerator(seed1 uint64, seed2 uint64) (*LEcuyerGenerator, error) {
+	s1, s2 := seed1%lEcuyerModulus1, seed2%lEcuyerModulus2
+	if s1 == 0 || s2 == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "seeds reduce to 0")
+	}
+
+	return &LEcuyerGenerator{name: LEcuyer, initial: [2]uint64{s1, s2}, s1: s1, s2: s2}, nil
+}
+
+func (g *LEcuyerGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *LEcuyerGenerator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seeds"] = g.initial
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Int returns values in [1, 2147483562]
+func (g *LEcuyerGenerator) Int() int {
+	g.s1 = g.s1 * lEcuyerMultiplier1 % lEcuyerModulus1
+	g.s2 = g.s2 * lEcuyerMultiplier2 % lEcuyerModulus2
+
+	z := int(g.s1) - int(g.s2)
+	if z < 1 {
+		z += lEcuyerModulus1 - 1
+	}
+
+	return z
+}
+
+// Float64 returns values in (0, 1)
+func (g *LEcuyerGenerator) Float64() float64 {
+	return float64(g.Int()) / lEcuyerModulus1
+}
+
+const (
+	mrgModulus1 = 4294967087
+	mrgModulus2 = 4294944443
+	mrgNorm     = 1.0 / (mrgModulus1 + 1)
+)
+
+type mrgMatrix [3][3]uint64
+
+var (
+	// one step transition matrices of the two components
+	mrgA1 = mrgMatrix{{0, 1, 0}, {0, 0, 1}, {mrgModulus1 - 810728, 1403580, 0}}
+	mrgA2 = mrgMatrix{{0, 1, 0}, {0, 0, 1}, {mrgModulus2 - 1370589, 0, 527612}}
+
+	// published jump matrices A^(2^76) (substream) and A^(2^127) (stream)
+	mrgA1p76 = mrgMatrix{
+		{82758667, 1871391091, 4127413238},
+		{3672831523, 69195019, 1871391091},
+		{3672091415, 3528743235, 69195019},
+	}
+	mrgA2p76 = mrgMatrix{
+		{1511326704, 3759209742, 1610795712},
+		{4292754251, 1511326704, 3889917532},
+		{3859662829, 4292754251, 3708466080},
+	}
+	mrgA1p127 = mrgMatrix{
+		{2427906178, 3580155704, 949770784},
+		{226153695, 1230515664, 3580155704},
+		{1988835001, 986791581, 1230515664},
+	}
+	mrgA2p127 = mrgMatrix{
+		{1464411153, 277697599, 1610723613},
+		{32183930, 1464411153, 1022607788},
+		{2824425944, 32183930, 2093834863},
+	}
+)
+
+// MRG32k3aGenerator is L'Ecuyer's combined multiple recursive generator
+// MRG32k3a with the stream/substream structure of RngStreams: streams are
+// 2^127 steps apart, each of them is split into substreams of 2^76 steps
+type MRG32k3aGenerator struct {
+	name GeneratorName
+	seed [6]uint64
+	// current state
+	c1, c2 [3]uint64
+	// start of the current substream
+	b1, b2 [3]uint64
+	// start of the stream
+	i1, i2 [3]uint64
+}
+
+// NewMRG32k3aGenerator validates the seed: the first three values must be
+// less than 4294967087, the last three less than 4294944443 and neither
+// triple may be all zeros; L'Ecuyer's default seed is six times 12345
+func NewMRG32k3aGenerator(seed [6]uint64) (*MRG32k3aGenerator, error) {
+	for i, s := range seed {
+		if (i < 3 && s >= mrgModulus1) || (i >= 3 && s >= mrgModulus2) {
+			return nil, errors.Wrapf(ErrInvalidParameters, "seed component %d is out of range", i)
+		}
+	}
+
+	if seed[0] == 0 && seed[1] == 0 && seed[2] == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "first seed triple is zero")
+	}
+
+	if seed[3] == 0 && seed[4] == 0 && seed[5] == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "second seed triple is zero")
+	}
+
+	g := &MRG32k3aGenerator{name: MRG32k3a, seed: seed}
+	copy(g.i1[:], seed[:3])
+	copy(g.i2[:], seed[3:])
+	g.ResetStream()
+
+	return g, nil
+}
+
+// NewMRG32k3aStreams returns count generators on consecutive streams, the
+// first one starts at seed
+func NewMRG32k3aStreams(seed [6]uint64, count int) ([]*MRG32k3aGenerator, error) {
+	g, err := NewMRG32k3aGenerator(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make([]*MRG32k3aGenerator, 0, count)
+	for i := 0; i < count; i += 1 {
+		streams = append(streams, g)
+
+		next := *g
+		next.NextStream()
+		g = &next
+	}
+
+	return streams, nil
+}
+
+func (g *MRG32k3aGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *MRG32k3aGenerator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Int returns values in [1, 4294967087]
+func (g *MRG32k3aGenerator) Int() int {
+	p1 := addMod(mulMod(1403580, g.c1[1], mrgModulus1), mulMod(mrgModulus1-810728, g.c1[0], mrgModulus1), mrgModulus1)
+	g.c1[0], g.c1[1], g.c1[2] = g.c1[1], g.c1[2], p1
+
+	p2 := addMod(mulMod(527612, g.c2[2], mrgModulus2), mulMod(mrgModulus2-1370589, g.c2[0], mrgModulus2), mrgModulus2)
+	g.c2[0], g.c2[1], g.c2[2] = g.c2[1], g.c2[2], p2
+
+	if p1 > p2 {
+		return int(p1 - p2)
+	}
+
+	return int(p1 + mrgModulus1 - p2)
+}
+
+// Float64 returns values in (0, 1)
+func (g *MRG32k3aGenerator) Float64() float64 {
+	return float64(g.Int()) * mrgNorm
+}
+
+// NextSubstream moves to the start of the next substream
+func (g *MRG32k3aGenerator) NextSubstream() {
+	g.b1 = mrgA1p76.apply(g.b1, mrgModulus1)
+	g.b2 = mrgA2p76.apply(g.b2, mrgModulus2)
+	g.c1, g.c2 = g.b1, g.b2
+}
+
+// ResetSubstream moves back to the start of the current substream
+func (g *MRG32k3aGenerator) ResetSubstream() {
+	g.c1, g.c2 = g.b1, g.b2
+}
+
+// NextStream moves to the start of the next stream
+func (g *MRG32k3aGenerator) NextStream() {
+	g.i1 = mrgA1p127.apply(g.i1, mrgModulus1)
+	g.i2 = mrgA2p127.apply(g.i2, mrgModulus2)
+
+	g.seed = [6]uint64{g.i1[0], g.i1[1], g.i1[2], g.i2[0], g.i2[1], g.i2[2]}
+	g.ResetStream()
+}
+
+// ResetStream moves back to the start of the stream
+func (g *MRG32k3aGenerator) ResetStream() {
+	g.b1, g.b2 = g.i1, g.i2
+	g.c1, g.c2 = g.i1, g.i2
+}
+
+func (a mrgMatrix) apply(v [3]uint64, modulus uint64) [3]uint64 {
+	var r [3]uint64
+
+	for i := 0; i < 3; i += 1 {
+		for j := 0; j < 3; j += 1 {
+			r[i] = addMod(r[i], mulMod(a[i][j], v[j], modulus), modulus)
+		}
+	}
+
+	return r
+}
+
+type combinedState struct {
+	Name  GeneratorName `json:"distributionName"`
+	Seed  []uint64      `json:"seed"`
+	State []uint64      `json:"state"`
+}
+
+// validateComponents checks the values of congruential components against
+// their moduli, a multiplicative component at 0 never leaves it
+func validateComponents(values []uint64, moduli []uint64) error {
+	for i, v := range values {
+		if v == 0 || v >= moduli[i] {
+			return errors.Wrapf(ErrInvalidState, "component %d is out of range [1, %d)", i, moduli[i])
+		}
+	}
+
+	return nil
+}
+
+// validateMRGTriples checks the triples of MRG32k3a states, the triples
+// alternate between the first and the second component
+func validateMRGTriples(values []uint64) error {
+	for t := 0; t < len(values)/3; t += 1 {
+		modulus := uint64(mrgModulus1)
+		if t%2 == 1 {
+			modulus = mrgModulus2
+		}
+
+		triple := values[t*3 : t*3+3]
+		for i, v := range triple {
+			if v >= modulus {
+				return errors.Wrapf(ErrInvalidState, "value %d of triple %d is out of range [0, %d)", i, t, modulus)
+			}
+		}
+
+		if triple[0] == 0 && triple[1] == 0 && triple[2] == 0 {
+			return errors.Wrapf(ErrInvalidState, "triple %d is zero", t)
+		}
+	}
+
+	return nil
+}
+
+func (g *WichmannHillGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(combinedState{Name: WichmannHill, Seed: g.initial[:], State: g.s[:]})
+}
+
+func (g *WichmannHillGenerator) UnmarshalBinary(data []byte) error {
+	var s combinedState
+	if err := unmarshalState(data, WichmannHill, &s); err != nil {
+		return err
+	}
+
+	if len(s.Seed) != len(g.initial) || len(s.State) != len(g.s) {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	for _, values := range [][]uint64{s.Seed, s.State} {
+		if err := validateComponents(values, wichmannHillModuli[:]); err != nil {
+			return err
+		}
+	}
+
+	g.name = WichmannHill
+	copy(g.initial[:], s.Seed)
+	copy(g.s[:], s.State)
+
+	return nil
+}
+
+func (g *LEcuyerGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(combinedState{Name: LEcuyer, Seed: g.initial[:], State: []uint64{g.s1, g.s2}})
+}
+
+func (g *LEcuyerGenerator) UnmarshalBinary(data []byte) error {
+	var s combinedState
+	if err := unmarshalState(data, LEcuyer, &s); err != nil {
+		return err
+	}
+
+	if len(s.Seed) != len(g.initial) || len(s.State) != 2 {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	for _, values := range [][]uint64{s.Seed, s.State} {
+		if err := validateComponents(values, []uint64{lEcuyerModulus1, lEcuyerModulus2}); err != nil {
+			return err
+		}
+	}
+
+	g.name = LEcuyer
+	copy(g.initial[:], s.Seed)
+	g.s1, g.s2 = s.State[0], s.State[1]
+
+	return nil
+}
+
+func (g *MRG32k3aGenerator) MarshalBinary() ([]byte, error) {
+	state := make([]uint64, 0, 18)
+	for _, part := range [][3]uint64{g.c1, g.c2, g.b1, g.b2, g.i1, g.i2} {
+		state = append(state, part[:]...)
+	}
+
+	return json.Marshal(combinedState{Name: MRG32k3a, Seed: g.seed[:], State: state})
+}
+
+func (g *MRG32k3aGenerator) UnmarshalBinary(data []byte) error {
+	var s combinedState
+	if err := unmarshalState(data, MRG32k3a, &s); err != nil {
+		return err
+	}
+
+	if len(s.Seed) != len(g.seed) || len(s.State) != 18 {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	for _, values := range [][]uint64{s.Seed, s.State} {
+		if err := validateMRGTriples(values); err != nil {
+			return err
+		}
+	}
+
+	g.name = MRG32k3a
+	copy(g.seed[:], s.Seed)
+	for i, part := range []*[3]uint64{&g.c1, &g.c2, &g.b1, &g.b2, &g.i1, &g.i2} {
+		copy(part[:], s.State[i*3:])
+	}
+
+	return nil
+}
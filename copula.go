@@ -0,0 +1,404 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+)
+
+// CopulaFamily is the dependence structure of CopulaGenerator
+type CopulaFamily string
+
+const (
+	GaussianCopula CopulaFamily = "gaussian"
+	StudentTCopula CopulaFamily = "student-t"
+	ClaytonCopula  CopulaFamily = "clayton"
+	GumbelCopula   CopulaFamily = "gumbel"
+	FrankCopula    CopulaFamily = "frank"
+)
+
+// CopulaGenerator produces dependent pairs (X, Y) with arbitrary marginal
+// distributions: a pair (u, v) of uniform values is drawn from the copula and
+// mapped through the inverse distribution functions of the marginals
+//
+// g and g2 are [0, 1) uniform sources, the marginals are only used for their
+// quantiles
+type CopulaGenerator struct {
+	name   GeneratorName
+	family CopulaFamily
+
+	g  Float64Generator
+	g2 Float64Generator
+
+	x QuantileGenerator
+	y QuantileGenerator
+
+	// correlation coefficient ρ for the elliptical copulas, θ for the Archimedean ones
+	parameter float64
+	// ν of the Student-t copula
+	degreesOfFreedom float64
+}
+
+func (cg *CopulaGenerator) Name() string {
+	return string(cg.name)
+}
+
+func (cg *CopulaGenerator) Family() CopulaFamily {
+	return cg.family
+}
+
+func (cg *CopulaGenerator) String() string {
+	d := make(map[string]interface{}, 8)
+
+	d["distributionName"] = cg.name
+	d["family"] = cg.family
+	d["parameter"] = cg.parameter
+	d["marginalX"] = json.RawMessage(cg.x.String())
+	d["marginalY"] = json.RawMessage(cg.y.String())
+
+	if cg.family == StudentTCopula {
+		d["degreesOfFreedom"] = cg.degreesOfFreedom
+	}
+
+	describeSource(d, "source", cg.g)
+	describeSource(d, "secondSource", cg.g2)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// NewGaussianCopulaGenerator builds the copula of the bivariate normal
+// distribution with the correlation coefficient in (-1, 1)
+func NewGaussianCopulaGenerator(
+	generator Float64Generator,
+	secondGenerator Float64Generator,
+	marginalX QuantileGenerator,
+	marginalY QuantileGenerator,
+	correlationCoefficient float64,
+) (*CopulaGenerator, error) {
+	return newCopulaGenerator(GaussianCopula, generator, secondGenerator, marginalX, marginalY, correlationCoefficient, 0)
+}
+
+// NewStudentTCopulaGenerator builds the copula of the bivariate Student-t
+// distribution, it has tail dependence in both tails unlike the Gaussian copula
+func NewStudentTCopulaGenerator(
+	generator Float64Generator,
+	secondGenerator Float64Generator,
+	marginalX QuantileGenerator,
+	marginalY QuantileGenerator,
+	correlationCoefficient float64,
+	degreesOfFreedom float64,
+) (*CopulaGenerator, error) {
+	return newCopulaGenerator(StudentTCopula, generator, secondGenerator, marginalX, marginalY, correlationCoefficient, degreesOfFreedom)
+}
+
+// NewClaytonCopulaGenerator builds the Clayton copula with θ > 0, it has
+// lower tail dependence
+func NewClaytonCopulaGenerator(
+	generator Float64Generator,
+	secondGenerator Float64Generator,
+	marginalX QuantileGenerator,
+	marginalY QuantileGenerator,
+	theta float64,
+) (*CopulaGenerator, error) {
+	return newCopulaGenerator(ClaytonCopula, generator, secondGenerator, marginalX, marginalY, theta, 0)
+}
+
+// NewGumbelCopulaGenerator builds the Gumbel copula with θ >= 1 (θ = 1 is the
+// independence copula), it has upper tail dependence
+func NewGumbelCopulaGenerator(
+	generator Float64Generator,
+	secondGenerator Float64Generator,
+	marginalX QuantileGenerator,
+	marginalY QuantileGenerator,
+	theta float64,
+) (*CopulaGenerator, error) {
+	return newCopulaGenerator(GumbelCopula, generator, secondGenerator, marginalX, marginalY, theta, 0)
+}
+
+// NewFrankCopulaGenerator builds the Frank copula with θ != 0, negative θ
+// gives negative dependence, there is no tail dependence
+func NewFrankCopulaGenerator(
+	generator Float64Generator,
+	secondGenerator Float64Generator,
+	marginalX QuantileGenerator,
+	marginalY QuantileGenerator,
+	theta float64,
+) (*CopulaGenerator, error) {
+	return newCopulaGenerator(FrankCopula, generator, secondGenerator, marginalX, marginalY, theta, 0)
+}
+
+func newCopulaGenerator(
+	family CopulaFamily,
+	generator Float64Generator,
+	secondGenerator Float64Generator,
+	marginalX QuantileGenerator,
+	marginalY QuantileGenerator,
+	parameter float64,
+	degreesOfFreedom float64,
+) (*CopulaGenerator, error) {
+	if err := validateCopula(family, parameter, degreesOfFreedom); err != nil {
+		return nil, err
+	}
+
+	if marginalX == nil || marginalY == nil {
+		return nil, errors.Wrap(ErrInvalidParameters, "marginal distribution is nil")
+	}
+
+	return &CopulaGenerator{
+		name:             Copula,
+		family:           family,
+		g:                generator,
+		g2:               secondGenerator,
+		x:                marginalX,
+		y:                marginalY,
+		parameter:        parameter,
+		degreesOfFreedom: degreesOfFreedom,
+	}, nil
+}
+
+func validateCopula(family CopulaFamily, parameter float64, degreesOfFreedom float64) error {
+	if math.IsNaN(parameter) || math.IsInf(parameter, 0) {
+		return errors.Wrap(ErrInvalidParameters, "copula parameter is not finite")
+	}
+
+	switch family {
+	case GaussianCopula:
+		if parameter <= -1 || parameter >= 1 {
+			return errors.Wrap(ErrInvalidParameters, "correlation coefficient is out of range (-1, 1)")
+		}
+	case StudentTCopula:
+		if parameter <= -1 || parameter >= 1 {
+			return errors.Wrap(ErrInvalidParameters, "correlation coefficient is out of range (-1, 1)")
+		}
+
+		if !(degreesOfFreedom > 0) || math.IsInf(degreesOfFreedom, 0) {
+			return errors.Wrap(ErrInvalidParameters, "degrees of freedom must be positive")
+		}
+	case ClaytonCopula:
+		if parameter <= 0 {
+			return errors.Wrap(ErrInvalidParameters, "clayton θ must be positive")
+		}
+	case GumbelCopula:
+		if parameter < 1 {
+			return errors.Wrap(ErrInvalidParameters, "gumbel θ must be at least 1")
+		}
+	case FrankCopula:
+		if parameter == 0 {
+			return errors.Wrap(ErrInvalidParameters, "frank θ must not be 0")
+		}
+	default:
+		return errors.Wrapf(ErrInvalidParameters, "unknown copula family %q", family)
+	}
+
+	return nil
+}
+
+// CopulaFloat64s returns a pair of uniform values drawn from the copula
+func (cg *CopulaGenerator) CopulaFloat64s() (float64, float64) {
+	switch cg.family {
+	case GaussianCopula:
+		z1, z2 := cg.correlatedNormals()
+
+		return standardNormalCDF(z1), standardNormalCDF(z2)
+	case StudentTCopula:
+		z1, z2 := cg.correlatedNormals()
+
+		// both components share the chi-square mixing variable
+		normal := NormalGenerator{name: Normal, g: cg.g, g2: cg.g2, stdDev: 1, mean: 0}
+		chi := 2 * standardGammaFloat64(cg.degreesOfFreedom/2, cg.g, &normal)
+		scale := math.Sqrt(cg.degreesOfFreedom / chi)
+
+		t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: cg.degreesOfFreedom}
+
+		return t.CDF(z1 * scale), t.CDF(z2 * scale)
+	case ClaytonCopula:
+		// conditional inversion of ∂C/∂u
+		u, w := openUniform(cg.g), openUniform(cg.g2)
+		theta := cg.parameter
+
+		v := math.Pow(math.Pow(u, -theta)*(math.Pow(w, -theta/(1+theta))-1)+1, -1/theta)
+
+		return u, v
+	case GumbelCopula:
+		// Marshall–Olkin algorithm with a positive stable mixing variable
+		alpha := 1 / cg.parameter
+		s := positiveStableFloat64(alpha, cg.g, cg.g2)
+
+		e1 := -math.Log(openUniform(cg.g))
+		e2 := -math.Log(openUniform(cg.g2))
+
+		return math.Exp(-math.Pow(e1/s, alpha)), math.Exp(-math.Pow(e2/s, alpha))
+	case FrankCopula:
+		// conditional inversion of ∂C/∂u
+		u, w := openUniform(cg.g), openUniform(cg.g2)
+		theta := cg.parameter
+
+		v := -math.Log1p(w*math.Expm1(-theta)/(w+(1-w)*math.Exp(-theta*u))) / theta
+
+		return u, v
+	default:
+		panic(fmt.Sprintf("unknown copula family %q", cg.family))
+	}
+}
+
+func (cg *CopulaGenerator) TwoDimensionalFloat64s() FloatPair {
+	u, v := cg.CopulaFloat64s()
+
+	return FloatPair{x: cg.x.Quantile(u), y: cg.y.Quantile(v)}
+}
+
+// correlatedNormals returns standard normal values with correlation ρ
+func (cg *CopulaGenerator) correlatedNormals() (float64, float64) {
+	normal := NormalGenerator{name: Normal, g: cg.g, g2: cg.g2, stdDev: 1, mean: 0}
+
+	z1 := normal.NormFloat64()
+	z2 := normal.NormFloat64()
+	r := cg.parameter
+
+	return z1, r*z1 + math.Sqrt(1-r*r)*z2
+}
+
+func standardNormalCDF(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+// openUniformAttempts bounds the values drawn by openUniform, a uniform source
+// yields that many values out of (0, 1) in a row with negligible probability
+const openUniformAttempts = 64
+
+// openUniform returns a value of g in (0, 1), panics if the source is stuck
+// at the ends of [0, 1], e.g. a multiplicative congruential generator seeded
+// with 0
+func openUniform(g Float64Generator) float64 {
+	for i := 0; i < openUniformAttempts; i += 1 {
+		if u := g.Float64(); u > 0 && u < 1 {
+			return u
+		}
+	}
+
+	panic(fmt.Sprintf("%d values of the source are out of (0, 1), the source is degenerate", openUniformAttempts))
+}
+
+// positiveStableFloat64 draws the positive α-stable variable with Laplace
+// transform exp(-t^α), 0 < α <= 1, by Kanter's representation
+func positiveStableFloat64(alpha float64, g Float64Generator, g2 Float64Generator) float64 {
+	if alpha == 1 {
+		return 1
+	}
+
+	theta := math.Pi * openUniform(g)
+	w := -math.Log(openUniform(g2))
+
+	return math.Sin(alpha*theta) / math.Pow(math.Sin(theta), 1/alpha) *
+		math.Pow(math.Sin((1-alpha)*theta)/w, (1-alpha)/alpha)
+}
+
+type copulaState struct {
+	Name             GeneratorName   `json:"distributionName"`
+	Family           CopulaFamily    `json:"family"`
+	Parameter        float64         `json:"parameter"`
+	DegreesOfFreedom float64         `json:"degreesOfFreedom,omitempty"`
+	Source           json.RawMessage `json:"source"`
+	SecondSource     json.RawMessage `json:"secondSource"`
+	MarginalX        json.RawMessage `json:"marginalX"`
+	MarginalY        json.RawMessage `json:"marginalY"`
+}
+
+func (cg *CopulaGenerator) MarshalBinary() ([]byte, error) {
+	s := copulaState{
+		Name:             Copula,
+		Family:           cg.family,
+		Parameter:        cg.parameter,
+		DegreesOfFreedom: cg.degreesOfFreedom,
+	}
+
+	var sources sharedSources
+	var err error
+
+	if s.Source, err = sources.marshal("source", cg.g); err != nil {
+		return nil, err
+	}
+
+	if s.SecondSource, err = sources.marshal("secondSource", cg.g2); err != nil {
+		return nil, err
+	}
+
+	// the marginals only supply quantiles, their sources are never drawn from
+	for _, f := range []struct {
+		dst *json.RawMessage
+		g   interface{}
+	}{
+		{&s.MarginalX, cg.x},
+		{&s.MarginalY, cg.y},
+	} {
+		if *f.dst, err = marshalSource(f.g); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(s)
+}
+
+func (cg *CopulaGenerator) UnmarshalBinary(data []byte) error {
+	var s copulaState
+	if err := unmarshalState(data, Copula, &s); err != nil {
+		return err
+	}
+
+	if err := validateCopula(s.Family, s.Parameter, s.DegreesOfFreedom); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	var sources sharedSources
+
+	g, err := sources.unmarshalFloat64("source", cg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	g2, err := sources.unmarshalFloat64("secondSource", cg.g2, s.SecondSource)
+	if err != nil {
+		return err
+	}
+
+	x, err := unmarshalQuantileSource(cg.x, s.MarginalX)
+	if err != nil {
+		return err
+	}
+
+	y, err := unmarshalQuantileSource(cg.y, s.MarginalY)
+	if err != nil {
+		return err
+	}
+
+	cg.name = Copula
+	cg.family = s.Family
+	cg.parameter = s.Parameter
+	cg.degreesOfFreedom = s.DegreesOfFreedom
+	cg.g = g
+	cg.g2 = g2
+	cg.x = x
+	cg.y = y
+
+	return nil
+}
+
+func unmarshalQuantileSource(g QuantileGenerator, data json.RawMessage) (QuantileGenerator, error) {
+	src, err := unmarshalSource(g, data)
+	if err != nil {
+		return nil, err
+	}
+
+	q, ok := src.(QuantileGenerator)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidState, "marginal is not a quantile generator")
+	}
+
+	return q, nil
+}
@@ -0,0 +1,314 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/bits"
+)
+
+const (
+	philoxM0 = 0xD2511F53
+	philoxM1 = 0xCD9E8D57
+	philoxW0 = 0x9E3779B9
+	philoxW1 = 0xBB67AE85
+
+	philoxRounds = 10
+
+	threefryParity = 0x1BD11BDAA9FC1A22
+	threefryRounds = 20
+
+	counterBlockSize = 4
+)
+
+var threefryRotations = [8][2]int{{14, 16}, {52, 57}, {23, 40}, {5, 37}, {25, 33}, {46, 12}, {58, 22}, {32, 32}}
+
+// PhiloxGenerator is Salmon et al. Philox4x32-10 counter-based generator
+//
+// every output is a pure function of (seed, stream, counter): the 64-bit seed
+// is the key, the 64-bit block counter and the 64-bit stream form the 128-bit
+// counter, so distinct streams never overlap and any position is reachable
+// in constant time with SetCounter
+type PhiloxGenerator struct {
+	name    GeneratorName
+	seed    uint64
+	stream  uint64
+	counter uint64
+	block   [counterBlockSize]uint32
+	index   int
+}
+
+func NewPhiloxGenerator(seed uint64, stream uint64) *PhiloxGenerator {
+	return &PhiloxGenerator{name: Philox, seed: seed, stream: stream, index: counterBlockSize}
+}
+
+func (g *PhiloxGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *PhiloxGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+	d["stream"] = g.stream
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *PhiloxGenerator) Uint32() uint32 {
+	if g.index == counterBlockSize {
+		g.block = philox4x32(
+			[counterBlockSize]uint32{uint32(g.counter), uint32(g.counter >> 32), uint32(g.stream), uint32(g.stream >> 32)},
+			[2]uint32{uint32(g.seed), uint32(g.seed >> 32)},
+		)
+		g.counter += 1
+		g.index = 0
+	}
+
+	v := g.block[g.index]
+	g.index += 1
+
+	return v
+}
+
+func (g *PhiloxGenerator) Uint64() uint64 {
+	return uint64(g.Uint32())<<32 | uint64(g.Uint32())
+}
+
+func (g *PhiloxGenerator) Int() int {
+	return int(g.Uint32())
+}
+
+func (g *PhiloxGenerator) Bits() uint {
+	return 32
+}
+
+// Seed replaces the key and rewinds the counter, the stream is kept
+func (g *PhiloxGenerator) Seed(seed int64) {
+	g.seed = uint64(seed)
+	g.SetCounter(0)
+}
+
+// Counter returns the block counter of the next output, every block holds
+// four 32-bit outputs
+func (g *PhiloxGenerator) Counter() uint64 {
+	if g.index == counterBlockSize {
+		return g.counter
+	}
+
+	return g.counter - 1
+}
+
+// SetCounter moves the generator to the start of the given block
+func (g *PhiloxGenerator) SetCounter(counter uint64) {
+	g.counter = counter
+	g.index = counterBlockSize
+}
+
+// philox4x32 applies the Philox4x32-10 bijection to the counter under the key
+func philox4x32(ctr [counterBlockSize]uint32, key [2]uint32) [counterBlockSize]uint32 {
+	for r := 0; r < philoxRounds; r += 1 {
+		if r > 0 {
+			key[0] += philoxW0
+			key[1] += philoxW1
+		}
+
+		hi0, lo0 := bits.Mul32(philoxM0, ctr[0])
+		hi1, lo1 := bits.Mul32(philoxM1, ctr[2])
+
+		ctr = [counterBlockSize]uint32{hi1 ^ ctr[1] ^ key[0], lo1, hi0 ^ ctr[3] ^ key[1], lo0}
+	}
+
+	return ctr
+}
+
+// ThreefryGenerator is Salmon et al. Threefry4x64-20 counter-based generator,
+// the Threefish block cipher with a simplified key schedule
+//
+// the key is (seed, stream), the counter is the 64-bit block index
+type ThreefryGenerator struct {
+	name    GeneratorName
+	seed    uint64
+	stream  uint64
+	counter uint64
+	block   [counterBlockSize]uint64
+	index   int
+}
+
+func NewThreefryGenerator(seed uint64, stream uint64) *ThreefryGenerator {
+	return &ThreefryGenerator{name: Threefry, seed: seed, stream: stream, index: counterBlockSize}
+}
+
+func (g *ThreefryGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *ThreefryGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+	d["stream"] = g.stream
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *ThreefryGenerator) Uint64() uint64 {
+	if g.index == counterBlockSize {
+		g.block = threefry4x64(
+			[counterBlockSize]uint64{g.counter, 0, 0, 0},
+			[counterBlockSize]uint64{g.seed, g.stream, 0, 0},
+		)
+		g.counter += 1
+		g.index = 0
+	}
+
+	v := g.block[g.index]
+	g.index += 1
+
+	return v
+}
+
+func (g *ThreefryGenerator) Int() int {
+	return int(g.Uint64() >> 1)
+}
+
+func (g *ThreefryGenerator) Bits() uint {
+	return 63
+}
+
+// Seed replaces the key seed word and rewinds the counter, the stream is kept
+func (g *ThreefryGenerator) Seed(seed int64) {
+	g.seed = uint64(seed)
+	g.SetCounter(0)
+}
+
+// Counter returns the block counter of the next output, every block holds
+// four 64-bit outputs
+func (g *ThreefryGenerator) Counter() uint64 {
+	if g.index == counterBlockSize {
+		return g.counter
+	}
+
+	return g.counter - 1
+}
+
+// SetCounter moves the generator to the start of the given block
+func (g *ThreefryGenerator) SetCounter(counter uint64) {
+	g.counter = counter
+	g.index = counterBlockSize
+}
+
+// threefry4x64 applies the Threefry4x64-20 bijection to the counter under the key
+func threefry4x64(x [counterBlockSize]uint64, key [counterBlockSize]uint64) [counterBlockSize]uint64 {
+	var ks [counterBlockSize + 1]uint64
+
+	ks[counterBlockSize] = threefryParity
+	for i, k := range key {
+		ks[i] = k
+		ks[counterBlockSize] ^= k
+		x[i] += k
+	}
+
+	for r := 0; r < threefryRounds; r += 1 {
+		rot := threefryRotations[r%8]
+
+		if r%2 == 0 {
+			x[0] += x[1]
+			x[1] = bits.RotateLeft64(x[1], rot[0]) ^ x[0]
+			x[2] += x[3]
+			x[3] = bits.RotateLeft64(x[3], rot[1]) ^ x[2]
+		} else {
+			x[0] += x[3]
+			x[3] = bits.RotateLeft64(x[3], rot[0]) ^ x[0]
+			x[2] += x[1]
+			x[1] = bits.RotateLeft64(x[1], rot[1]) ^ x[2]
+		}
+
+		// key injection after every 4 rounds
+		if r%4 == 3 {
+			s := (r + 1) / 4
+			for i := range x {
+				x[i] += ks[(s+i)%len(ks)]
+			}
+			x[3] += uint64(s)
+		}
+	}
+
+	return x
+}
+
+type counterState struct {
+	Name    GeneratorName `json:"distributionName"`
+	Seed    uint64        `json:"seed"`
+	Stream  uint64        `json:"stream"`
+	Counter uint64        `json:"counter"`
+	// Index is the position of the next output within the current block
+	Index int `json:"index"`
+}
+
+func (g *PhiloxGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(counterState{Name: Philox, Seed: g.seed, Stream: g.stream, Counter: g.Counter(), Index: g.index % counterBlockSize})
+}
+
+func (g *PhiloxGenerator) UnmarshalBinary(data []byte) error {
+	s, err := unmarshalCounterState(data, Philox)
+	if err != nil {
+		return err
+	}
+
+	*g = *NewPhiloxGenerator(s.Seed, s.Stream)
+	g.SetCounter(s.Counter)
+
+	// regenerate the current block
+	if s.Index > 0 {
+		g.Uint32()
+		g.index = s.Index
+	}
+
+	return nil
+}
+
+func (g *ThreefryGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(counterState{Name: Threefry, Seed: g.seed, Stream: g.stream, Counter: g.Counter(), Index: g.index % counterBlockSize})
+}
+
+func (g *ThreefryGenerator) UnmarshalBinary(data []byte) error {
+	s, err := unmarshalCounterState(data, Threefry)
+	if err != nil {
+		return err
+	}
+
+	*g = *NewThreefryGenerator(s.Seed, s.Stream)
+	g.SetCounter(s.Counter)
+
+	// regenerate the current block
+	if s.Index > 0 {
+		g.Uint64()
+		g.index = s.Index
+	}
+
+	return nil
+}
+
+func unmarshalCounterState(data []byte, name GeneratorName) (counterState, error) {
+	var s counterState
+	if err := unmarshalState(data, name, &s); err != nil {
+		return s, err
+	}
+
+	if s.Index < 0 || s.Index >= counterBlockSize {
+		return s, errors.Wrap(ErrInvalidState, "block index is out of range")
+	}
+
+	return s, nil
+}
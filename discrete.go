@@ -0,0 +1,979 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"math/bits"
+)
+
+// poissonInversionLimit is the mean below which Poisson values are drawn by
+// inversion, the expected cost of the inversion grows linearly with the mean
+const poissonInversionLimit = 10
+
+// binomialInversionLimit is the bound of n·min(p, 1 - p) below which
+// binomial values are drawn by inversion instead of BTPE
+const binomialInversionLimit = 30
+
+// PoissonGenerator produces Poisson values with mean λ, small means use the
+// sequential inversion, large means Hörmann's transformed rejection with
+// squeeze (PTRS) which takes about 1.2 pairs of uniform values per value
+type PoissonGenerator struct {
+	name   GeneratorName
+	g      Float64Generator
+	lambda float64
+}
+
+func (pg *PoissonGenerator) Name() string {
+	return string(pg.name)
+}
+
+func (pg *PoissonGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = pg.name
+	d["lambda"] = pg.lambda
+
+	describeSource(d, "source", pg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewPoissonGeneratorDefault(lambda float64) (*PoissonGenerator, error) {
+	return NewPoissonGenerator(newDefaultGenerator(), lambda)
+}
+
+func NewPoissonGenerator(generator Float64Generator, lambda float64) (*PoissonGenerator, error) {
+	if err := validatePositive("lambda", lambda); err != nil {
+		return nil, err
+	}
+
+	return &PoissonGenerator{name: Poisson, g: generator, lambda: lambda}, nil
+}
+
+func (pg *PoissonGenerator) PoissonInt() int {
+	return poissonInt(pg.lambda, pg.g)
+}
+
+// poissonInt draws a Poisson value with mean lambda
+func poissonInt(lambda float64, g Float64Generator) int {
+	if lambda < poissonInversionLimit {
+		p0 := math.Exp(-lambda)
+		k, p, s := 0, p0, p0
+		u := g.Float64()
+
+		for u > s {
+			k += 1
+			p *= lambda / float64(k)
+
+			if p == 0 {
+				// u fell into the mass lost to rounding, redraw
+				k, p, s, u = 0, p0, p0, g.Float64()
+				continue
+			}
+
+			s += p
+		}
+
+		return k
+	}
+
+	logLambda := math.Log(lambda)
+	b := 0.931 + 2.53*math.Sqrt(lambda)
+	a := -0.059 + 0.02483*b
+	invAlpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+
+	for {
+		u := g.Float64() - 0.5
+		v := g.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + lambda + 0.43)
+
+		if us >= 0.07 && v <= vr {
+			return int(k)
+		}
+
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+
+		lg, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invAlpha)-math.Log(a/(us*us)+b) <= -lambda+k*logLambda-lg {
+			return int(k)
+		}
+	}
+}
+
+// BinomialGenerator produces the number of successes in n independent trials
+// with success probability p, n·min(p, 1 - p) < 30 is drawn by inversion,
+// the rest by Kachitvichyanukul and Schmeiser's BTPE
+type BinomialGenerator struct {
+	name GeneratorName
+	g    Float64Generator
+	n    int
+	p    float64
+}
+
+func (bg *BinomialGenerator) Name() string {
+	return string(bg.name)
+}
+
+func (bg *BinomialGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = bg.name
+	d["trials"] = bg.n
+	d["probability"] = bg.p
+
+	describeSource(d, "source", bg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewBinomialGeneratorDefault(trials int, probability float64) (*BinomialGenerator, error) {
+	return NewBinomialGenerator(newDefaultGenerator(), trials, probability)
+}
+
+func NewBinomialGenerator(generator Float64Generator, trials int, probability float64) (*BinomialGenerator, error) {
+	if trials < 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "trials must not be negative")
+	}
+
+	if err := validateProbability(probability); err != nil {
+		return nil, err
+	}
+
+	return &BinomialGenerator{name: Binomial, g: generator, n: trials, p: probability}, nil
+}
+
+func (bg *BinomialGenerator) BinomialInt() int {
+	// draw with the smaller probability and reflect
+	p := math.Min(bg.p, 1-bg.p)
+
+	var k int
+	switch {
+	case bg.n == 0 || p == 0:
+		k = 0
+	case float64(bg.n)*p < binomialInversionLimit:
+		k = bg.inversion(p)
+	default:
+		k = bg.btpe(p)
+	}
+
+	if bg.p > 0.5 {
+		return bg.n - k
+	}
+
+	return k
+}
+
+func (bg *BinomialGenerator) inversion(p float64) int {
+	n := float64(bg.n)
+	q := 1 - p
+	qn := math.Exp(n * math.Log1p(-p))
+	np := n * p
+	bound := math.Min(n, np+10*math.Sqrt(np*q+1))
+
+	k := 0
+	pk := qn
+	u := bg.g.Float64()
+
+	for u > pk {
+		k += 1
+
+		if float64(k) > bound {
+			k, pk, u = 0, qn, bg.g.Float64()
+			continue
+		}
+
+		u -= pk
+		pk *= (n - float64(k) + 1) * p / (float64(k) * q)
+	}
+
+	return k
+}
+
+func (bg *BinomialGenerator) btpe(p float64) int {
+	n := float64(bg.n)
+	q := 1 - p
+	nrq := n * p * q
+
+	fm := n*p + p
+	m := math.Floor(fm)
+	p1 := math.Floor(2.195*math.Sqrt(nrq)-4.6*q) + 0.5
+	xm := m + 0.5
+	xl := xm - p1
+	xr := xm + p1
+	c := 0.134 + 20.5/(15.3+m)
+
+	a := (fm - xl) / (fm - xl*p)
+	laml := a * (1 + a/2)
+	a = (xr - fm) / (xr * q)
+	lamr := a * (1 + a/2)
+
+	p2 := p1 * (1 + 2*c)
+	p3 := p2 + c/laml
+	p4 := p3 + c/lamr
+
+	for {
+		u := bg.g.Float64() * p4
+		v := bg.g.Float64()
+
+		var y float64
+
+		switch {
+		case u <= p1:
+			// the triangular region, accepted immediately
+			return int(math.Floor(xm - p1*v + u))
+		case u <= p2:
+			// the parallelograms
+			x := xl + (u-p1)/c
+			v = v*c + 1 - math.Abs(m-x+0.5)/p1
+
+			if v > 1 {
+				continue
+			}
+
+			y = math.Floor(x)
+		case u <= p3:
+			// the left exponential tail
+			y = math.Floor(xl + math.Log(v)/laml)
+
+			if y < 0 {
+				continue
+			}
+
+			v *= (u - p2) * laml
+		default:
+			// the right exponential tail
+			y = math.Floor(xr - math.Log(v)/lamr)
+
+			if y > n {
+				continue
+			}
+
+			v *= (u - p3) * lamr
+		}
+
+		k := math.Abs(y - m)
+
+		if k <= 20 || k >= nrq/2-1 {
+			// explicit evaluation of f(y)/f(m)
+			s := p / q
+			a := s * (n + 1)
+			f := 1.0
+
+			if m < y {
+				for i := m + 1; i <= y; i += 1 {
+					f *= a/i - s
+				}
+			} else if m > y {
+				for i := y + 1; i <= m; i += 1 {
+					f /= a/i - s
+				}
+			}
+
+			if v <= f {
+				return int(y)
+			}
+
+			continue
+		}
+
+		// squeeze with the normal approximation, then Stirling's bound
+		rho := (k / nrq) * ((k*(k/3+0.625)+0.1666666666666)/nrq + 0.5)
+		t := -k * k / (2 * nrq)
+		logV := math.Log(v)
+
+		if logV < t-rho {
+			return int(y)
+		}
+
+		if logV > t+rho {
+			continue
+		}
+
+		x1 := y + 1
+		f1 := m + 1
+		z := n + 1 - m
+		w := n - y + 1
+
+		bound := xm*math.Log(f1/x1) + (n-m+0.5)*math.Log(z/w) + (y-m)*math.Log(w*p/(x1*q)) +
+			stirlingCorrection(f1) + stirlingCorrection(z) + stirlingCorrection(x1) + stirlingCorrection(w)
+
+		if logV <= bound {
+			return int(y)
+		}
+	}
+}
+
+// stirlingCorrection is the truncated series of log(x!) - Stirling's formula
+func stirlingCorrection(x float64) float64 {
+	x2 := x * x
+
+	return (13860 - (462-(132-(99-140/x2)/x2)/x2)/x2) / x / 166320
+}
+
+// GeometricGenerator produces the number of independent trials up to and
+// including the first success, p is the success probability of every trial
+type GeometricGenerator struct {
+	name GeneratorName
+	g    Float64Generator
+	p    float64
+}
+
+func (gg *GeometricGenerator) Name() string {
+	return string(gg.name)
+}
+
+func (gg *GeometricGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = gg.name
+	d["probability"] = gg.p
+
+	describeSource(d, "source", gg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewGeometricGeneratorDefault(probability float64) (*GeometricGenerator, error) {
+	return NewGeometricGenerator(newDefaultGenerator(), probability)
+}
+
+func NewGeometricGenerator(generator Float64Generator, probability float64) (*GeometricGenerator, error) {
+	if err := validateProbability(probability); err != nil {
+		return nil, err
+	}
+
+	if probability == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "probability must be positive")
+	}
+
+	return &GeometricGenerator{name: Geometric, g: generator, p: probability}, nil
+}
+
+func (gg *GeometricGenerator) GeometricInt() int {
+	if gg.p == 1 {
+		return 1
+	}
+
+	return 1 + int(math.Floor(math.Log(openUniform(gg.g))/math.Log1p(-gg.p)))
+}
+
+// NegativeBinomialGenerator produces the number of failures before the r-th
+// success, p is the success probability of every trial, r may be fractional
+//
+// the values are drawn as the gamma-Poisson mixture Poisson(Gamma(r, (1 - p)/p))
+type NegativeBinomialGenerator struct {
+	name      GeneratorName
+	g         Float64Generator
+	normal    NormFloat64Generator
+	successes float64
+	p         float64
+}
+
+func (ng *NegativeBinomialGenerator) Name() string {
+	return string(ng.name)
+}
+
+func (ng *NegativeBinomialGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = ng.name
+	d["successes"] = ng.successes
+	d["probability"] = ng.p
+
+	describeSource(d, "source", ng.g)
+	describeSource(d, "normal", ng.normal)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewNegativeBinomialGeneratorDefault(successes float64, probability float64) (*NegativeBinomialGenerator, error) {
+	return NewNegativeBinomialGenerator(newDefaultGenerator(), NewNormalGeneratorDefault(), successes, probability)
+}
+
+func NewNegativeBinomialGenerator(
+	generator Float64Generator,
+	normal NormFloat64Generator,
+	successes float64,
+	probability float64,
+) (*NegativeBinomialGenerator, error) {
+	if err := validatePositive("successes", successes); err != nil {
+		return nil, err
+	}
+
+	if err := validateProbability(probability); err != nil {
+		return nil, err
+	}
+
+	if probability == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "probability must be positive")
+	}
+
+	return &NegativeBinomialGenerator{
+		name:      NegativeBinomial,
+		g:         generator,
+		normal:    normal,
+		successes: successes,
+		p:         probability,
+	}, nil
+}
+
+func (ng *NegativeBinomialGenerator) NegativeBinomialInt() int {
+	if ng.p == 1 {
+		return 0
+	}
+
+	lambda := standardGammaFloat64(ng.successes, ng.g, ng.normal) * (1 - ng.p) / ng.p
+	if lambda == 0 {
+		return 0
+	}
+
+	return poissonInt(lambda, ng.g)
+}
+
+// HypergeometricGenerator produces the number of successes in draws made
+// without replacement from a population containing the given number of
+// successes, values are drawn by inversion searching outward from the mode,
+// the expected cost is proportional to the standard deviation
+type HypergeometricGenerator struct {
+	name       GeneratorName
+	g          Float64Generator
+	population int
+	successes  int
+	draws      int
+}
+
+func (hg *HypergeometricGenerator) Name() string {
+	return string(hg.name)
+}
+
+func (hg *HypergeometricGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = hg.name
+	d["population"] = hg.population
+	d["successes"] = hg.successes
+	d["draws"] = hg.draws
+
+	describeSource(d, "source", hg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewHypergeometricGeneratorDefault(population int, successes int, draws int) (*HypergeometricGenerator, error) {
+	return NewHypergeometricGenerator(newDefaultGenerator(), population, successes, draws)
+}
+
+func NewHypergeometricGenerator(generator Float64Generator, population int, successes int, draws int) (*HypergeometricGenerator, error) {
+	if population <= 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "population must be positive")
+	}
+
+	if successes < 0 || successes > population {
+		return nil, errors.Wrapf(ErrInvalidParameters, "successes are out of range [0, %d]", population)
+	}
+
+	if draws < 0 || draws > population {
+		return nil, errors.Wrapf(ErrInvalidParameters, "draws are out of range [0, %d]", population)
+	}
+
+	return &HypergeometricGenerator{
+		name:       Hypergeometric,
+		g:          generator,
+		population: population,
+		successes:  successes,
+		draws:      draws,
+	}, nil
+}
+
+func (hg *HypergeometricGenerator) HypergeometricInt() int {
+	total, good, n := float64(hg.population), float64(hg.successes), float64(hg.draws)
+	bad := total - good
+
+	low := math.Max(0, n-bad)
+	high := math.Min(n, good)
+	mode := math.Floor((n + 1) * (good + 1) / (total + 2))
+
+	pMode := math.Exp(logChoose(good, mode) + logChoose(bad, n-mode) - logChoose(total, n))
+
+	for {
+		u := hg.g.Float64()
+
+		if u < pMode {
+			return int(mode)
+		}
+
+		u -= pMode
+
+		down, up := mode, mode
+		pDown, pUp := pMode, pMode
+
+		for down > low || up < high {
+			if down > low {
+				// f(k - 1) = f(k) k (bad - n + k) / ((good - k + 1) (n - k + 1))
+				pDown *= down * (bad - n + down) / ((good - down + 1) * (n - down + 1))
+				down -= 1
+
+				if u < pDown {
+					return int(down)
+				}
+
+				u -= pDown
+			}
+
+			if up < high {
+				// f(k + 1) = f(k) (good - k) (n - k) / ((k + 1) (bad - n + k + 1))
+				pUp *= (good - up) * (n - up) / ((up + 1) * (bad - n + up + 1))
+				up += 1
+
+				if u < pUp {
+					return int(up)
+				}
+
+				u -= pUp
+			}
+		}
+
+		// u fell into the mass lost to rounding, redraw
+	}
+}
+
+// logChoose returns log(n choose k)
+func logChoose(n float64, k float64) float64 {
+	a, _ := math.Lgamma(n + 1)
+	b, _ := math.Lgamma(k + 1)
+	c, _ := math.Lgamma(n - k + 1)
+
+	return a - b - c
+}
+
+// DiscreteUniformGenerator produces integers uniformly distributed in
+// [min, max] from an IntGenerator with values in [0, modulus)
+//
+// the range is reduced without the modulo bias: consecutive source values are
+// combined until they span at least max - min + 1 values and the incomplete
+// last block of the span is rejected, values of the source outside
+// [0, modulus) are rejected as well
+//
+// generators built on a BitGenerator have no modulus (0), they reduce 64-bit
+// values with Lemire's method and support the whole int range
+type DiscreteUniformGenerator struct {
+	name GeneratorName
+	g    IntGenerator
+	bits *BitGenerator
+	m    int
+	min  int
+	max  int
+
+	// size is max - min + 1 and limit is the largest multiple of size not
+	// exceeding the number of values of draws combined source values, 0
+	// stands for 2^64 in both
+	size  uint64
+	limit uint64
+	draws int
+}
+
+func (dg *DiscreteUniformGenerator) Name() string {
+	return string(dg.name)
+}
+
+func (dg *DiscreteUniformGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = dg.name
+	if dg.bits == nil {
+		d["modulus"] = dg.m
+	}
+	d["min"] = dg.min
+	d["max"] = dg.max
+
+	describeSource(d, "source", dg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// NewDiscreteUniformGeneratorDefault draws 64-bit values from the default source
+func NewDiscreteUniformGeneratorDefault(min int, max int) (*DiscreteUniformGenerator, error) {
+	return NewDiscreteUniformBitGenerator(NewBitGeneratorDefault(), min, max)
+}
+
+// NewDiscreteUniformBitGenerator reduces the 64-bit values of generator to
+// [min, max] with Lemire's multiply-and-reject method
+func NewDiscreteUniformBitGenerator(generator *BitGenerator, min int, max int) (*DiscreteUniformGenerator, error) {
+	if generator == nil {
+		return nil, errors.Wrap(ErrInvalidParameters, "generator is nil")
+	}
+
+	if min > max {
+		return nil, errors.Wrap(ErrInvalidParameters, "min is greater than max")
+	}
+
+	return &DiscreteUniformGenerator{
+		name: DiscreteUniform,
+		g:    generator,
+		bits: generator,
+		min:  min,
+		max:  max,
+		size: uint64(max) - uint64(min) + 1,
+	}, nil
+}
+
+func NewDiscreteUniformGenerator(generator IntGenerator, modulus int, min int, max int) (*DiscreteUniformGenerator, error) {
+	if modulus < 2 {
+		return nil, errors.Wrap(ErrInvalidParameters, "modulus must be at least 2")
+	}
+
+	if min > max {
+		return nil, errors.Wrap(ErrInvalidParameters, "min is greater than max")
+	}
+
+	// max - min + 1 computed in uint64 is 0 for the whole int64 range
+	size := uint64(max) - uint64(min) + 1
+	m := uint64(modulus)
+
+	span, draws := m, 1
+	for span != 0 && (size == 0 || span < size) {
+		hi, lo := bits.Mul64(span, m)
+		if hi > 1 || (hi == 1 && lo != 0) {
+			return nil, errors.Wrapf(ErrInvalidParameters, "range [%d, %d] is too large for modulus %d", min, max, modulus)
+		}
+
+		span = lo
+		draws += 1
+	}
+
+	var rest uint64
+	switch {
+	case size == 0:
+		rest = 0
+	case span == 0:
+		// 2^64 mod size
+		rest = (math.MaxUint64%size + 1) % size
+	default:
+		rest = span % size
+	}
+
+	return &DiscreteUniformGenerator{
+		name:  DiscreteUniform,
+		g:     generator,
+		m:     modulus,
+		min:   min,
+		max:   max,
+		size:  size,
+		limit: span - rest,
+		draws: draws,
+	}, nil
+}
+
+func (dg *DiscreteUniformGenerator) DiscreteUniformInt() int {
+	if dg.bits != nil {
+		if dg.size == 0 {
+			return int(uint64(dg.min) + dg.bits.Uint64())
+		}
+
+		return int(uint64(dg.min) + dg.bits.uint64n(dg.size))
+	}
+
+	m := uint64(dg.m)
+
+	for {
+		x := uint64(0)
+		valid := true
+
+		for i := 0; i < dg.draws; i += 1 {
+			v := dg.g.Int()
+			if v < 0 || v >= dg.m {
+				valid = false
+				break
+			}
+
+			x = x*m + uint64(v)
+		}
+
+		if !valid || (dg.limit != 0 && x >= dg.limit) {
+			continue
+		}
+
+		if dg.size == 0 {
+			return int(uint64(dg.min) + x)
+		}
+
+		return int(uint64(dg.min) + x%dg.size)
+	}
+}
+
+func validateProbability(p float64) error {
+	if !(p >= 0 && p <= 1) {
+		return errors.Wrap(ErrInvalidParameters, "probability is out of range [0, 1]")
+	}
+
+	return nil
+}
+
+// discreteState is shared by the discrete generators, only the parameters of
+// the distribution are set
+type discreteState struct {
+	Name        GeneratorName   `json:"distributionName"`
+	Lambda      float64         `json:"lambda,omitempty"`
+	Trials      int             `json:"trials,omitempty"`
+	Probability float64         `json:"probability,omitempty"`
+	Successes   float64         `json:"successes,omitempty"`
+	Population  int             `json:"population,omitempty"`
+	Draws       int             `json:"draws,omitempty"`
+	Modulus     int             `json:"modulus,omitempty"`
+	Min         int             `json:"min,omitempty"`
+	Max         int             `json:"max,omitempty"`
+	Source      json.RawMessage `json:"source"`
+	Normal      json.RawMessage `json:"normal,omitempty"`
+}
+
+func (pg *PoissonGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(pg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(discreteState{Name: Poisson, Lambda: pg.lambda, Source: src})
+}
+
+func (pg *PoissonGenerator) UnmarshalBinary(data []byte) error {
+	var s discreteState
+	if err := unmarshalState(data, Poisson, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(pg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewPoissonGenerator(g, s.Lambda)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*pg = *restored
+
+	return nil
+}
+
+func (bg *BinomialGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(bg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(discreteState{Name: Binomial, Trials: bg.n, Probability: bg.p, Source: src})
+}
+
+func (bg *BinomialGenerator) UnmarshalBinary(data []byte) error {
+	var s discreteState
+	if err := unmarshalState(data, Binomial, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(bg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewBinomialGenerator(g, s.Trials, s.Probability)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*bg = *restored
+
+	return nil
+}
+
+func (gg *GeometricGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(gg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(discreteState{Name: Geometric, Probability: gg.p, Source: src})
+}
+
+func (gg *GeometricGenerator) UnmarshalBinary(data []byte) error {
+	var s discreteState
+	if err := unmarshalState(data, Geometric, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(gg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewGeometricGenerator(g, s.Probability)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*gg = *restored
+
+	return nil
+}
+
+func (ng *NegativeBinomialGenerator) MarshalBinary() ([]byte, error) {
+	var sources sharedSources
+
+	src, err := sources.marshal("source", ng.g)
+	if err != nil {
+		return nil, err
+	}
+
+	normal, err := sources.marshal("normal", ng.normal)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(discreteState{
+		Name:        NegativeBinomial,
+		Successes:   ng.successes,
+		Probability: ng.p,
+		Source:      src,
+		Normal:      normal,
+	})
+}
+
+func (ng *NegativeBinomialGenerator) UnmarshalBinary(data []byte) error {
+	var s discreteState
+	if err := unmarshalState(data, NegativeBinomial, &s); err != nil {
+		return err
+	}
+
+	var sources sharedSources
+
+	g, err := sources.unmarshalFloat64("source", ng.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	normal, err := sources.unmarshalNormFloat64("normal", ng.normal, s.Normal)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewNegativeBinomialGenerator(g, normal, s.Successes, s.Probability)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*ng = *restored
+
+	return nil
+}
+
+func (hg *HypergeometricGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(hg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(discreteState{
+		Name:       Hypergeometric,
+		Population: hg.population,
+		Successes:  float64(hg.successes),
+		Draws:      hg.draws,
+		Source:     src,
+	})
+}
+
+func (hg *HypergeometricGenerator) UnmarshalBinary(data []byte) error {
+	var s discreteState
+	if err := unmarshalState(data, Hypergeometric, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(hg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewHypergeometricGenerator(g, s.Population, int(s.Successes), s.Draws)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*hg = *restored
+
+	return nil
+}
+
+func (dg *DiscreteUniformGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(dg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(discreteState{Name: DiscreteUniform, Modulus: dg.m, Min: dg.min, Max: dg.max, Source: src})
+}
+
+func (dg *DiscreteUniformGenerator) UnmarshalBinary(data []byte) error {
+	var s discreteState
+	if err := unmarshalState(data, DiscreteUniform, &s); err != nil {
+		return err
+	}
+
+	src, err := unmarshalSource(dg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	var restored *DiscreteUniformGenerator
+	if s.Modulus == 0 {
+		bg, ok := src.(*BitGenerator)
+		if !ok {
+			return errors.Wrap(ErrInvalidState, "modulus is missing and source is not a bit generator")
+		}
+
+		restored, err = NewDiscreteUniformBitGenerator(bg, s.Min, s.Max)
+	} else {
+		g, ok := src.(IntGenerator)
+		if !ok {
+			return errors.Wrap(ErrInvalidState, "source is not an int generator")
+		}
+
+		restored, err = NewDiscreteUniformGenerator(g, s.Modulus, s.Min, s.Max)
+	}
+
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*dg = *restored
+
+	return nil
+}
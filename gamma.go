@@ -0,0 +1,613 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+)
+
+// erlangProductLength bounds the count of uniform values multiplied before the
+// logarithm is taken, (2^-32)^16 is still far from the float64 underflow
+const erlangProductLength = 16
+
+// GammaGenerator produces Gamma(shape, scale) values with Marsaglia and
+// Tsang's method, g is the [0, 1) uniform source and normal is the standard
+// normal source
+type GammaGenerator struct {
+	name   GeneratorName
+	g      Float64Generator
+	normal NormFloat64Generator
+	shape  float64
+	scale  float64
+}
+
+func (gg *GammaGenerator) Name() string {
+	return string(gg.name)
+}
+
+func (gg *GammaGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = gg.name
+	d["shape"] = gg.shape
+	d["scale"] = gg.scale
+
+	describeSource(d, "source", gg.g)
+	describeSource(d, "normal", gg.normal)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewGammaGeneratorDefault(shape float64, scale float64) (*GammaGenerator, error) {
+	return NewGammaGenerator(newDefaultGenerator(), NewNormalGeneratorDefault(), shape, scale)
+}
+
+func NewGammaGenerator(generator Float64Generator, normal NormFloat64Generator, shape float64, scale float64) (*GammaGenerator, error) {
+	if err := validatePositive("shape", shape); err != nil {
+		return nil, err
+	}
+
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	return &GammaGenerator{name: Gamma, g: generator, normal: normal, shape: shape, scale: scale}, nil
+}
+
+func (gg *GammaGenerator) GammaFloat64() float64 {
+	return gg.scale * standardGammaFloat64(gg.shape, gg.g, gg.normal)
+}
+
+func (gg *GammaGenerator) law() distuv.Gamma {
+	return distuv.Gamma{Alpha: gg.shape, Beta: 1 / gg.scale}
+}
+
+// Quantile returns the inverse of the gamma distribution function
+func (gg *GammaGenerator) Quantile(p float64) float64 {
+	return gg.law().Quantile(p)
+}
+
+func (gg *GammaGenerator) PDF(x float64) float64 {
+	return gg.law().Prob(x)
+}
+
+func (gg *GammaGenerator) CDF(x float64) float64 {
+	return gg.law().CDF(x)
+}
+
+func (gg *GammaGenerator) Mean() float64 {
+	return gg.law().Mean()
+}
+
+func (gg *GammaGenerator) Variance() float64 {
+	return gg.law().Variance()
+}
+
+// ErlangGenerator produces Erlang(shape, scale) values, the sum of shape
+// exponential values with the given mean (scale), e.g. the time until the
+// shape-th arrival of a Poisson process
+type ErlangGenerator struct {
+	name  GeneratorName
+	g     Float64Generator
+	shape int
+	scale float64
+}
+
+func (eg *ErlangGenerator) Name() string {
+	return string(eg.name)
+}
+
+func (eg *ErlangGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = eg.name
+	d["shape"] = eg.shape
+	d["scale"] = eg.scale
+
+	describeSource(d, "source", eg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewErlangGeneratorDefault(shape int, scale float64) (*ErlangGenerator, error) {
+	return NewErlangGenerator(newDefaultGenerator(), shape, scale)
+}
+
+func NewErlangGenerator(generator Float64Generator, shape int, scale float64) (*ErlangGenerator, error) {
+	if shape < 1 {
+		return nil, errors.Wrap(ErrInvalidParameters, "shape must be a positive integer")
+	}
+
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	return &ErlangGenerator{name: Erlang, g: generator, shape: shape, scale: scale}, nil
+}
+
+// ErlangFloat64 returns -scale log(U_1 ... U_shape), the product is split into
+// chunks so it doesn't underflow
+func (eg *ErlangGenerator) ErlangFloat64() float64 {
+	sum := 0.0
+
+	for left := eg.shape; left > 0; left -= erlangProductLength {
+		product := 1.0
+		for i := 0; i < left && i < erlangProductLength; i += 1 {
+			product *= openUniform(eg.g)
+		}
+
+		sum -= math.Log(product)
+	}
+
+	return eg.scale * sum
+}
+
+func (eg *ErlangGenerator) law() distuv.Gamma {
+	return distuv.Gamma{Alpha: float64(eg.shape), Beta: 1 / eg.scale}
+}
+
+// Quantile returns the inverse of the Erlang distribution function
+func (eg *ErlangGenerator) Quantile(p float64) float64 {
+	return eg.law().Quantile(p)
+}
+
+func (eg *ErlangGenerator) PDF(x float64) float64 {
+	return eg.law().Prob(x)
+}
+
+func (eg *ErlangGenerator) CDF(x float64) float64 {
+	return eg.law().CDF(x)
+}
+
+func (eg *ErlangGenerator) Mean() float64 {
+	return eg.law().Mean()
+}
+
+func (eg *ErlangGenerator) Variance() float64 {
+	return eg.law().Variance()
+}
+
+// ChiSquaredGenerator produces χ²(ν) values as 2 Gamma(ν/2, 1)
+type ChiSquaredGenerator struct {
+	name             GeneratorName
+	g                Float64Generator
+	normal           NormFloat64Generator
+	degreesOfFreedom float64
+}
+
+func (cg *ChiSquaredGenerator) Name() string {
+	return string(cg.name)
+}
+
+func (cg *ChiSquaredGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = cg.name
+	d["degreesOfFreedom"] = cg.degreesOfFreedom
+
+	describeSource(d, "source", cg.g)
+	describeSource(d, "normal", cg.normal)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewChiSquaredGeneratorDefault(degreesOfFreedom float64) (*ChiSquaredGenerator, error) {
+	return NewChiSquaredGenerator(newDefaultGenerator(), NewNormalGeneratorDefault(), degreesOfFreedom)
+}
+
+func NewChiSquaredGenerator(generator Float64Generator, normal NormFloat64Generator, degreesOfFreedom float64) (*ChiSquaredGenerator, error) {
+	if err := validatePositive("degrees of freedom", degreesOfFreedom); err != nil {
+		return nil, err
+	}
+
+	return &ChiSquaredGenerator{name: ChiSquared, g: generator, normal: normal, degreesOfFreedom: degreesOfFreedom}, nil
+}
+
+func (cg *ChiSquaredGenerator) ChiSquaredFloat64() float64 {
+	return 2 * standardGammaFloat64(cg.degreesOfFreedom/2, cg.g, cg.normal)
+}
+
+func (cg *ChiSquaredGenerator) law() distuv.ChiSquared {
+	return distuv.ChiSquared{K: cg.degreesOfFreedom}
+}
+
+// Quantile returns the inverse of the χ² distribution function
+func (cg *ChiSquaredGenerator) Quantile(p float64) float64 {
+	return cg.law().Quantile(p)
+}
+
+func (cg *ChiSquaredGenerator) PDF(x float64) float64 {
+	return cg.law().Prob(x)
+}
+
+func (cg *ChiSquaredGenerator) CDF(x float64) float64 {
+	return cg.law().CDF(x)
+}
+
+func (cg *ChiSquaredGenerator) Mean() float64 {
+	return cg.law().Mean()
+}
+
+func (cg *ChiSquaredGenerator) Variance() float64 {
+	return cg.law().Variance()
+}
+
+// BetaGenerator produces Beta(α, β) values as X / (X + Y) with
+// X ~ Gamma(α, 1) and Y ~ Gamma(β, 1)
+type BetaGenerator struct {
+	name   GeneratorName
+	g      Float64Generator
+	normal NormFloat64Generator
+	alpha  float64
+	beta   float64
+}
+
+func (bg *BetaGenerator) Name() string {
+	return string(bg.name)
+}
+
+func (bg *BetaGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = bg.name
+	d["alpha"] = bg.alpha
+	d["beta"] = bg.beta
+
+	describeSource(d, "source", bg.g)
+	describeSource(d, "normal", bg.normal)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewBetaGeneratorDefault(alpha float64, beta float64) (*BetaGenerator, error) {
+	return NewBetaGenerator(newDefaultGenerator(), NewNormalGeneratorDefault(), alpha, beta)
+}
+
+func NewBetaGenerator(generator Float64Generator, normal NormFloat64Generator, alpha float64, beta float64) (*BetaGenerator, error) {
+	if err := validatePositive("alpha", alpha); err != nil {
+		return nil, err
+	}
+
+	if err := validatePositive("beta", beta); err != nil {
+		return nil, err
+	}
+
+	return &BetaGenerator{name: Beta, g: generator, normal: normal, alpha: alpha, beta: beta}, nil
+}
+
+// BetaFloat64 divides the gammas in log space, for small shapes both of them
+// may underflow to 0
+func (bg *BetaGenerator) BetaFloat64() float64 {
+	x := logStandardGammaFloat64(bg.alpha, bg.g, bg.normal)
+	y := logStandardGammaFloat64(bg.beta, bg.g, bg.normal)
+
+	return 1 / (1 + math.Exp(y-x))
+}
+
+func (bg *BetaGenerator) law() distuv.Beta {
+	return distuv.Beta{Alpha: bg.alpha, Beta: bg.beta}
+}
+
+// Quantile returns the inverse of the beta distribution function
+func (bg *BetaGenerator) Quantile(p float64) float64 {
+	return bg.law().Quantile(p)
+}
+
+func (bg *BetaGenerator) PDF(x float64) float64 {
+	return bg.law().Prob(x)
+}
+
+func (bg *BetaGenerator) CDF(x float64) float64 {
+	return bg.law().CDF(x)
+}
+
+func (bg *BetaGenerator) Mean() float64 {
+	return bg.law().Mean()
+}
+
+func (bg *BetaGenerator) Variance() float64 {
+	return bg.law().Variance()
+}
+
+// DirichletGenerator produces Dirichlet(α_1, ..., α_k) vectors, the
+// normalized vector of independent Gamma(α_i, 1) values
+type DirichletGenerator struct {
+	name          GeneratorName
+	g             Float64Generator
+	normal        NormFloat64Generator
+	concentration []float64
+}
+
+func (dg *DirichletGenerator) Name() string {
+	return string(dg.name)
+}
+
+func (dg *DirichletGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = dg.name
+	d["concentration"] = dg.concentration
+
+	describeSource(d, "source", dg.g)
+	describeSource(d, "normal", dg.normal)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewDirichletGeneratorDefault(concentration []float64) (*DirichletGenerator, error) {
+	return NewDirichletGenerator(newDefaultGenerator(), NewNormalGeneratorDefault(), concentration)
+}
+
+func NewDirichletGenerator(generator Float64Generator, normal NormFloat64Generator, concentration []float64) (*DirichletGenerator, error) {
+	if len(concentration) < 2 {
+		return nil, errors.Wrap(ErrInvalidParameters, "at least 2 concentration parameters are required")
+	}
+
+	for i, a := range concentration {
+		if err := validatePositive(fmt.Sprintf("concentration %d", i), a); err != nil {
+			return nil, err
+		}
+	}
+
+	c := make([]float64, len(concentration))
+	copy(c, concentration)
+
+	return &DirichletGenerator{name: Dirichlet, g: generator, normal: normal, concentration: c}, nil
+}
+
+// Dimension returns the count of components of every sample
+func (dg *DirichletGenerator) Dimension() int {
+	return len(dg.concentration)
+}
+
+// DirichletFloat64s normalizes the gammas with log-sum-exp, for small
+// concentrations all of them may underflow to 0
+func (dg *DirichletGenerator) DirichletFloat64s() []float64 {
+	sample := make([]float64, len(dg.concentration))
+	max := math.Inf(-1)
+
+	for i, a := range dg.concentration {
+		sample[i] = logStandardGammaFloat64(a, dg.g, dg.normal)
+		max = math.Max(max, sample[i])
+	}
+
+	sum := 0.0
+	for i := range sample {
+		sample[i] = math.Exp(sample[i] - max)
+		sum += sample[i]
+	}
+
+	for i := range sample {
+		sample[i] /= sum
+	}
+
+	return sample
+}
+
+// logStandardGammaFloat64 draws the logarithm of Gamma(shape, 1), the boost
+// of shapes below 1 is added as log(U)/shape, which stays finite where
+// U^(1/shape) underflows
+func logStandardGammaFloat64(shape float64, g Float64Generator, normal NormFloat64Generator) float64 {
+	if shape < 1 {
+		return math.Log(standardGammaFloat64(shape+1, g, normal)) + math.Log(openUniform(g))/shape
+	}
+
+	return math.Log(standardGammaFloat64(shape, g, normal))
+}
+
+// standardGammaFloat64 draws Gamma(shape, 1) with Marsaglia and Tsang's
+// method, shapes below 1 are boosted with U^(1/shape)
+func standardGammaFloat64(shape float64, g Float64Generator, normal NormFloat64Generator) float64 {
+	if shape < 1 {
+		return standardGammaFloat64(shape+1, g, normal) * math.Pow(openUniform(g), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+
+	for {
+		x := normal.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+
+		v = v * v * v
+		u := openUniform(g)
+
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < x*x/2+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
+
+func validatePositive(parameter string, value float64) error {
+	if !(value > 0) || math.IsInf(value, 0) {
+		return errors.Wrapf(ErrInvalidParameters, "%s must be positive and finite", parameter)
+	}
+
+	return nil
+}
+
+// gammaState is shared by the gamma family, only the parameters of the
+// distribution are set
+type gammaState struct {
+	Name             GeneratorName   `json:"distributionName"`
+	Shape            float64         `json:"shape,omitempty"`
+	Scale            float64         `json:"scale,omitempty"`
+	DegreesOfFreedom float64         `json:"degreesOfFreedom,omitempty"`
+	Alpha            float64         `json:"alpha,omitempty"`
+	Beta             float64         `json:"beta,omitempty"`
+	Concentration    []float64       `json:"concentration,omitempty"`
+	Source           json.RawMessage `json:"source"`
+	NormalSource     json.RawMessage `json:"normalSource,omitempty"`
+}
+
+func marshalGammaState(s gammaState, g Float64Generator, normal NormFloat64Generator) ([]byte, error) {
+	var sources sharedSources
+	var err error
+
+	if s.Source, err = sources.marshal("source", g); err != nil {
+		return nil, err
+	}
+
+	if normal != nil {
+		if s.NormalSource, err = sources.marshal("normalSource", normal); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(s)
+}
+
+func unmarshalGammaState(
+	data []byte,
+	name GeneratorName,
+	g Float64Generator,
+	normal NormFloat64Generator,
+) (gammaState, Float64Generator, NormFloat64Generator, error) {
+	var s gammaState
+	if err := unmarshalState(data, name, &s); err != nil {
+		return s, nil, nil, err
+	}
+
+	var sources sharedSources
+
+	g, err := sources.unmarshalFloat64("source", g, s.Source)
+	if err != nil {
+		return s, nil, nil, err
+	}
+
+	if s.NormalSource == nil {
+		return s, g, nil, nil
+	}
+
+	normal, err = sources.unmarshalNormFloat64("normalSource", normal, s.NormalSource)
+	if err != nil {
+		return s, nil, nil, err
+	}
+
+	return s, g, normal, nil
+}
+
+func (gg *GammaGenerator) MarshalBinary() ([]byte, error) {
+	return marshalGammaState(gammaState{Name: Gamma, Shape: gg.shape, Scale: gg.scale}, gg.g, gg.normal)
+}
+
+func (gg *GammaGenerator) UnmarshalBinary(data []byte) error {
+	s, g, normal, err := unmarshalGammaState(data, Gamma, gg.g, gg.normal)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewGammaGenerator(g, normal, s.Shape, s.Scale)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*gg = *restored
+
+	return nil
+}
+
+func (eg *ErlangGenerator) MarshalBinary() ([]byte, error) {
+	return marshalGammaState(gammaState{Name: Erlang, Shape: float64(eg.shape), Scale: eg.scale}, eg.g, nil)
+}
+
+func (eg *ErlangGenerator) UnmarshalBinary(data []byte) error {
+	s, g, _, err := unmarshalGammaState(data, Erlang, eg.g, nil)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewErlangGenerator(g, int(s.Shape), s.Scale)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*eg = *restored
+
+	return nil
+}
+
+func (cg *ChiSquaredGenerator) MarshalBinary() ([]byte, error) {
+	return marshalGammaState(gammaState{Name: ChiSquared, DegreesOfFreedom: cg.degreesOfFreedom}, cg.g, cg.normal)
+}
+
+func (cg *ChiSquaredGenerator) UnmarshalBinary(data []byte) error {
+	s, g, normal, err := unmarshalGammaState(data, ChiSquared, cg.g, cg.normal)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewChiSquaredGenerator(g, normal, s.DegreesOfFreedom)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*cg = *restored
+
+	return nil
+}
+
+func (bg *BetaGenerator) MarshalBinary() ([]byte, error) {
+	return marshalGammaState(gammaState{Name: Beta, Alpha: bg.alpha, Beta: bg.beta}, bg.g, bg.normal)
+}
+
+func (bg *BetaGenerator) UnmarshalBinary(data []byte) error {
+	s, g, normal, err := unmarshalGammaState(data, Beta, bg.g, bg.normal)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewBetaGenerator(g, normal, s.Alpha, s.Beta)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*bg = *restored
+
+	return nil
+}
+
+func (dg *DirichletGenerator) MarshalBinary() ([]byte, error) {
+	return marshalGammaState(gammaState{Name: Dirichlet, Concentration: dg.concentration}, dg.g, dg.normal)
+}
+
+func (dg *DirichletGenerator) UnmarshalBinary(data []byte) error {
+	s, g, normal, err := unmarshalGammaState(data, Dirichlet, dg.g, dg.normal)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewDirichletGenerator(g, normal, s.Concentration)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*dg = *restored
+
+	return nil
+}
@@ -5,7 +5,8 @@ import (
 	"fmt"
 	"gonum.org/v1/gonum/floats"
 	"math"
-	"math/rand"
+	"math/bits"
+	"strconv"
 )
 
 type GeneratorName string
@@ -16,10 +17,75 @@ const (
 	Exponential    GeneratorName = "exponential"
 	Normal         GeneratorName = "normal"
 	TwoDimensional GeneratorName = "two-dimensional"
+
+	MultivariateNormal  GeneratorName = "multivariate-normal"
+	Copula              GeneratorName = "copula"
+	ZigguratNormal      GeneratorName = "ziggurat-normal"
+	ZigguratExponential GeneratorName = "ziggurat-exponential"
+
+	Gamma      GeneratorName = "gamma"
+	Erlang     GeneratorName = "erlang"
+	ChiSquared GeneratorName = "chi-squared"
+	Beta       GeneratorName = "beta"
+	Dirichlet  GeneratorName = "dirichlet"
+
+	Pareto    GeneratorName = "pareto"
+	Lomax     GeneratorName = "lomax"
+	Cauchy    GeneratorName = "cauchy"
+	StudentT  GeneratorName = "student-t"
+	LogNormal GeneratorName = "log-normal"
+	Weibull   GeneratorName = "weibull"
+	Stable    GeneratorName = "stable"
+
+	Poisson          GeneratorName = "poisson"
+	Binomial         GeneratorName = "binomial"
+	Geometric        GeneratorName = "geometric"
+	NegativeBinomial GeneratorName = "negative-binomial"
+	Hypergeometric   GeneratorName = "hypergeometric"
+	DiscreteUniform  GeneratorName = "discrete-uniform"
+
+	InverseTransform    GeneratorName = "inverse-transform"
+	AcceptanceRejection GeneratorName = "acceptance-rejection"
+
+	TruncatedNormal      GeneratorName = "truncated-normal"
+	TruncatedExponential GeneratorName = "truncated-exponential"
+	Truncated            GeneratorName = "truncated"
+
+	Mixture  GeneratorName = "mixture"
+	Compound GeneratorName = "compound"
+
+	UniformBits GeneratorName = "uniform-bits"
+
+	Halton        GeneratorName = "halton"
+	Sobol         GeneratorName = "sobol"
+	Lattice       GeneratorName = "lattice"
+	QMCProjection GeneratorName = "qmc-projection"
+
+	PCG32              GeneratorName = "pcg32"
+	PCG64              GeneratorName = "pcg64"
+	SplitMix64         GeneratorName = "splitmix64"
+	Xoshiro256StarStar GeneratorName = "xoshiro256**"
+	Xoroshiro128Plus   GeneratorName = "xoroshiro128+"
+	MT19937            GeneratorName = "mt19937"
+
+	WichmannHill GeneratorName = "wichmann-hill"
+	LEcuyer      GeneratorName = "lecuyer"
+	MRG32k3a     GeneratorName = "mrg32k3a"
+
+	LaggedFibonacci   GeneratorName = "lagged-fibonacci"
+	SubtractWithCarry GeneratorName = "subtract-with-carry"
+	Ranlux            GeneratorName = "ranlux"
+
+	Philox   GeneratorName = "philox4x32"
+	Threefry GeneratorName = "threefry4x64"
+
+	mathRand GeneratorName = "math-rand"
 )
 
 type IntGenerator interface{ Int() int }
 
+type Uint64Generator interface{ Uint64() uint64 }
+
 type Float64Generator interface{ Float64() float64 }
 
 type ExpFloat64Generator interface{ ExpFloat64() float64 }
@@ -35,23 +101,57 @@ type DistributionGenerator interface {
 	Name() string
 }
 
+// QuantileGenerator is a distribution generator with a known inverse of the
+// cumulative distribution function
+type QuantileGenerator interface {
+	DistributionGenerator
+	Quantile(p float64) float64
+}
+
+// Distribution is a distribution generator describing the theoretical law of
+// its values, goodness-of-fit tests read the law from the generator
+type Distribution interface {
+	QuantileGenerator
+	PDF(x float64) float64
+	CDF(x float64) float64
+	Mean() float64
+	Variance() float64
+}
+
+// CongruentialGenerator is the linear congruential generator
+// x -> (multiplier*x + additiveComponent) mod modulus
+//
+// the state is kept in uint64 and the product is computed in 128 bits, so any
+// modulus up to 2^64 (represented by 0) is supported without overflow
 type CongruentialGenerator struct {
 	name    GeneratorName
-	n       int
-	m       int
-	a       int
-	initial int
-	current int
+	n       uint64
+	m       uint64
+	a       uint64
+	initial uint64
+	current uint64
 }
 
 func NewCongruentialGenerator(modulus int, multiplier int, additiveComponent int, initialValue int) *CongruentialGenerator {
+	if modulus <= 0 {
+		panic("modulus is not positive")
+	}
+
+	n := uint64(modulus)
+
+	return NewCongruentialGenerator64(n, reduce(multiplier, n), reduce(additiveComponent, n), reduce(initialValue, n))
+}
+
+// NewCongruentialGenerator64 builds a generator with unsigned 64-bit parameters,
+// modulus 0 stands for 2^64
+func NewCongruentialGenerator64(modulus uint64, multiplier uint64, additiveComponent uint64, initialValue uint64) *CongruentialGenerator {
 	return &CongruentialGenerator{
 		name:    Congruential,
 		n:       modulus,
-		m:       multiplier,
-		a:       additiveComponent,
-		current: initialValue,
-		initial: initialValue,
+		m:       modMod(multiplier, modulus),
+		a:       modMod(additiveComponent, modulus),
+		current: modMod(initialValue, modulus),
+		initial: modMod(initialValue, modulus),
 	}
 }
 
@@ -63,7 +163,7 @@ func (cg *CongruentialGenerator) String() string {
 	d := make(map[string]interface{}, 5)
 
 	d["distributionName"] = cg.name
-	d["modulus"] = cg.n
+	d["modulus"] = modulusNumber(cg.n)
 	d["multiplier"] = cg.m
 	d["additiveComponent"] = cg.a
 	d["initialValue"] = cg.initial
@@ -75,11 +175,60 @@ func (cg *CongruentialGenerator) String() string {
 	}
 }
 
+// Uint64 advances the generator and returns the new state
+func (cg *CongruentialGenerator) Uint64() uint64 {
+	cg.current = addMod(mulMod(cg.current, cg.m, cg.n), cg.a, cg.n)
+
+	return cg.current
+}
+
+// Int advances the generator and returns the new state, when the modulus
+// exceeds 2^63 the state is shifted right by one bit to fit int
 func (cg *CongruentialGenerator) Int() int {
-	val := (cg.current*cg.m + cg.a) % cg.n
-	cg.current = val
+	val := cg.Uint64()
+
+	if cg.n == 0 || cg.n > math.MaxInt64+1 {
+		return int(val >> 1)
+	}
+
+	return int(val)
+}
+
+// Bits returns the count of random bits of Int values, the values are
+// uniform on the bits only when the modulus is a power of 2, 0 is returned
+// for other moduli
+func (cg *CongruentialGenerator) Bits() uint {
+	switch {
+	case cg.n == 0:
+		// Int drops the lowest bit of the 64-bit state
+		return 63
+	case cg.n&(cg.n-1) != 0:
+		return 0
+	default:
+		return uint(bits.TrailingZeros64(cg.n))
+	}
+}
+
+// Seed restarts the sequence from seed (reduced by the modulus)
+func (cg *CongruentialGenerator) Seed(seed int64) {
+	var v uint64
+	if cg.n == 0 {
+		v = uint64(seed)
+	} else {
+		v = reduce(int(seed), cg.n)
+	}
+
+	cg.initial = v
+	cg.current = v
+}
+
+// modulusNumber formats the modulus for JSON, 0 stands for 2^64
+func modulusNumber(n uint64) json.Number {
+	if n == 0 {
+		return json.Number("18446744073709551616")
+	}
 
-	return val
+	return json.Number(strconv.FormatUint(n, 10))
 }
 
 type UniformGenerator struct {
@@ -93,11 +242,13 @@ func (ug *UniformGenerator) Name() string {
 }
 
 func (ug *UniformGenerator) String() string {
-	d := make(map[string]interface{}, 2)
+	d := make(map[string]interface{}, 3)
 
 	d["distributionName"] = ug.name
 	d["modulus"] = ug.m
 
+	describeSource(d, "source", ug.g)
+
 	if b, err := json.Marshal(d); err != nil {
 		return fmt.Sprintf("%v", d)
 	} else {
@@ -106,7 +257,7 @@ func (ug *UniformGenerator) String() string {
 }
 
 func NewUniformGeneratorDefault() *UniformGenerator {
-	return &UniformGenerator{name: Uniform, g: rand.New(rand.NewSource(rand.Int63())), m: math.MaxInt32}
+	return &UniformGenerator{name: Uniform, g: newDefaultGenerator(), m: math.MaxInt32}
 }
 
 func NewUniformGenerator(generator IntGenerator, modulus int) *UniformGenerator {
@@ -125,6 +276,33 @@ func (ug *UniformGenerator) Float64() float64 {
 	return float64(ug.g.Int()) / float64(ug.m)
 }
 
+// Quantile returns the inverse of the [0, 1) uniform distribution function
+func (ug *UniformGenerator) Quantile(p float64) float64 {
+	return p
+}
+
+func (ug *UniformGenerator) PDF(x float64) float64 {
+	if x < 0 || x >= 1 {
+		return 0
+	}
+
+	return 1
+}
+
+func (ug *UniformGenerator) CDF(x float64) float64 {
+	return math.Min(math.Max(x, 0), 1)
+}
+
+func (ug *UniformGenerator) Mean() float64 {
+	return 0.5
+}
+
+func (ug *UniformGenerator) Variance() float64 {
+	return 1.0 / 12
+}
+
+// ExponentialGenerator produces exponential values with the density
+// l·exp(-l·x), l is the rate and 1/l the mean
 type ExponentialGenerator struct {
 	name GeneratorName
 	g    Float64Generator
@@ -136,11 +314,13 @@ func (eg *ExponentialGenerator) Name() string {
 }
 
 func (eg *ExponentialGenerator) String() string {
-	d := make(map[string]interface{}, 2)
+	d := make(map[string]interface{}, 3)
 
 	d["distributionName"] = eg.name
 	d["rate"] = eg.l
 
+	describeSource(d, "source", eg.g)
+
 	if b, err := json.Marshal(d); err != nil {
 		return fmt.Sprintf("%v", d)
 	} else {
@@ -149,7 +329,7 @@ func (eg *ExponentialGenerator) String() string {
 }
 
 func NewExponentialGeneratorDefault() *ExponentialGenerator {
-	return &ExponentialGenerator{name: Exponential, g: rand.New(rand.NewSource(rand.Int63())), l: 1}
+	return &ExponentialGenerator{name: Exponential, g: newDefaultGenerator(), l: 1}
 }
 
 func NewExponentialGenerator(generator Float64Generator, rate float64) *ExponentialGenerator {
@@ -157,7 +337,44 @@ func NewExponentialGenerator(generator Float64Generator, rate float64) *Exponent
 }
 
 func (eg *ExponentialGenerator) ExpFloat64() float64 {
-	return -eg.l * math.Log(eg.g.Float64())
+	return -math.Log(openUniform(eg.g)) / eg.l
+}
+
+// Quantile returns the inverse of the distribution function of ExpFloat64 values
+func (eg *ExponentialGenerator) Quantile(p float64) float64 {
+	return -math.Log1p(-p) / eg.l
+}
+
+func (eg *ExponentialGenerator) PDF(x float64) float64 {
+	return exponentialPDF(x, eg.l)
+}
+
+func (eg *ExponentialGenerator) CDF(x float64) float64 {
+	return exponentialCDF(x, eg.l)
+}
+
+func (eg *ExponentialGenerator) Mean() float64 {
+	return 1 / eg.l
+}
+
+func (eg *ExponentialGenerator) Variance() float64 {
+	return 1 / (eg.l * eg.l)
+}
+
+func exponentialPDF(x float64, rate float64) float64 {
+	if x < 0 {
+		return 0
+	}
+
+	return rate * math.Exp(-rate*x)
+}
+
+func exponentialCDF(x float64, rate float64) float64 {
+	if x < 0 {
+		return 0
+	}
+
+	return -math.Expm1(-rate * x)
 }
 
 type NormalGenerator struct {
@@ -166,6 +383,11 @@ type NormalGenerator struct {
 	g2     Float64Generator
 	stdDev float64
 	mean   float64
+
+	// the polar method produces a pair of independent standard normal
+	// values, the second one is kept for the next call
+	spare    float64
+	hasSpare bool
 }
 
 func (ng *NormalGenerator) Name() string {
@@ -173,12 +395,15 @@ func (ng *NormalGenerator) Name() string {
 }
 
 func (ng *NormalGenerator) String() string {
-	d := make(map[string]interface{}, 3)
+	d := make(map[string]interface{}, 5)
 
 	d["distributionName"] = ng.name
 	d["standardDeviation"] = ng.stdDev
 	d["mean"] = ng.mean
 
+	describeSource(d, "source", ng.g)
+	describeSource(d, "secondSource", ng.g2)
+
 	if b, err := json.Marshal(d); err != nil {
 		return fmt.Sprintf("%v", d)
 	} else {
@@ -187,7 +412,7 @@ func (ng *NormalGenerator) String() string {
 }
 
 func NewNormalGeneratorDefault() *NormalGenerator {
-	return &NormalGenerator{name: Normal, g: rand.New(rand.NewSource(rand.Int63())), g2: rand.New(rand.NewSource(rand.Int63())), stdDev: 1, mean: 0}
+	return &NormalGenerator{name: Normal, g: newDefaultGenerator(), g2: newDefaultGenerator(), stdDev: 1, mean: 0}
 }
 
 func NewNormalGenerator(generator Float64Generator, secondGenerator Float64Generator, standardDeviation float64, mean float64) *NormalGenerator {
@@ -195,17 +420,59 @@ func NewNormalGenerator(generator Float64Generator, secondGenerator Float64Gener
 }
 
 func (ng *NormalGenerator) NormFloat64() float64 {
-	v1 := 2*ng.g.Float64() - 1
-	v2 := 2*ng.g2.Float64() - 1
-	S := math.Pow(v1, 2) + math.Pow(v2, 2)
+	if ng.hasSpare {
+		ng.hasSpare = false
 
-	if S >= 1 {
-		return ng.NormFloat64()
+		return ng.spare*ng.stdDev + ng.mean
 	}
 
-	intermediate := math.Sqrt(-2/S*math.Log(S)) * ng.stdDev
+	for {
+		v1 := 2*ng.g.Float64() - 1
+		v2 := 2*ng.g2.Float64() - 1
+		S := v1*v1 + v2*v2
 
-	return intermediate*v1 + ng.mean
+		if S >= 1 || S == 0 {
+			continue
+		}
+
+		intermediate := math.Sqrt(-2 / S * math.Log(S))
+
+		ng.spare = intermediate * v2
+		ng.hasSpare = true
+
+		return intermediate*v1*ng.stdDev + ng.mean
+	}
+}
+
+// Quantile returns the inverse of the normal distribution function
+func (ng *NormalGenerator) Quantile(p float64) float64 {
+	return ng.mean + ng.stdDev*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+func (ng *NormalGenerator) PDF(x float64) float64 {
+	return normalPDF(x, ng.stdDev, ng.mean)
+}
+
+func (ng *NormalGenerator) CDF(x float64) float64 {
+	return normalCDF(x, ng.stdDev, ng.mean)
+}
+
+func (ng *NormalGenerator) Mean() float64 {
+	return ng.mean
+}
+
+func (ng *NormalGenerator) Variance() float64 {
+	return ng.stdDev * ng.stdDev
+}
+
+func normalPDF(x float64, stdDev float64, mean float64) float64 {
+	z := (x - mean) / stdDev
+
+	return math.Exp(-z*z/2) / (stdDev * math.Sqrt(2*math.Pi))
+}
+
+func normalCDF(x float64, stdDev float64, mean float64) float64 {
+	return standardNormalCDF((x - mean) / stdDev)
 }
 
 type FloatPair struct {
@@ -223,8 +490,21 @@ func (p FloatPairs) XY(idx int) (x, y float64) {
 	return p[idx].x, p[idx].y
 }
 
+// TwoDimensionalMethod selects how TwoDimensionalGenerator produces the
+// normal components of a pair
+type TwoDimensionalMethod string
+
+const (
+	// CentralLimitMethod approximates every normal value by a sum of six
+	// uniform values, the tails are cut at ±3·sqrt2·σ and the kurtosis is lower
+	CentralLimitMethod TwoDimensionalMethod = "central-limit"
+	// PolarMethod draws exact normal values with NormalGenerator
+	PolarMethod TwoDimensionalMethod = "polar"
+)
+
 type TwoDimensionalGenerator struct {
-	name GeneratorName
+	name   GeneratorName
+	method TwoDimensionalMethod
 
 	g  Float64Generator
 	g2 Float64Generator
@@ -246,15 +526,19 @@ func (tdg *TwoDimensionalGenerator) Name() string {
 }
 
 func (tdg *TwoDimensionalGenerator) String() string {
-	d := make(map[string]interface{}, 6)
+	d := make(map[string]interface{}, 9)
 
 	d["distributionName"] = tdg.name
+	d["method"] = tdg.method
 	d["standardDeviationX"] = tdg.stdDevX
 	d["standardDeviationY"] = tdg.stdDevY
 	d["meanX"] = tdg.meanX
 	d["meanY"] = tdg.meanY
 	d["correlationCoefficient"] = tdg.correlationCoefficient
 
+	describeSource(d, "source", tdg.g)
+	describeSource(d, "secondSource", tdg.g2)
+
 	if b, err := json.Marshal(d); err != nil {
 		return fmt.Sprintf("%v", d)
 	} else {
@@ -265,8 +549,9 @@ func (tdg *TwoDimensionalGenerator) String() string {
 func NewTwoDimensionalGeneratorDefault() *TwoDimensionalGenerator {
 	return &TwoDimensionalGenerator{
 		name:                   TwoDimensional,
-		g:                      rand.New(rand.NewSource(rand.Int63())),
-		g2:                     rand.New(rand.NewSource(rand.Int63())),
+		method:                 CentralLimitMethod,
+		g:                      newDefaultGenerator(),
+		g2:                     newDefaultGenerator(),
 		stdDevX:                1,
 		stdDevY:                1,
 		meanX:                  0,
@@ -286,6 +571,7 @@ func NewTwoDimensionalGenerator(
 ) *TwoDimensionalGenerator {
 	return &TwoDimensionalGenerator{
 		name:                   TwoDimensional,
+		method:                 CentralLimitMethod,
 		g:                      generator,
 		g2:                     secondGenerator,
 		stdDevX:                standardDeviationX,
@@ -296,7 +582,22 @@ func NewTwoDimensionalGenerator(
 	}
 }
 
+// WithMethod selects the method used to produce the normal components,
+// CentralLimitMethod is used by default
+func (tdg *TwoDimensionalGenerator) WithMethod(method TwoDimensionalMethod) *TwoDimensionalGenerator {
+	if method != CentralLimitMethod && method != PolarMethod {
+		panic(fmt.Sprintf("unknown two-dimensional method %q", method))
+	}
+
+	tdg.method = method
+	return tdg
+}
+
 func (tdg *TwoDimensionalGenerator) TwoDimensionalFloat64s() FloatPair {
+	if tdg.method == PolarMethod {
+		return tdg.polarFloat64s()
+	}
+
 	SxComponents := make([]float64, 0, 6)
 	SyComponents := make([]float64, 0, 6)
 
@@ -317,3 +618,18 @@ func (tdg *TwoDimensionalGenerator) TwoDimensionalFloat64s() FloatPair {
 
 	return FloatPair{x: x, y: y}
 }
+
+// polarFloat64s builds the pair from two independent standard normal values
+// z1, z2: x = m_x + σ_x z1, y = m_y + σ_y (r z1 + sqrt(1 - r²) z2)
+func (tdg *TwoDimensionalGenerator) polarFloat64s() FloatPair {
+	normal := NormalGenerator{name: Normal, g: tdg.g, g2: tdg.g2, stdDev: 1, mean: 0}
+
+	z1 := normal.NormFloat64()
+	z2 := normal.NormFloat64()
+
+	r := tdg.correlationCoefficient
+	x := tdg.stdDevX*z1 + tdg.meanX
+	y := tdg.stdDevY*(r*z1+math.Sqrt(1-r*r)*z2) + tdg.meanY
+
+	return FloatPair{x: x, y: y}
+}
@@ -1,8 +1,14 @@
 package generators
 
 import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/floats"
 	"math"
+	"math/big"
 	"math/rand"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +22,86 @@ func BenchmarkCongruentialGenerator(b *testing.B) {
 	}
 }
 
+func BenchmarkPCG32Generator(b *testing.B) {
+	g := NewPCG32Generator(42, 54)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkPCG64Generator(b *testing.B) {
+	g := NewPCG64Generator(42, 54)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkSplitMix64Generator(b *testing.B) {
+	g := NewSplitMix64Generator(42)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkXoshiro256StarStarGenerator(b *testing.B) {
+	g := NewXoshiro256StarStarGenerator(42)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkXoroshiro128PlusGenerator(b *testing.B) {
+	g := NewXoroshiro128PlusGenerator(42)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkMT19937Generator(b *testing.B) {
+	g := NewMT19937Generator(5489)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkPhiloxGenerator(b *testing.B) {
+	g := NewPhiloxGenerator(42, 54)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
+func BenchmarkThreefryGenerator(b *testing.B) {
+	g := NewThreefryGenerator(42, 54)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.Int()
+	}
+}
+
 func BenchmarkStdCongruentialGenerator(b *testing.B) {
 	for i := 0; i < b.N; i += 1 {
 		_ = rand.Int()
@@ -61,6 +147,16 @@ func BenchmarkChainedUniformGenerator(b *testing.B) {
 	}
 }
 
+func BenchmarkPCG32UniformGenerator(b *testing.B) {
+	ug := NewUniformGenerator(NewPCG32Generator(42, 54), math.MaxUint32+1)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = ug.Float64()
+	}
+}
+
 func BenchmarkExponentialGenerator(b *testing.B) {
 	s := rand.NewSource(rand.Int63())
 
@@ -101,6 +197,41 @@ func BenchmarkChainedExponentialGenerator(b *testing.B) {
 	}
 }
 
+func BenchmarkZigguratExponentialGenerator(b *testing.B) {
+	s := rand.NewSource(rand.Int63())
+
+	g := NewZigguratExponentialGenerator(rand.New(s), 1)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.ExpFloat64()
+	}
+}
+
+func BenchmarkChainedZigguratExponentialGenerator(b *testing.B) {
+	cg := NewCongruentialGenerator(int(math.Pow(2, 32)), 1103515245, 12345, 0)
+	ug := NewUniformGenerator(cg, int(math.Pow(2, 32)))
+	eg := NewZigguratExponentialGenerator(ug, 345)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = eg.ExpFloat64()
+	}
+}
+
+func BenchmarkPCG32ZigguratExponentialGenerator(b *testing.B) {
+	ug := NewUniformGenerator(NewPCG32Generator(42, 54), math.MaxUint32+1)
+	eg := NewZigguratExponentialGenerator(ug, 1)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = eg.ExpFloat64()
+	}
+}
+
 func BenchmarkNormalGenerator(b *testing.B) {
 	s := rand.NewSource(rand.Int63())
 	s2 := rand.NewSource(rand.Int63())
@@ -146,6 +277,64 @@ func BenchmarkChainedNormalGenerator(b *testing.B) {
 	}
 }
 
+func BenchmarkZigguratNormalGenerator(b *testing.B) {
+	s := rand.NewSource(rand.Int63())
+
+	g := NewZigguratNormalGenerator(rand.New(s), 1, 0)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = g.NormFloat64()
+	}
+}
+
+func BenchmarkChainedZigguratNormalGenerator(b *testing.B) {
+	cg := NewCongruentialGenerator(int(math.Pow(2, 32)), 1103515245, 12345, 0)
+	ug := NewUniformGenerator(cg, int(math.Pow(2, 32)))
+
+	ng := NewZigguratNormalGenerator(ug, 1, 2)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = ng.NormFloat64()
+	}
+}
+
+func BenchmarkPCG32NormalGenerator(b *testing.B) {
+	ug := NewUniformGenerator(NewPCG32Generator(42, 54), math.MaxUint32+1)
+	ng := NewNormalGenerator(ug, ug, 1, 0)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = ng.NormFloat64()
+	}
+}
+
+func BenchmarkPCG32ZigguratNormalGenerator(b *testing.B) {
+	ug := NewUniformGenerator(NewPCG32Generator(42, 54), math.MaxUint32+1)
+	ng := NewZigguratNormalGenerator(ug, 1, 0)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = ng.NormFloat64()
+	}
+}
+
+func BenchmarkGammaGenerator(b *testing.B) {
+	ug := NewUniformGenerator(NewPCG32Generator(42, 54), math.MaxUint32+1)
+	gg, _ := NewGammaGenerator(ug, NewZigguratNormalGenerator(ug, 1, 0), 2.5, 1)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = gg.GammaFloat64()
+	}
+}
+
 func BenchmarkTwoDimensionalGenerator(b *testing.B) {
 	s := rand.NewSource(rand.Int63())
 	s2 := rand.NewSource(rand.Int63())
@@ -175,6 +364,19 @@ func BenchmarkChainedTwoDimensionalGenerator(b *testing.B) {
 	}
 }
 
+func BenchmarkPolarTwoDimensionalGenerator(b *testing.B) {
+	s := rand.NewSource(1)
+	s2 := rand.NewSource(2)
+
+	tdg := NewTwoDimensionalGenerator(rand.New(s), rand.New(s2), 1, 1, 0, 0, 0.5).WithMethod(PolarMethod)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		_ = tdg.TwoDimensionalFloat64s()
+	}
+}
+
 func BenchmarkTwoDimensionalGeneratorDefault(b *testing.B) {
 	tdg := NewTwoDimensionalGeneratorDefault()
 
@@ -184,3 +386,2458 @@ func BenchmarkTwoDimensionalGeneratorDefault(b *testing.B) {
 		_ = tdg.TwoDimensionalFloat64s()
 	}
 }
+
+func TestStateRoundTrip(t *testing.T) {
+	newChain := func() (*UniformGenerator, *NormalGenerator) {
+		cg := NewCongruentialGenerator(int(math.Pow(2, 32)), 1103515245, 12345, 0)
+		ug := NewUniformGenerator(cg, int(math.Pow(2, 32)))
+
+		cg2 := NewCongruentialGenerator(int(math.Pow(2, 32)), 134775813, 1, 3)
+		ug2 := NewUniformGenerator(cg2, int(math.Pow(2, 32)))
+
+		return ug, NewNormalGenerator(ug, ug2, 1, 2)
+	}
+
+	cases := []struct {
+		name     string
+		g        StatefulGenerator
+		restored StatefulGenerator
+		next     func(g StatefulGenerator) float64
+	}{
+		{
+			name:     "congruential",
+			g:        NewCongruentialGenerator(int(math.Pow(2, 32)), 1103515245, 12345, 0),
+			restored: &CongruentialGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*CongruentialGenerator).Int()) },
+		},
+		{
+			name:     "uniform",
+			g:        func() StatefulGenerator { ug, _ := newChain(); return ug }(),
+			restored: &UniformGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*UniformGenerator).Float64() },
+		},
+		{
+			name:     "pcg64",
+			g:        NewPCG64Generator(1, 2),
+			restored: &PCG64Generator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*PCG64Generator).Int()) },
+		},
+		{
+			name:     "xoshiro256**",
+			g:        NewXoshiro256StarStarGenerator(1),
+			restored: &Xoshiro256StarStarGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*Xoshiro256StarStarGenerator).Int()) },
+		},
+		{
+			name:     "mt19937 uniform",
+			g:        NewUniformGenerator(NewMT19937Generator(1), math.MaxUint32+1),
+			restored: &UniformGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*UniformGenerator).Float64() },
+		},
+		{
+			name: "mrg32k3a normal",
+			g: func() StatefulGenerator {
+				g, _ := NewMRG32k3aGenerator([6]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+				g2, _ := NewMRG32k3aGenerator([6]uint64{1, 2, 3, 4, 5, 6})
+				return NewNormalGenerator(g, g2, 1, 0)
+			}(),
+			restored: &NormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*NormalGenerator).NormFloat64() },
+		},
+		{
+			name: "ranlux",
+			g: func() StatefulGenerator {
+				g, _ := NewRanluxGenerator(2, 1)
+				return g
+			}(),
+			restored: &RanluxGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*RanluxGenerator).Float64() },
+		},
+		{
+			name: "philox normal",
+			g: func() StatefulGenerator {
+				g := NewUniformGenerator(NewPhiloxGenerator(1, 2), math.MaxUint32+1)
+				g2 := NewUniformGenerator(NewPhiloxGenerator(1, 3), math.MaxUint32+1)
+				return NewNormalGenerator(g, g2, 1, 0)
+			}(),
+			restored: &NormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*NormalGenerator).NormFloat64() },
+		},
+		{
+			name:     "threefry",
+			g:        NewThreefryGenerator(1, 2),
+			restored: &ThreefryGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*ThreefryGenerator).Int()) },
+		},
+		{
+			name: "multivariate normal",
+			g: func() StatefulGenerator {
+				g := NewUniformGenerator(NewPhiloxGenerator(1, 2), math.MaxUint32+1)
+				g2 := NewUniformGenerator(NewPhiloxGenerator(1, 3), math.MaxUint32+1)
+				mg, _ := NewMultivariateNormalGenerator(NewNormalGenerator(g, g2, 1, 0), []float64{1, 2}, [][]float64{{2, 1}, {1, 2}})
+				return mg
+			}(),
+			restored: &MultivariateNormalGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				return g.(*MultivariateNormalGenerator).MultivariateNormFloat64s()[1]
+			},
+		},
+		{
+			name: "gumbel copula",
+			g: func() StatefulGenerator {
+				newUniform := func(sequence uint64) *UniformGenerator {
+					return NewUniformGenerator(NewPCG32Generator(1, sequence), math.MaxUint32+1)
+				}
+				x := NewExponentialGenerator(newUniform(3), 0.5)
+				cg, _ := NewGumbelCopulaGenerator(newUniform(1), newUniform(2), x, NewNormalGeneratorDefault(), 2)
+				return cg
+			}(),
+			restored: &CopulaGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				p := g.(*CopulaGenerator).TwoDimensionalFloat64s()
+				return p.x + p.y
+			},
+		},
+		{
+			name:     "ziggurat normal",
+			g:        NewZigguratNormalGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 2, 1),
+			restored: &ZigguratNormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*ZigguratNormalGenerator).NormFloat64() },
+		},
+		{
+			name:     "ziggurat exponential default",
+			g:        NewZigguratExponentialGeneratorDefault(),
+			restored: &ZigguratExponentialGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*ZigguratExponentialGenerator).ExpFloat64() },
+		},
+		{
+			name: "beta",
+			g: func() StatefulGenerator {
+				ug := NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1)
+				ug2 := NewUniformGenerator(NewPCG32Generator(1, 3), math.MaxUint32+1)
+				bg, _ := NewBetaGenerator(ug, NewZigguratNormalGenerator(ug2, 1, 0), 0.5, 2)
+				return bg
+			}(),
+			restored: &BetaGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*BetaGenerator).BetaFloat64() },
+		},
+		{
+			name: "stable",
+			g: func() StatefulGenerator {
+				sg, _ := NewStableGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 1.5, 0.5, 2, 1)
+				return sg
+			}(),
+			restored: &StableGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*StableGenerator).StableFloat64() },
+		},
+		{
+			name: "student-t",
+			g: func() StatefulGenerator {
+				sg, _ := NewStudentTGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 3, 0, 1)
+				return sg
+			}(),
+			restored: &StudentTGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*StudentTGenerator).StudentTFloat64() },
+		},
+		{
+			name: "log-normal",
+			g: func() StatefulGenerator {
+				lg, _ := NewLogNormalGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 0.5, 1)
+				return lg
+			}(),
+			restored: &LogNormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*LogNormalGenerator).LogNormalFloat64() },
+		},
+		{
+			name: "uniform bits",
+			g: func() StatefulGenerator {
+				g, _ := NewRanluxGenerator(2, 3)
+				return NewBitGeneratorWithBits(g, 24)
+			}(),
+			restored: &BitGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*BitGenerator).OpenFloat64() },
+		},
+		{
+			name: "qmc projection",
+			g: func() StatefulGenerator {
+				sg, _ := NewSobolGenerator(3)
+				qp, _ := NewQMCProjectionGenerator(sg.WithRandomization(OwenScrambling, 7), 2)
+				return qp
+			}(),
+			restored: &QMCProjectionGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*QMCProjectionGenerator).Float64() },
+		},
+		{
+			name: "halton",
+			g: func() StatefulGenerator {
+				hg, _ := NewHaltonGenerator(5)
+				return hg.WithRandomization(DigitPermutation, 3)
+			}(),
+			restored: &HaltonGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*HaltonGenerator).Float64() },
+		},
+		{
+			name: "lattice",
+			g: func() StatefulGenerator {
+				lg, _ := NewKorobovLatticeGenerator(1021, 76, 3)
+				return lg.WithRandomization(RandomShift, 5)
+			}(),
+			restored: &LatticeGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*LatticeGenerator).Float64() },
+		},
+		{
+			name: "pareto default",
+			g: func() StatefulGenerator {
+				pg, _ := NewParetoGeneratorDefault(1, 2.5)
+				return pg
+			}(),
+			restored: &ParetoGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*ParetoGenerator).ParetoFloat64() },
+		},
+		{
+			name: "binomial",
+			g: func() StatefulGenerator {
+				bg, _ := NewBinomialGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 1000, 0.3)
+				return bg
+			}(),
+			restored: &BinomialGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*BinomialGenerator).BinomialInt()) },
+		},
+		{
+			name: "negative binomial",
+			g: func() StatefulGenerator {
+				ug := NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1)
+				ug2 := NewUniformGenerator(NewPCG32Generator(1, 3), math.MaxUint32+1)
+				ng, _ := NewNegativeBinomialGenerator(ug, NewZigguratNormalGenerator(ug2, 1, 0), 2.5, 0.1)
+				return ng
+			}(),
+			restored: &NegativeBinomialGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				return float64(g.(*NegativeBinomialGenerator).NegativeBinomialInt())
+			},
+		},
+		{
+			name: "discrete uniform",
+			g: func() StatefulGenerator {
+				dg, _ := NewDiscreteUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1, -5, 1<<40)
+				return dg
+			}(),
+			restored: &DiscreteUniformGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*DiscreteUniformGenerator).DiscreteUniformInt()) },
+		},
+		{
+			name: "discrete uniform default",
+			g: func() StatefulGenerator {
+				dg, _ := NewDiscreteUniformGeneratorDefault(math.MinInt64, math.MaxInt64)
+				return dg
+			}(),
+			restored: &DiscreteUniformGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*DiscreteUniformGenerator).DiscreteUniformInt()) },
+		},
+		{
+			name: "poisson default",
+			g: func() StatefulGenerator {
+				pg, _ := NewPoissonGeneratorDefault(42)
+				return pg
+			}(),
+			restored: &PoissonGenerator{},
+			next:     func(g StatefulGenerator) float64 { return float64(g.(*PoissonGenerator).PoissonInt()) },
+		},
+		{
+			name: "truncated normal",
+			g: func() StatefulGenerator {
+				tg, _ := NewTruncatedNormalGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 2, 1, 5, math.Inf(1))
+				return tg
+			}(),
+			restored: &TruncatedNormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*TruncatedNormalGenerator).TruncatedFloat64() },
+		},
+		{
+			name: "truncated",
+			g: func() StatefulGenerator {
+				cg, _ := NewCauchyGenerator(NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1), 0, 1)
+				tg, _ := NewTruncatedGenerator(NewUniformGenerator(NewPCG32Generator(1, 3), math.MaxUint32+1), cg, -2, 10)
+				return tg
+			}(),
+			restored: &TruncatedGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*TruncatedGenerator).TruncatedFloat64() },
+		},
+		{
+			name: "dirichlet default",
+			g: func() StatefulGenerator {
+				dg, _ := NewDirichletGeneratorDefault([]float64{1, 2, 3})
+				return dg
+			}(),
+			restored: &DirichletGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*DirichletGenerator).DirichletFloat64s()[1] },
+		},
+		{
+			name:     "uniform default",
+			g:        NewUniformGeneratorDefault(),
+			restored: &UniformGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*UniformGenerator).Float64() },
+		},
+		{
+			name:     "exponential default",
+			g:        NewExponentialGeneratorDefault(),
+			restored: &ExponentialGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*ExponentialGenerator).ExpFloat64() },
+		},
+		{
+			name:     "normal",
+			g:        func() StatefulGenerator { _, ng := newChain(); return ng }(),
+			restored: &NormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*NormalGenerator).NormFloat64() },
+		},
+		{
+			name: "normal shared source",
+			g: func() StatefulGenerator {
+				ug := NewUniformGenerator(NewPhiloxGenerator(1, 2), math.MaxUint32+1)
+				return NewNormalGenerator(ug, ug, 1, 0)
+			}(),
+			restored: &NormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*NormalGenerator).NormFloat64() },
+		},
+		{
+			name: "two-dimensional polar shared source",
+			g: func() StatefulGenerator {
+				ug := NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1)
+				return NewTwoDimensionalGenerator(ug, ug, 1, 2, 0, 1, 0.5).WithMethod(PolarMethod)
+			}(),
+			restored: &TwoDimensionalGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				p := g.(*TwoDimensionalGenerator).TwoDimensionalFloat64s()
+				return p.x + p.y
+			},
+		},
+		{
+			name: "clayton copula shared source",
+			g: func() StatefulGenerator {
+				// the marginal only supplies quantiles, its source may be shared
+				ug := NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1)
+				x := NewExponentialGenerator(ug, 0.5)
+				cg, _ := NewClaytonCopulaGenerator(ug, ug, x, x, 2)
+				return cg
+			}(),
+			restored: &CopulaGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				p := g.(*CopulaGenerator).TwoDimensionalFloat64s()
+				return p.x + p.y
+			},
+		},
+		{
+			name:     "normal default",
+			g:        NewNormalGeneratorDefault(),
+			restored: &NormalGenerator{},
+			next:     func(g StatefulGenerator) float64 { return g.(*NormalGenerator).NormFloat64() },
+		},
+		{
+			name:     "two-dimensional default",
+			g:        NewTwoDimensionalGeneratorDefault(),
+			restored: &TwoDimensionalGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				p := g.(*TwoDimensionalGenerator).TwoDimensionalFloat64s()
+				return p.x + p.y
+			},
+		},
+		{
+			name:     "two-dimensional polar",
+			g:        NewTwoDimensionalGeneratorDefault().WithMethod(PolarMethod),
+			restored: &TwoDimensionalGenerator{},
+			next: func(g StatefulGenerator) float64 {
+				p := g.(*TwoDimensionalGenerator).TwoDimensionalFloat64s()
+				return p.x + p.y
+			},
+		},
+	}
+
+	for _, c := range cases {
+		for i := 0; i < 1000; i += 1 {
+			_ = c.next(c.g)
+		}
+
+		state, err := c.g.MarshalBinary()
+		if err != nil {
+			t.Errorf("%s: can't marshal state: %s", c.name, err)
+			continue
+		}
+
+		if err := c.restored.UnmarshalBinary(state); err != nil {
+			t.Errorf("%s: can't unmarshal state: %s", c.name, err)
+			continue
+		}
+
+		for i := 0; i < 1000; i += 1 {
+			expected, got := c.next(c.g), c.next(c.restored)
+			if expected != got {
+				t.Errorf("%s: step %d: expected %f got %f", c.name, i, expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestDefaultGeneratorState(t *testing.T) {
+	// the values cross the reseeds of several blocks
+	g := newDefaultGenerator()
+	for i := 0; i < 3*seededSourceBlock+5; i += 1 {
+		_ = g.Int63()
+	}
+
+	state, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := &defaultGenerator{}
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2*seededSourceBlock; i += 1 {
+		if expected, got := g.Int63(), restored.Int63(); expected != got {
+			t.Fatalf("step %d: expected %d got %d", i, expected, got)
+		}
+	}
+
+	// a restore replays the values of the last block only, the value before
+	// the start of a block leads to the state at the start
+	before, at := &defaultGenerator{}, &defaultGenerator{}
+	for _, r := range []struct {
+		g     *defaultGenerator
+		steps uint64
+	}{{before, 1<<40 - 1}, {at, 1 << 40}} {
+		state, _ := json.Marshal(defaultState{Name: mathRand, Seed: 7, Steps: r.steps})
+		if err := r.g.UnmarshalBinary(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_ = before.Uint64()
+	for i := 0; i < 10; i += 1 {
+		if expected, got := at.Uint64(), before.Uint64(); expected != got {
+			t.Fatalf("step 2^40 + %d: expected %d got %d", i, expected, got)
+		}
+	}
+}
+
+func TestSharedSourceState(t *testing.T) {
+	ug := NewUniformGenerator(NewPCG32Generator(1, 2), math.MaxUint32+1)
+
+	state, err := NewNormalGenerator(ug, ug, 1, 0).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s struct {
+		SecondSource string `json:"secondSource"`
+	}
+	if err := json.Unmarshal(state, &s); err != nil || s.SecondSource != "source" {
+		t.Errorf("expected the second source to refer to the source, got %s", state)
+	}
+
+	restored := &NormalGenerator{}
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.g != restored.g2 {
+		t.Errorf("expected a single restored source")
+	}
+
+	corrupt := []byte(strings.Replace(string(state), `"secondSource":"source"`, `"secondSource":"normal"`, 1))
+	if err := restored.UnmarshalBinary(corrupt); errors.Cause(err) != ErrInvalidState {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+
+}
+
+func TestSource(t *testing.T) {
+	modulus := int(math.Pow(2, 32))
+	s := NewSource(NewCongruentialGenerator(modulus, 1103515245, 12345, 0), 32)
+	cg := NewCongruentialGenerator(modulus, 1103515245, 12345, 0)
+
+	for i := 0; i < 100; i += 1 {
+		expected := uint64(cg.Int())<<32 | uint64(cg.Int())
+		if got := s.Uint64(); got != expected {
+			t.Fatalf("step %d: expected %d got %d", i, expected, got)
+		}
+	}
+
+	s.Seed(0)
+	cg.Seed(0)
+	if got, expected := s.Int63(), int64((uint64(cg.Int())<<32|uint64(cg.Int()))&math.MaxInt64); got != expected {
+		t.Errorf("after seed: expected %d got %d", expected, got)
+	}
+
+	r := rand.New(NewFloat64Source(NewUniformGenerator(cg, modulus), 32))
+	p := r.Perm(10)
+	seen := make(map[int]bool, len(p))
+	for _, v := range p {
+		seen[v] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("expected permutation of 10 items, got %v", p)
+	}
+
+	es := NewExpSource(cg, 32)
+	es.Seed(42)
+	if cg.Int() != int((42*1103515245+12345)%modulus) {
+		t.Errorf("exp source seed was not propagated")
+	}
+}
+
+func TestKnownAnswers(t *testing.T) {
+	pcg32 := NewPCG32Generator(42, 54)
+	for i, expected := range []uint32{0xa15c02b7, 0x7b47f409, 0xba1d3330, 0x83d2f293, 0xbfa4784b, 0xcbed606e} {
+		if got := pcg32.Uint32(); got != expected {
+			t.Errorf("pcg32: step %d: expected %#x got %#x", i, expected, got)
+		}
+	}
+
+	pcg64 := NewPCG64Generator(42, 54)
+	for i, expected := range []uint64{0x86b1da1d72062b68, 0x1304aa46c9853d39, 0xa3670e9e0dd50358, 0xf9090e529a7dae00} {
+		if got := pcg64.Uint64(); got != expected {
+			t.Errorf("pcg64: step %d: expected %#x got %#x", i, expected, got)
+		}
+	}
+
+	if got := NewSplitMix64Generator(0).Uint64(); got != 0xe220a8397b1dcdaf {
+		t.Errorf("splitmix64: expected %#x got %#x", uint64(0xe220a8397b1dcdaf), got)
+	}
+
+	mt := NewMT19937Generator(5489)
+	if got := mt.Uint32(); got != 3499211612 {
+		t.Errorf("mt19937: expected %d got %d", 3499211612, got)
+	}
+	for i := 2; i < 10000; i += 1 {
+		_ = mt.Uint32()
+	}
+	if got := mt.Uint32(); got != 4123659995 {
+		t.Errorf("mt19937: 10000th value: expected %d got %d", 4123659995, got)
+	}
+
+	xoroshiro, reference := NewXoroshiro128PlusGenerator(7), NewXoroshiro128PlusGenerator(7)
+	for i := 0; i < 100; i += 1 {
+		if expected, got := int(reference.Uint64()>>11), xoroshiro.Int(); expected != got {
+			t.Fatalf("xoroshiro128+: value %d: expected the upper 53 bits %d got %d", i, expected, got)
+		}
+	}
+}
+
+func TestCongruentialSkip(t *testing.T) {
+	modulus := int(math.Pow(2, 32))
+
+	for _, n := range []uint64{0, 1, 2, 7, 1000, 65537} {
+		cg := NewCongruentialGenerator(modulus, 1103515245, 12345, 17)
+		skipped := NewCongruentialGenerator(modulus, 1103515245, 12345, 17)
+
+		for i := uint64(0); i < n; i += 1 {
+			_ = cg.Int()
+		}
+		skipped.Skip(n)
+
+		if expected, got := cg.Int(), skipped.Int(); expected != got {
+			t.Errorf("skip %d: expected %d got %d", n, expected, got)
+		}
+	}
+
+	cg := NewCongruentialGenerator(modulus, 1103515245, 12345, 0)
+	streams, err := cg.Substreams(4, uint64(modulus/4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, s := range streams {
+		expected := cg.Jump(uint64(i) * uint64(modulus/4)).Int()
+		if got := s.Int(); got != expected {
+			t.Errorf("substream %d: expected %d got %d", i, expected, got)
+		}
+	}
+
+	full := cg.Jump(uint64(modulus))
+	if full.Int() != cg.Int() {
+		t.Errorf("full period jump should return to the starting point")
+	}
+
+	// the streams are checked against the period, not the modulus
+	for _, c := range []struct {
+		g      *CongruentialGenerator
+		count  int
+		length uint64
+		valid  bool
+	}{
+		{NewCongruentialGenerator(math.MaxInt32, 16807, 0, 1), 2, (math.MaxInt32 - 1) / 2, true},
+		{NewCongruentialGenerator(math.MaxInt32, 16807, 0, 1), 2, 1 << 30, false},
+		{NewCongruentialGenerator(16, 3, 2, 1), 2, 2, false},
+		{NewCongruentialGenerator64(0, 6364136223846793005, 1442695040888963407, 1), 1 << 16, 1 << 48, true},
+		{NewCongruentialGenerator64(0, 6364136223846793005, 0, 1), 4, 1 << 61, false},
+		{cg, 0, 1, false},
+	} {
+		if _, err := c.g.Substreams(c.count, c.length); (err == nil) != c.valid || err != nil && errors.Cause(err) != ErrInvalidParameters {
+			t.Errorf("%s: %d substreams of length %d: unexpected error %v", c.g, c.count, c.length, err)
+		}
+	}
+
+	mmix := NewCongruentialGenerator64(0, 6364136223846793005, 1442695040888963407, 1)
+	skipped := mmix.Jump(12345)
+	for i := 0; i < 12345; i += 1 {
+		_ = mmix.Uint64()
+	}
+	if expected, got := mmix.Uint64(), skipped.Uint64(); expected != got {
+		t.Errorf("mmix skip: expected %d got %d", expected, got)
+	}
+}
+
+func TestAnalyzeCongruential(t *testing.T) {
+	cases := []struct {
+		modulus, multiplier, additiveComponent, initialValue int
+		fullPeriod                                           bool
+		period                                               uint64
+		accepted                                             bool
+	}{
+		// Numerical Recipes
+		{int(math.Pow(2, 32)), 1664525, 1013904223, 0, true, 1 << 32, true},
+		// MINSTD
+		{math.MaxInt32, 16807, 0, 1, false, math.MaxInt32 - 1, true},
+		// RANDU
+		{1 << 31, 65539, 0, 1, false, 1 << 29, true},
+		{math.MaxInt32, 2147483629, 2147483587, 255, false, 715827882, false},
+		{16, 3, 2, 1, false, 2, false},
+		// even multiplier: 1, 2, 4, 8, 0, 0, ...
+		{16, 2, 0, 1, false, 1, false},
+		{1 << 20, 6, 0, 3, false, 1, false},
+		// λ(101·103) = lcm(100, 102) = 5100
+		{101 * 103, 2, 0, 1, false, 5100, true},
+		{4, 3, 0, 1, false, 2, true},
+	}
+
+	for _, c := range cases {
+		g, analysis, err := NewCongruentialGeneratorChecked(c.modulus, c.multiplier, c.additiveComponent, c.initialValue)
+
+		if analysis.FullPeriod != c.fullPeriod || analysis.Period != c.period || !analysis.Exact {
+			t.Errorf("%+v: unexpected analysis %+v", c, analysis)
+		}
+
+		if c.accepted && (err != nil || g == nil) {
+			t.Errorf("%+v: expected generator, got error %v", c, err)
+		}
+
+		if !c.accepted && errors.Cause(err) != ErrShortPeriod {
+			t.Errorf("%+v: expected short period error, got %v", c, err)
+		}
+	}
+
+	// both prime factors are out of reach of trial division, λ is unknown
+	analysis, _ := AnalyzeCongruential(2147483647*2147483629, 2, 0, 1)
+	if analysis.Exact || analysis.MaximalPeriod != 0 {
+		t.Errorf("unfactored modulus: unexpected analysis %+v", analysis)
+	}
+
+	analysis, _ = AnalyzeCongruential(16, 2, 0, 1)
+	if !hasPrefix(analysis.Warnings, "even multiplier") {
+		t.Errorf("expected the even multiplier warning, got %q", analysis.Warnings)
+	}
+
+	if _, _, err := NewCongruentialGeneratorChecked(16, 0, 1, 0); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func hasPrefix(values []string, prefix string) bool {
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestSpectralTest(t *testing.T) {
+	// RANDU: x_{k+2} - 6x_{k+1} + 9x_k ≡ 0, all triples lie on 15 planes
+	dims, err := SpectralTest(1<<31, 65539, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dims[1].NuSquared.Int64() != 118 {
+		t.Errorf("RANDU: expected ν3² = 118 got %s", dims[1].NuSquared)
+	}
+
+	// MINSTD, Knuth's TAOCP vol. 2, table 3.3.4-1
+	dims, err = SpectralTest(math.MaxInt32, 16807, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []int64{282475250, 408197, 21682, 4439, 895} {
+		if dims[i].NuSquared.Int64() != expected {
+			t.Errorf("MINSTD: expected ν%d² = %d got %s", dims[i].Dimension, expected, dims[i].NuSquared)
+		}
+	}
+
+	// brute force the shortest dual lattice vector for a small modulus
+	modulus, multiplier := 1021, 65
+	dims, err = SpectralTest(modulus, multiplier, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range dims {
+		best := int64(math.MaxInt64)
+		limit := int64(math.Sqrt(float64(modulus))) + 1
+
+		var search func(s []int64)
+		search = func(s []int64) {
+			if len(s) == d.Dimension {
+				sum, power, length, zero := int64(0), int64(1), int64(0), true
+				for _, v := range s {
+					sum = (sum + v*power) % int64(modulus)
+					power = power * int64(multiplier) % int64(modulus)
+					length += v * v
+					zero = zero && v == 0
+				}
+
+				if !zero && sum == 0 && length < best {
+					best = length
+				}
+
+				return
+			}
+
+			for v := -limit; v <= limit; v += 1 {
+				search(append(s, v))
+			}
+		}
+		search(make([]int64, 0, d.Dimension))
+
+		if d.NuSquared.Int64() != best {
+			t.Errorf("dimension %d: expected ν² = %d got %s", d.Dimension, best, d.NuSquared)
+		}
+	}
+
+	ranks, err := RankMultipliers(1<<31, []int{65539, 1103515245}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ranks[0].Multiplier != 1103515245 {
+		t.Errorf("RANDU should be ranked last, got %+v", ranks)
+	}
+}
+
+func TestCongruentialClassicParameters(t *testing.T) {
+	// Numerical Recipes ranqd1 from 0
+	nr := NewCongruentialGenerator64(1<<32, 1664525, 1013904223, 0)
+	for i, expected := range []uint64{0x3c6ef35f, 0x47502932, 0xd1ccf6e9, 0xaaf95334} {
+		if got := nr.Uint64(); got != expected {
+			t.Errorf("numerical recipes: step %d: expected %#x got %#x", i, expected, got)
+		}
+	}
+
+	cases := []struct {
+		name                                              string
+		modulus, multiplier, additiveComponent, initValue uint64
+	}{
+		{"drand48", 1 << 48, 0x5deece66d, 0xb, 0x1234abcd330e},
+		{"mmix", 0, 6364136223846793005, 1442695040888963407, 1},
+		{"minstd", math.MaxInt32, 48271, 0, 1},
+		{"wide multiplier", 1<<61 - 1, 1<<60 + 12345, 98765, 42},
+	}
+
+	for _, c := range cases {
+		g := NewCongruentialGenerator64(c.modulus, c.multiplier, c.additiveComponent, c.initValue)
+
+		modulus := new(big.Int).SetUint64(c.modulus)
+		if c.modulus == 0 {
+			modulus.SetBit(modulus, 64, 1)
+		}
+
+		x := new(big.Int).SetUint64(c.initValue)
+		for i := 0; i < 1000; i += 1 {
+			x.Mul(x, new(big.Int).SetUint64(c.multiplier))
+			x.Add(x, new(big.Int).SetUint64(c.additiveComponent))
+			x.Mod(x, modulus)
+
+			if got := g.Uint64(); got != x.Uint64() {
+				t.Errorf("%s: step %d: expected %d got %d", c.name, i, x.Uint64(), got)
+				break
+			}
+		}
+	}
+
+	// the full 64-bit state is shifted to fit int
+	mmix := NewCongruentialGenerator64(0, 6364136223846793005, 1442695040888963407, 1)
+	reference := NewCongruentialGenerator64(0, 6364136223846793005, 1442695040888963407, 1)
+	if got := mmix.Int(); got < 0 || uint64(got) != reference.Uint64()>>1 {
+		t.Errorf("mmix: unexpected int %d", got)
+	}
+}
+
+func TestMRG32k3a(t *testing.T) {
+	g, err := NewMRG32k3aGenerator([6]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// p1 = 3023790853, p2 = 2478282264 for the default seed
+	if got := g.Int(); got != 545508589 {
+		t.Errorf("expected first value 545508589 got %d", got)
+	}
+
+	multiply := func(a, b mrgMatrix, modulus uint64) mrgMatrix {
+		var r mrgMatrix
+		for i := 0; i < 3; i += 1 {
+			for j := 0; j < 3; j += 1 {
+				for k := 0; k < 3; k += 1 {
+					r[i][j] = addMod(r[i][j], mulMod(a[i][k], b[k][j], modulus), modulus)
+				}
+			}
+		}
+
+		return r
+	}
+
+	power := func(a mrgMatrix, log2 int, modulus uint64) mrgMatrix {
+		for i := 0; i < log2; i += 1 {
+			a = multiply(a, a, modulus)
+		}
+
+		return a
+	}
+
+	if power(mrgA1, 76, mrgModulus1) != mrgA1p76 || power(mrgA2, 76, mrgModulus2) != mrgA2p76 {
+		t.Errorf("substream jump matrices don't match A^(2^76)")
+	}
+
+	if power(mrgA1, 127, mrgModulus1) != mrgA1p127 || power(mrgA2, 127, mrgModulus2) != mrgA2p127 {
+		t.Errorf("stream jump matrices don't match A^(2^127)")
+	}
+
+	// one step jump applied to the state must match the recurrence
+	h, _ := NewMRG32k3aGenerator([6]uint64{1, 2, 3, 4, 5, 6})
+	c1, c2 := mrgA1.apply(h.c1, mrgModulus1), mrgA2.apply(h.c2, mrgModulus2)
+	_ = h.Int()
+	if c1 != h.c1 || c2 != h.c2 {
+		t.Errorf("transition matrices don't match the recurrence")
+	}
+
+	streams, err := NewMRG32k3aStreams([6]uint64{12345, 12345, 12345, 12345, 12345, 12345}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if streams[0].Float64() == streams[1].Float64() {
+		t.Errorf("streams should differ")
+	}
+
+	if _, err := NewMRG32k3aGenerator([6]uint64{0, 0, 0, 1, 1, 1}); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func TestCombinedGenerators(t *testing.T) {
+	wh, err := NewWichmannHillGenerator(1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	le, err := NewLEcuyerGenerator(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, g := range []Float64Generator{wh, le, NewUniformGenerator(le, lEcuyerModulus1)} {
+		sum := 0.0
+		for i := 0; i < 100000; i += 1 {
+			u := g.Float64()
+			if u < 0 || u >= 1 {
+				t.Fatalf("%T: value %f is out of [0, 1)", g, u)
+			}
+			sum += u
+		}
+
+		if mean := sum / 100000; math.Abs(mean-0.5) > 0.01 {
+			t.Errorf("%T: unexpected mean %f", g, mean)
+		}
+	}
+
+	// states out of the ranges of the components are rejected
+	corrupt := []struct {
+		g     StatefulGenerator
+		state combinedState
+	}{
+		{&WichmannHillGenerator{}, combinedState{Name: WichmannHill, Seed: []uint64{1, 2, 3}, State: []uint64{1, 0, 3}}},
+		{&WichmannHillGenerator{}, combinedState{Name: WichmannHill, Seed: []uint64{1, 2, 3}, State: []uint64{1, 2, 30323}}},
+		{&LEcuyerGenerator{}, combinedState{Name: LEcuyer, Seed: []uint64{1, 2}, State: []uint64{lEcuyerModulus1, 2}}},
+		{&LEcuyerGenerator{}, combinedState{Name: LEcuyer, Seed: []uint64{0, 2}, State: []uint64{1, 2}}},
+		{&MRG32k3aGenerator{}, combinedState{Name: MRG32k3a, Seed: []uint64{1, 1, 1, 1, 1, 1}, State: append([]uint64{0, 0, 0}, make([]uint64, 15)...)}},
+		{&MRG32k3aGenerator{}, combinedState{Name: MRG32k3a, Seed: []uint64{1, 1, 1, 1, 1, mrgModulus2}, State: []uint64{
+			1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		}}},
+	}
+
+	for _, c := range corrupt {
+		state, _ := json.Marshal(c.state)
+		if err := c.g.UnmarshalBinary(state); errors.Cause(err) != ErrInvalidState {
+			t.Errorf("%s: expected invalid state error, got %v", state, err)
+		}
+	}
+}
+
+func TestLaggedGenerators(t *testing.T) {
+	// James's RANLUX test output, luxury level 3 with the default seed
+	ranlux, err := NewRanluxGenerator(3, 314159265)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []float64{0.53981817, 0.76155043, 0.06029940, 0.79600263, 0.30631220} {
+		if got := ranlux.Float64(); math.Abs(got-expected) > 5e-9 {
+			t.Errorf("ranlux: step %d: expected %.8f got %.8f", i, expected, got)
+		}
+	}
+
+	// level 0 is the plain subtract-with-carry generator
+	plain, _ := NewRanluxGenerator(0, 42)
+	swc, _ := NewSubtractWithCarryGenerator(24, 10, 24, 42)
+	for i := 0; i < 100; i += 1 {
+		if expected, got := swc.Uint32(), plain.Uint32(); expected != got {
+			t.Fatalf("ranlux level 0: step %d: expected %d got %d", i, expected, got)
+		}
+	}
+
+	// x_n = x_{n-5} + x_{n-17}
+	additive, err := NewLaggedFibonacciGenerator(AdditiveLaggedFibonacci, 5, 17, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := make([]uint32, 0, 1000)
+	for i := 0; i < 1000; i += 1 {
+		history = append(history, additive.Uint32())
+	}
+	for n := 17; n < len(history); n += 1 {
+		if history[n] != history[n-5]+history[n-17] {
+			t.Fatalf("additive: recurrence broken at %d", n)
+		}
+	}
+
+	multiplicative, err := NewLaggedFibonacciGenerator(MultiplicativeLaggedFibonacci, 24, 55, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i += 1 {
+		if multiplicative.Uint32()%2 != 1 {
+			t.Fatalf("multiplicative: even value at step %d", i)
+		}
+	}
+
+	if _, err := NewLaggedFibonacciGenerator(AdditiveLaggedFibonacci, 17, 5, 42); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	state, err := additive.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := []byte(strings.Replace(string(state), `"additive"`, `"subtractive"`, 1))
+	if err := (&LaggedFibonacciGenerator{}).UnmarshalBinary(corrupt); errors.Cause(err) != ErrInvalidState {
+		t.Errorf("expected invalid state error, got %v", err)
+	}
+}
+
+func TestCounterGenerators(t *testing.T) {
+	// Random123 known answer vectors
+	philoxCases := []struct {
+		ctr      [4]uint32
+		key      [2]uint32
+		expected [4]uint32
+	}{
+		{[4]uint32{}, [2]uint32{}, [4]uint32{0x6627e8d5, 0xe169c58d, 0xbc57ac4c, 0x9b00dbd8}},
+		{
+			[4]uint32{0xffffffff, 0xffffffff, 0xffffffff, 0xffffffff},
+			[2]uint32{0xffffffff, 0xffffffff},
+			[4]uint32{0x408f276d, 0x41c83b0e, 0xa20bc7c6, 0x6d5451fd},
+		},
+	}
+	for _, c := range philoxCases {
+		if got := philox4x32(c.ctr, c.key); got != c.expected {
+			t.Errorf("philox4x32(%x, %x): expected %x got %x", c.ctr, c.key, c.expected, got)
+		}
+	}
+
+	expected := [4]uint64{0x09218ebde6c85537, 0x55941f5266d86105, 0x4bd25e16282434dc, 0xee29ec846bd2e40b}
+	if got := threefry4x64([4]uint64{}, [4]uint64{}); got != expected {
+		t.Errorf("threefry4x64(0, 0): expected %x got %x", expected, got)
+	}
+
+	// the stream doesn't depend on the order it is consumed in
+	sequential := NewPhiloxGenerator(42, 7)
+	values := make([]uint32, 0, 40)
+	for i := 0; i < 40; i += 1 {
+		values = append(values, sequential.Uint32())
+	}
+
+	random := NewPhiloxGenerator(42, 7)
+	for block := 9; block >= 0; block -= 1 {
+		random.SetCounter(uint64(block))
+		for i := 0; i < 4; i += 1 {
+			if got := random.Uint32(); got != values[block*4+i] {
+				t.Fatalf("philox: block %d word %d: expected %d got %d", block, i, values[block*4+i], got)
+			}
+		}
+	}
+
+	threefry := NewThreefryGenerator(42, 7)
+	threefry.Uint64()
+	if threefry.Counter() != 0 {
+		t.Errorf("threefry: expected counter 0, got %d", threefry.Counter())
+	}
+
+	if a, b := NewThreefryGenerator(42, 7).Uint64(), NewThreefryGenerator(42, 8).Uint64(); a == b {
+		t.Error("threefry: streams 7 and 8 start with the same value")
+	}
+}
+
+// newTestUniform is the uniform fixture of the distribution tests, the
+// sequence selects an independent PCG32 stream
+func newTestUniform(sequence uint64) *UniformGenerator {
+	return NewUniformGenerator(NewPCG32Generator(42, sequence), math.MaxUint32+1)
+}
+
+func TestMultivariateNormalGenerator(t *testing.T) {
+	newNormal := func() *NormalGenerator {
+		return NewNormalGenerator(newTestUniform(1), newTestUniform(2), 1, 0)
+	}
+
+	cases := []struct {
+		name       string
+		mean       []float64
+		covariance [][]float64
+	}{
+		{
+			name:       "positive definite",
+			mean:       []float64{1, -2, 0.5},
+			covariance: [][]float64{{4, 1.2, -0.8}, {1.2, 1, 0.3}, {-0.8, 0.3, 2}},
+		},
+		{
+			// the third component is the sum of the first two
+			name:       "semi-definite",
+			mean:       []float64{0, 0, 0},
+			covariance: [][]float64{{1, 0.5, 1.5}, {0.5, 1, 1.5}, {1.5, 1.5, 3}},
+		},
+	}
+
+	const samples = 200000
+
+	for _, c := range cases {
+		mg, err := NewMultivariateNormalGenerator(newNormal(), c.mean, c.covariance)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		n := len(c.mean)
+		sums := make([]float64, n)
+		products := make([][]float64, n)
+		for i := range products {
+			products[i] = make([]float64, n)
+		}
+
+		for k := 0; k < samples; k += 1 {
+			x := mg.MultivariateNormFloat64s()
+			for i := range x {
+				sums[i] += x[i]
+				for j := range x {
+					products[i][j] += (x[i] - c.mean[i]) * (x[j] - c.mean[j])
+				}
+			}
+		}
+
+		for i := 0; i < n; i += 1 {
+			if mean := sums[i] / samples; math.Abs(mean-c.mean[i]) > 0.02 {
+				t.Errorf("%s: mean %d: expected %f got %f", c.name, i, c.mean[i], mean)
+			}
+
+			for j := 0; j < n; j += 1 {
+				if cov := products[i][j] / samples; math.Abs(cov-c.covariance[i][j]) > 0.05 {
+					t.Errorf("%s: covariance (%d, %d): expected %f got %f", c.name, i, j, c.covariance[i][j], cov)
+				}
+			}
+		}
+	}
+
+	invalid := [][][]float64{
+		{{1, 0}},
+		{{1, 0.5}, {0.4, 1}},
+		{{1, 2}, {2, 1}},
+		{{-1, 0}, {0, 1}},
+	}
+	for _, covariance := range invalid {
+		if _, err := NewMultivariateNormalGenerator(newNormal(), []float64{0, 0}, covariance); errors.Cause(err) != ErrInvalidParameters {
+			t.Errorf("%v: expected invalid parameters error, got %v", covariance, err)
+		}
+	}
+}
+
+func TestTwoDimensionalMethods(t *testing.T) {
+	const (
+		samples     = 400000
+		r           = 0.7
+		tail        = 3.5
+		stdDevX     = 2.0
+		stdDevY     = 0.5
+		meanX       = 1.0
+		meanY       = -1.0
+		cltBoundary = 3 * math.Sqrt2
+	)
+
+	// P(|Z| > 3.5) for the standard normal distribution
+	normalTail := math.Erfc(tail / math.Sqrt2)
+
+	for _, method := range []TwoDimensionalMethod{CentralLimitMethod, PolarMethod} {
+		g := NewUniformGenerator(NewPCG32Generator(42, 1), math.MaxUint32+1)
+		g2 := NewUniformGenerator(NewPCG32Generator(42, 2), math.MaxUint32+1)
+		tdg := NewTwoDimensionalGenerator(g, g2, stdDevX, stdDevY, meanX, meanY, r).WithMethod(method)
+
+		var sx, sy, sxx, syy, sxy, maxZ float64
+		tails := 0
+
+		for i := 0; i < samples; i += 1 {
+			p := tdg.TwoDimensionalFloat64s()
+			zx, zy := (p.x-meanX)/stdDevX, (p.y-meanY)/stdDevY
+
+			sx += zx
+			sy += zy
+			sxx += zx * zx
+			syy += zy * zy
+			sxy += zx * zy
+			maxZ = math.Max(maxZ, math.Abs(zx))
+
+			if math.Abs(zx) > tail {
+				tails += 1
+			}
+		}
+
+		n := float64(samples)
+		correlation := (sxy - sx*sy/n) / math.Sqrt((sxx-sx*sx/n)*(syy-sy*sy/n))
+		if math.Abs(correlation-r) > 0.01 {
+			t.Errorf("%s: expected correlation %f got %f", method, r, correlation)
+		}
+
+		tailProbability := float64(tails) / n
+
+		switch method {
+		case CentralLimitMethod:
+			if maxZ > cltBoundary {
+				t.Errorf("%s: value %f beyond the ±3·sqrt2 boundary", method, maxZ)
+			}
+
+			// the approximation underestimates the tail several times
+			if tailProbability > normalTail/3 {
+				t.Errorf("%s: tail probability %g is not truncated (normal %g)", method, tailProbability, normalTail)
+			}
+		case PolarMethod:
+			// binomial standard deviation is about 7% of the expected count
+			if math.Abs(tailProbability-normalTail) > 0.25*normalTail {
+				t.Errorf("%s: expected tail probability %g got %g", method, normalTail, tailProbability)
+			}
+		}
+	}
+}
+
+func TestCopulaGenerators(t *testing.T) {
+	// Frank's τ = 1 - 4/θ (1 - D_1(θ)) with the Debye function D_1(θ) = 1/θ ∫_0^θ t/(e^t - 1) dt
+	frankTau := func(theta float64) float64 {
+		const steps = 100000
+
+		integral := 0.0
+		for i := 0; i < steps; i += 1 {
+			x := (float64(i) + 0.5) * theta / steps
+			integral += x / math.Expm1(x) * theta / steps
+		}
+
+		return 1 - 4/theta*(1-integral/theta)
+	}
+
+	x := NewExponentialGenerator(newTestUniform(3), 0.5)
+	y := NewNormalGenerator(newTestUniform(4), newTestUniform(5), 1, 0)
+
+	cases := []struct {
+		name string
+		new  func() (*CopulaGenerator, error)
+		tau  float64
+	}{
+		{
+			name: "gaussian",
+			new: func() (*CopulaGenerator, error) {
+				return NewGaussianCopulaGenerator(newTestUniform(1), newTestUniform(2), x, y, 0.6)
+			},
+			tau: 2 / math.Pi * math.Asin(0.6),
+		},
+		{
+			name: "student-t",
+			new: func() (*CopulaGenerator, error) {
+				return NewStudentTCopulaGenerator(newTestUniform(1), newTestUniform(2), x, y, -0.4, 3)
+			},
+			tau: 2 / math.Pi * math.Asin(-0.4),
+		},
+		{
+			name: "clayton",
+			new: func() (*CopulaGenerator, error) {
+				return NewClaytonCopulaGenerator(newTestUniform(1), newTestUniform(2), x, y, 2)
+			},
+			tau: 2.0 / 4,
+		},
+		{
+			name: "gumbel",
+			new: func() (*CopulaGenerator, error) {
+				return NewGumbelCopulaGenerator(newTestUniform(1), newTestUniform(2), x, y, 3)
+			},
+			tau: 1 - 1.0/3,
+		},
+		{
+			name: "frank",
+			new: func() (*CopulaGenerator, error) {
+				return NewFrankCopulaGenerator(newTestUniform(1), newTestUniform(2), x, y, -5)
+			},
+			tau: frankTau(-5),
+		},
+	}
+
+	const samples = 3000
+
+	for _, c := range cases {
+		cg, err := c.new()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		pairs := make(FloatPairs, 0, samples)
+		meanX := 0.0
+		for i := 0; i < samples; i += 1 {
+			p := cg.TwoDimensionalFloat64s()
+			pairs = append(pairs, p)
+			meanX += p.x / samples
+		}
+
+		if math.Abs(meanX-2) > 0.15 {
+			t.Errorf("%s: expected exponential marginal mean 2, got %f", c.name, meanX)
+		}
+
+		// Kendall's τ is invariant under the monotone marginal transforms
+		concordant := 0.0
+		for i := range pairs {
+			for j := i + 1; j < len(pairs); j += 1 {
+				concordant += math.Copysign(1, (pairs[i].x-pairs[j].x)*(pairs[i].y-pairs[j].y))
+			}
+		}
+
+		if tau := concordant / (samples * (samples - 1) / 2); math.Abs(tau-c.tau) > 0.04 {
+			t.Errorf("%s: expected Kendall's τ %f got %f", c.name, c.tau, tau)
+		}
+	}
+
+	if _, err := NewClaytonCopulaGenerator(newTestUniform(1), newTestUniform(2), x, y, -1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	// a multiplicative generator seeded with 0 never leaves 0
+	stuck := NewUniformGenerator(NewCongruentialGenerator(16, 5, 0, 0), 16)
+	clayton, _ := NewClaytonCopulaGenerator(stuck, stuck, x, y, 2)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("clayton: expected panic for a source stuck at 0")
+			}
+		}()
+
+		clayton.TwoDimensionalFloat64s()
+	}()
+}
+
+func TestZigguratGenerators(t *testing.T) {
+	const samples = 1000000
+
+	normals := []struct {
+		name string
+		g    NormFloat64Generator
+	}{
+		{"polar", NewNormalGenerator(newTestUniform(1), newTestUniform(2), 1, 0)},
+		{"ziggurat", NewZigguratNormalGenerator(newTestUniform(1), 1, 0)},
+	}
+
+	points := []float64{-4, -3, -2, -1, -0.5, 0, 0.3, 1, 2, 3.5, 4}
+
+	for _, c := range normals {
+		below := make([]int, len(points))
+		for i := 0; i < samples; i += 1 {
+			x := c.g.NormFloat64()
+			for j, p := range points {
+				if x < p {
+					below[j] += 1
+				}
+			}
+		}
+
+		for j, p := range points {
+			expected := math.Erfc(-p/math.Sqrt2) / 2
+			got := float64(below[j]) / samples
+
+			// about 4 binomial standard deviations
+			if tolerance := 4*math.Sqrt(expected*(1-expected)/samples) + 1e-5; math.Abs(got-expected) > tolerance {
+				t.Errorf("%s: P(X < %.1f): expected %f got %f", c.name, p, expected, got)
+			}
+		}
+	}
+
+	exponentials := []struct {
+		name string
+		g    ExpFloat64Generator
+	}{
+		{"logarithm", NewExponentialGenerator(newTestUniform(3), 0.5)},
+		{"ziggurat", NewZigguratExponentialGenerator(newTestUniform(3), 0.5)},
+	}
+
+	points = []float64{0.01, 0.5, 1, 2, 5, 10, 16, 20}
+
+	for _, c := range exponentials {
+		below := make([]int, len(points))
+		for i := 0; i < samples; i += 1 {
+			x := c.g.ExpFloat64()
+			for j, p := range points {
+				if x < p {
+					below[j] += 1
+				}
+			}
+		}
+
+		for j, p := range points {
+			expected := -math.Expm1(-p / 2)
+			got := float64(below[j]) / samples
+
+			if tolerance := 4*math.Sqrt(expected*(1-expected)/samples) + 1e-5; math.Abs(got-expected) > tolerance {
+				t.Errorf("%s: P(X < %.2f): expected %f got %f", c.name, p, expected, got)
+			}
+		}
+	}
+}
+
+// newTestNormal is the standard normal fixture drawing from the stream of
+// newTestUniform(sequence)
+func newTestNormal(sequence uint64) *ZigguratNormalGenerator {
+	return NewZigguratNormalGenerator(newTestUniform(sequence), 1, 0)
+}
+
+// checkMoments compares the sample moments of next with the expected ones,
+// the mean within meanTolerance and the variance within 3%
+func checkMoments(t *testing.T, name string, next func() float64, samples int, mean float64, meanTolerance float64, variance float64) {
+	t.Helper()
+
+	sum, squares := 0.0, 0.0
+	for i := 0; i < samples; i += 1 {
+		x := next()
+		sum += x
+		squares += x * x
+	}
+
+	sampleMean := sum / float64(samples)
+	sampleVariance := squares/float64(samples) - sampleMean*sampleMean
+
+	if math.Abs(sampleMean-mean) > meanTolerance {
+		t.Errorf("%s: expected mean %f got %f", name, mean, sampleMean)
+	}
+
+	if math.Abs(sampleVariance-variance) > 0.03*variance {
+		t.Errorf("%s: expected variance %f got %f", name, variance, sampleVariance)
+	}
+}
+
+func TestGammaFamilyGenerators(t *testing.T) {
+	const samples = 400000
+
+	gamma, _ := NewGammaGenerator(newTestUniform(1), newTestNormal(2), 2.5, 3)
+	smallGamma, _ := NewGammaGenerator(newTestUniform(1), newTestNormal(2), 0.3, 1)
+	erlang, _ := NewErlangGenerator(newTestUniform(3), 20, 0.5)
+	chiSquared, _ := NewChiSquaredGenerator(newTestUniform(4), newTestNormal(5), 7)
+	beta, _ := NewBetaGenerator(newTestUniform(6), newTestNormal(7), 0.5, 2)
+
+	cases := []struct {
+		name     string
+		next     func() float64
+		mean     float64
+		variance float64
+	}{
+		{"gamma", gamma.GammaFloat64, 2.5 * 3, 2.5 * 9},
+		{"gamma shape < 1", smallGamma.GammaFloat64, 0.3, 0.3},
+		{"erlang", erlang.ErlangFloat64, 20 * 0.5, 20 * 0.25},
+		{"chi-squared", chiSquared.ChiSquaredFloat64, 7, 14},
+		{"beta", beta.BetaFloat64, 0.5 / 2.5, 0.5 * 2 / (2.5 * 2.5 * 3.5)},
+	}
+
+	for _, c := range cases {
+		checkMoments(t, c.name, c.next, samples, c.mean, 0.01*c.mean, c.variance)
+	}
+
+	concentration := []float64{0.5, 1, 3.5}
+	dirichlet, _ := NewDirichletGenerator(newTestUniform(8), newTestNormal(9), concentration)
+
+	means := make([]float64, len(concentration))
+	for i := 0; i < samples; i += 1 {
+		sum := 0.0
+		for j, x := range dirichlet.DirichletFloat64s() {
+			sum += x
+			means[j] += x / samples
+		}
+
+		if math.Abs(sum-1) > 1e-12 {
+			t.Fatalf("dirichlet: components sum to %f", sum)
+		}
+	}
+
+	for j, a := range concentration {
+		if expected := a / 5; math.Abs(means[j]-expected) > 0.005 {
+			t.Errorf("dirichlet: component %d: expected mean %f got %f", j, expected, means[j])
+		}
+	}
+
+	// both gammas of tiny shapes underflow to 0, the values must still be
+	// defined and split between the ends of [0, 1]
+	tinyBeta, _ := NewBetaGenerator(newTestUniform(10), newTestNormal(11), 0.001, 0.001)
+	tinyDirichlet, _ := NewDirichletGenerator(newTestUniform(12), newTestNormal(13), []float64{0.001, 0.001, 0.001})
+
+	sum := 0.0
+	for i := 0; i < 100000; i += 1 {
+		x := tinyBeta.BetaFloat64()
+		if math.IsNaN(x) || x < 0 || x > 1 {
+			t.Fatalf("beta with tiny shapes: got %v", x)
+		}
+		sum += x
+
+		total := 0.0
+		for _, y := range tinyDirichlet.DirichletFloat64s() {
+			if math.IsNaN(y) || y < 0 || y > 1 {
+				t.Fatalf("dirichlet with tiny concentrations: got %v", y)
+			}
+			total += y
+		}
+
+		if math.Abs(total-1) > 1e-12 {
+			t.Fatalf("dirichlet with tiny concentrations: components sum to %f", total)
+		}
+	}
+
+	if mean := sum / 100000; math.Abs(mean-0.5) > 0.01 {
+		t.Errorf("beta with tiny shapes: expected mean 0.5 got %f", mean)
+	}
+
+	if _, err := NewGammaGenerator(newTestUniform(1), newTestNormal(2), 0, 1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	if _, err := NewErlangGenerator(newTestUniform(1), 0, 1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	// the normal source draws from the uniform source, a restored copy would
+	// draw from an independent one
+	ug := newTestUniform(1)
+	shared, _ := NewGammaGenerator(ug, NewZigguratNormalGenerator(ug, 1, 0), 0.5, 1)
+	if _, err := shared.MarshalBinary(); errors.Cause(err) != ErrStateUnsupported {
+		t.Errorf("expected state unsupported error, got %v", err)
+	}
+}
+
+func TestHeavyTailedGenerators(t *testing.T) {
+	const samples = 200000
+
+	pareto, _ := NewParetoGenerator(newTestUniform(1), 2, 1.5)
+	lomax, _ := NewLomaxGenerator(newTestUniform(2), 3, 0.8)
+	cauchy, _ := NewCauchyGenerator(newTestUniform(3), -1, 2)
+	studentT, _ := NewStudentTGenerator(newTestUniform(4), 2.5, 1, 3)
+	logNormal, _ := NewLogNormalGenerator(newTestUniform(5), 0.5, 1.2)
+	weibull, _ := NewWeibullGenerator(newTestUniform(6), 2, 0.6)
+
+	cases := []struct {
+		name string
+		next func() float64
+		q    QuantileGenerator
+	}{
+		{"pareto", pareto.ParetoFloat64, pareto},
+		{"lomax", lomax.LomaxFloat64, lomax},
+		{"cauchy", cauchy.CauchyFloat64, cauchy},
+		{"student-t", studentT.StudentTFloat64, studentT},
+		{"log-normal", logNormal.LogNormalFloat64, logNormal},
+		{"weibull", weibull.WeibullFloat64, weibull},
+	}
+
+	probabilities := []float64{0.01, 0.1, 0.5, 0.9, 0.99}
+
+	for _, c := range cases {
+		quantiles := make([]float64, len(probabilities))
+		for i, p := range probabilities {
+			quantiles[i] = c.q.Quantile(p)
+		}
+
+		below := make([]float64, len(probabilities))
+		for i := 0; i < samples; i += 1 {
+			x := c.next()
+			for j, q := range quantiles {
+				if x <= q {
+					below[j] += 1.0 / samples
+				}
+			}
+		}
+
+		for j, p := range probabilities {
+			if math.Abs(below[j]-p) > 0.004 {
+				t.Errorf("%s: expected P(X <= %f) = %f got %f", c.name, quantiles[j], p, below[j])
+			}
+		}
+	}
+
+	// α-stable special cases with known distribution functions
+	normal, _ := NewStableGenerator(newTestUniform(7), 2, 0, 1.5, 1)
+	symmetric, _ := NewStableGenerator(newTestUniform(8), 1, 0, 2, -1)
+	levy, _ := NewStableGenerator(newTestUniform(9), 0.5, 1, 2, 0)
+
+	stable := []struct {
+		name string
+		next func() float64
+		cdf  func(x float64) float64
+	}{
+		{"stable α = 2", normal.StableFloat64, func(x float64) float64 {
+			return 0.5 * math.Erfc(-(x-1)/(2*1.5))
+		}},
+		{"stable α = 1", symmetric.StableFloat64, func(x float64) float64 {
+			return 0.5 + math.Atan((x+1)/2)/math.Pi
+		}},
+		{"stable α = 1/2, β = 1", levy.StableFloat64, func(x float64) float64 {
+			if x <= 0 {
+				return 0
+			}
+			return math.Erfc(math.Sqrt(2 / (2 * x)))
+		}},
+	}
+
+	points := []float64{-3, -1, 0.5, 1, 2, 5, 20}
+
+	for _, c := range stable {
+		below := make([]float64, len(points))
+		for i := 0; i < samples; i += 1 {
+			x := c.next()
+			for j, p := range points {
+				if x <= p {
+					below[j] += 1.0 / samples
+				}
+			}
+		}
+
+		for j, p := range points {
+			if expected := c.cdf(p); math.Abs(below[j]-expected) > 0.004 {
+				t.Errorf("%s: expected P(X <= %f) = %f got %f", c.name, p, expected, below[j])
+			}
+		}
+	}
+
+	if _, err := NewStableGenerator(newTestUniform(1), 2.5, 0, 1, 0); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	if _, err := NewParetoGenerator(newTestUniform(1), 1, -1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func TestDiscreteGenerators(t *testing.T) {
+	const samples = 400000
+
+	smallPoisson, _ := NewPoissonGenerator(newTestUniform(1), 3.5)
+	poisson, _ := NewPoissonGenerator(newTestUniform(2), 250)
+	smallBinomial, _ := NewBinomialGenerator(newTestUniform(3), 40, 0.2)
+	binomial, _ := NewBinomialGenerator(newTestUniform(4), 5000, 0.7)
+	geometric, _ := NewGeometricGenerator(newTestUniform(5), 0.15)
+	negativeBinomial, _ := NewNegativeBinomialGenerator(newTestUniform(6), NewZigguratNormalGenerator(newTestUniform(7), 1, 0), 3.5, 0.25)
+	hypergeometric, _ := NewHypergeometricGenerator(newTestUniform(8), 5000, 1200, 800)
+	discreteUniform, _ := NewDiscreteUniformGenerator(NewPCG32Generator(42, 9), math.MaxUint32+1, -10, 30)
+	discreteUniformBits, _ := NewDiscreteUniformBitGenerator(NewBitGeneratorWithBits(NewPCG64Generator(42, 10), 63), -10, 30)
+
+	cases := []struct {
+		name     string
+		next     func() int
+		mean     float64
+		variance float64
+	}{
+		{"poisson λ < 10", smallPoisson.PoissonInt, 3.5, 3.5},
+		{"poisson", poisson.PoissonInt, 250, 250},
+		{"binomial np < 30", smallBinomial.BinomialInt, 8, 6.4},
+		{"binomial", binomial.BinomialInt, 3500, 1050},
+		{"geometric", geometric.GeometricInt, 1 / 0.15, 0.85 / (0.15 * 0.15)},
+		{"negative binomial", negativeBinomial.NegativeBinomialInt, 3.5 * 3, 3.5 * 3 / 0.25},
+		{"hypergeometric", hypergeometric.HypergeometricInt, 800 * 0.24, 800 * 0.24 * 0.76 * 4200 / 4999},
+		{"discrete uniform", discreteUniform.DiscreteUniformInt, 10, (41*41 - 1) / 12.0},
+		{"discrete uniform bits", discreteUniformBits.DiscreteUniformInt, 10, (41*41 - 1) / 12.0},
+	}
+
+	for _, c := range cases {
+		next := c.next
+		checkMoments(t, c.name, func() float64 { return float64(next()) }, samples, c.mean, 0.01*c.mean, c.variance)
+	}
+
+	// the BTPE acceptance regions must reproduce the probability mass function
+	frequencies := make(map[int]float64)
+	for i := 0; i < samples; i += 1 {
+		frequencies[binomial.BinomialInt()] += 1.0 / samples
+	}
+
+	for _, k := range []int{3440, 3470, 3500, 3530, 3560} {
+		expected := math.Exp(logChoose(5000, float64(k)) + float64(k)*math.Log(0.7) + float64(5000-k)*math.Log(0.3))
+		if math.Abs(frequencies[k]-expected) > 0.15*expected {
+			t.Errorf("binomial: expected P(X = %d) = %f got %f", k, expected, frequencies[k])
+		}
+	}
+
+	// a source with 3 values reduced to 2 values, the modulo would give 2/3 and 1/3
+	counter := 0
+	threeValues := NewCongruentialGenerator(3, 1, 1, 0)
+	coin, _ := NewDiscreteUniformGenerator(threeValues, 3, 0, 1)
+	for i := 0; i < 3000; i += 1 {
+		counter += coin.DiscreteUniformInt()
+	}
+
+	if counter != 1500 {
+		t.Errorf("discrete uniform: expected 1500 ones in 3000 values got %d", counter)
+	}
+
+	if _, err := NewDiscreteUniformGenerator(threeValues, 3, math.MinInt64, math.MaxInt64); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	// the default source has 64 bits for every value, the whole int range is
+	// drawn without rejections
+	whole, err := NewDiscreteUniformGeneratorDefault(math.MinInt64, math.MaxInt64)
+	if err != nil {
+		t.Fatalf("discrete uniform: can't cover the int range: %s", err)
+	}
+
+	negative := 0
+	for i := 0; i < 10000; i += 1 {
+		if whole.DiscreteUniformInt() < 0 {
+			negative += 1
+		}
+	}
+
+	if negative < 4800 || negative > 5200 {
+		t.Errorf("discrete uniform: expected about 5000 negative values in 10000 got %d", negative)
+	}
+
+	wide, _ := NewDiscreteUniformGeneratorDefault(0, math.MaxInt64)
+	if wide.bits == nil || wide.bits.Bits() != 63 {
+		t.Errorf("discrete uniform: expected the default generator to draw from a bit generator")
+	}
+
+	if _, err := NewBinomialGenerator(newTestUniform(1), 10, 1.5); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	if _, err := NewHypergeometricGenerator(newTestUniform(1), 10, 11, 5); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	// the normal source draws from the uniform source, a restored copy would
+	// draw from an independent one
+	ug := newTestUniform(1)
+	shared, _ := NewNegativeBinomialGenerator(ug, NewZigguratNormalGenerator(ug, 1, 0), 3.5, 0.25)
+	if _, err := shared.MarshalBinary(); errors.Cause(err) != ErrStateUnsupported {
+		t.Errorf("expected state unsupported error, got %v", err)
+	}
+}
+
+func TestSamplers(t *testing.T) {
+	const samples = 400000
+
+	// the exponential distribution with rate 2
+	inverse, err := NewInverseTransformGenerator(newTestUniform(1), func(p float64) float64 {
+		return -math.Log1p(-p) / 2
+	})
+	if err != nil {
+		t.Fatalf("can't build inverse transform generator: %s", err)
+	}
+	inverse.WithLabel("exponential")
+
+	// the density 6x(1 - x) on [0, 1] under 1.5 times the uniform density
+	proposal := newTestUniform(2)
+	acceptance, err := NewAcceptanceRejectionGenerator(
+		newTestUniform(3),
+		func(x float64) float64 { return 6 * x * (1 - x) },
+		NewSampler(proposal, proposal.Float64),
+		func(x float64) float64 { return 1 },
+		1.5,
+	)
+	if err != nil {
+		t.Fatalf("can't build acceptance-rejection generator: %s", err)
+	}
+
+	cases := []struct {
+		name     string
+		next     func() float64
+		mean     float64
+		variance float64
+	}{
+		{"inverse transform", inverse.Sample, 0.5, 0.25},
+		{"acceptance-rejection", acceptance.Sample, 0.5, 0.05},
+	}
+
+	for _, c := range cases {
+		checkMoments(t, c.name, c.next, samples, c.mean, 0.01*c.mean, c.variance)
+	}
+
+	if rate := acceptance.AcceptanceRate(); math.Abs(rate-1/1.5) > 0.005 {
+		t.Errorf("acceptance-rejection: expected acceptance rate %f got %f", 1/1.5, rate)
+	}
+
+	if v := acceptance.EnvelopeViolations(); v != 0 {
+		t.Errorf("acceptance-rejection: expected no envelope violations got %d", v)
+	}
+
+	expected := `{"distributionName":"acceptance-rejection","envelope":1.5,` +
+		`"proposal":{"distributionName":"uniform","modulus":4294967296,"source":{"distributionName":"pcg32","seed":42,"sequence":2}},` +
+		`"source":{"distributionName":"uniform","modulus":4294967296,"source":{"distributionName":"pcg32","seed":42,"sequence":3}}}`
+	if s := acceptance.String(); s != expected {
+		t.Errorf("acceptance-rejection: expected %s got %s", expected, s)
+	}
+
+	// an envelope below the target is reported
+	low, _ := NewAcceptanceRejectionGenerator(
+		newTestUniform(4),
+		func(x float64) float64 { return 6 * x * (1 - x) },
+		NewSampler(proposal, proposal.Float64),
+		func(x float64) float64 { return 1 },
+		1.2,
+	)
+	for i := 0; i < 1000; i += 1 {
+		_ = low.Sample()
+	}
+
+	if low.EnvelopeViolations() == 0 {
+		t.Errorf("acceptance-rejection: expected envelope violations")
+	}
+
+	// the state restores the sources of a generator built with the same functions
+	state, err := acceptance.MarshalBinary()
+	if err != nil {
+		t.Fatalf("can't marshal state: %s", err)
+	}
+
+	restoredProposal := newTestUniform(5)
+	restored, _ := NewAcceptanceRejectionGenerator(
+		newTestUniform(6),
+		func(x float64) float64 { return 6 * x * (1 - x) },
+		NewSampler(restoredProposal, restoredProposal.Float64),
+		func(x float64) float64 { return 1 },
+		1,
+	)
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatalf("can't unmarshal state: %s", err)
+	}
+
+	for i := 0; i < 1000; i += 1 {
+		if expected, got := acceptance.Sample(), restored.Sample(); expected != got {
+			t.Fatalf("acceptance-rejection: step %d: expected %f got %f", i, expected, got)
+		}
+	}
+
+	if err := (&InverseTransformGenerator{}).UnmarshalBinary(state); errors.Cause(err) != ErrStateUnsupported {
+		t.Errorf("expected state unsupported error, got %v", err)
+	}
+
+	if _, err := NewInverseTransformGenerator(newTestUniform(7), nil); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func TestTruncatedGenerators(t *testing.T) {
+	const samples = 200000
+
+	type truncated interface {
+		TruncatedFloat64() float64
+		CDF(x float64) float64
+		Bounds() (float64, float64)
+	}
+
+	mustTruncate := func(g truncated, err error) truncated {
+		if err != nil {
+			t.Fatalf("can't build truncated generator: %s", err)
+		}
+
+		return g
+	}
+
+	cauchy, _ := NewCauchyGenerator(newTestUniform(10), 1, 2)
+
+	cases := []struct {
+		name string
+		g    truncated
+	}{
+		{"normal around the mean", mustTruncate(NewTruncatedNormalGenerator(newTestUniform(1), 2, 1, 0, 3))},
+		{"normal wide", mustTruncate(NewTruncatedNormalGenerator(newTestUniform(2), 1, 0, -1, math.Inf(1)))},
+		{"normal half", mustTruncate(NewTruncatedNormalGenerator(newTestUniform(3), 1, 0, 0, math.Inf(1)))},
+		{"normal tail", mustTruncate(NewTruncatedNormalGenerator(newTestUniform(4), 1, 0, 2, 2.5))},
+		{"normal far tail", mustTruncate(NewTruncatedNormalGenerator(newTestUniform(5), 1, 0, 40, math.Inf(1)))},
+		{"normal left tail", mustTruncate(NewTruncatedNormalGenerator(newTestUniform(6), 3, 2, math.Inf(-1), -25))},
+		{"exponential", mustTruncate(NewTruncatedExponentialGenerator(newTestUniform(7), 2, 1, 4))},
+		{"exponential tail", mustTruncate(NewTruncatedExponentialGenerator(newTestUniform(8), 0.5, 300, math.Inf(1)))},
+		{"cauchy", mustTruncate(NewTruncatedGenerator(newTestUniform(9), cauchy, -3, 5))},
+	}
+
+	for _, c := range cases {
+		values := make([]float64, samples)
+		a, b := c.g.Bounds()
+
+		for i := range values {
+			values[i] = c.g.TruncatedFloat64()
+
+			if values[i] < a || values[i] > b {
+				t.Fatalf("%s: value %f is out of [%f, %f]", c.name, values[i], a, b)
+			}
+		}
+
+		sort.Float64s(values)
+
+		// the empirical distribution function at the deciles
+		for _, p := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+			x := values[int(p*samples)]
+			if got := c.g.CDF(x); math.Abs(got-p) > 0.005 {
+				t.Errorf("%s: expected CDF(%f) = %f got %f", c.name, x, p, got)
+			}
+		}
+	}
+
+	if _, err := NewTruncatedNormalGenerator(newTestUniform(1), 1, 0, 2, 1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	if _, err := NewTruncatedGenerator(newTestUniform(1), NewExponentialGenerator(newTestUniform(2), 1), -3, -1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+
+	// the source yields 0 first, the quantile of the lower bound is -Inf
+	zero := NewUniformGenerator(NewCongruentialGenerator(16, 5, 1, 3), 16)
+	left, _ := NewTruncatedGenerator(zero, NewNormalGeneratorDefault(), math.Inf(-1), 0)
+	for i := 0; i < 16; i += 1 {
+		if x := left.TruncatedFloat64(); math.IsInf(x, 0) || x > 0 {
+			t.Fatalf("normal truncated to (-Inf, 0]: value %d: got %v", i, x)
+		}
+	}
+}
+
+func TestMixtureGenerators(t *testing.T) {
+	const samples = 400000
+
+	fast := NewZigguratNormalGenerator(newTestUniform(1), 1, 10)
+	slow := NewZigguratNormalGenerator(newTestUniform(2), 5, 100)
+	timeout := NewExponentialGenerator(newTestUniform(3), 0.5)
+
+	mixture, err := NewMixtureGenerator(newTestUniform(4), []Sampler{
+		NewSampler(fast, fast.NormFloat64),
+		NewSampler(slow, slow.NormFloat64),
+		NewSampler(timeout, timeout.ExpFloat64),
+	}, []float64{6, 3, 1})
+	if err != nil {
+		t.Fatalf("can't build mixture generator: %s", err)
+	}
+
+	claims := NewExponentialGenerator(newTestUniform(5), 0.5)
+	compound, err := NewCompoundPoissonGenerator(newTestUniform(6), 4, NewSampler(claims, claims.ExpFloat64))
+	if err != nil {
+		t.Fatalf("can't build compound generator: %s", err)
+	}
+
+	counts := make([]float64, mixture.Components())
+	sum, squares := 0.0, 0.0
+	compoundSum, compoundSquares := 0.0, 0.0
+
+	for i := 0; i < samples; i += 1 {
+		c, x := mixture.SampleComponent()
+		counts[c] += 1.0 / samples
+		sum += x
+		squares += x * x
+
+		y := compound.Sample()
+		compoundSum += y
+		compoundSquares += y * y
+	}
+
+	for i, w := range []float64{0.6, 0.3, 0.1} {
+		if math.Abs(counts[i]-w) > 0.003 {
+			t.Errorf("mixture: expected component %d share %f got %f", i, w, counts[i])
+		}
+	}
+
+	// the mixture moments are the weighted moments of the components
+	expectedMean := 0.6*10 + 0.3*100 + 0.1*2
+	expectedSquares := 0.6*(1+100) + 0.3*(25+10000) + 0.1*8
+	if mean := sum / samples; math.Abs(mean-expectedMean) > 0.01*expectedMean {
+		t.Errorf("mixture: expected mean %f got %f", expectedMean, mean)
+	}
+
+	expectedVariance := expectedSquares - expectedMean*expectedMean
+	if variance := squares/samples - sum*sum/(samples*samples); math.Abs(variance-expectedVariance) > 0.03*expectedVariance {
+		t.Errorf("mixture: expected variance %f got %f", expectedVariance, variance)
+	}
+
+	// E[S] = λ E[X], Var[S] = λ E[X²]
+	if mean := compoundSum / samples; math.Abs(mean-8) > 0.01*8 {
+		t.Errorf("compound: expected mean %f got %f", 8.0, mean)
+	}
+
+	if variance := compoundSquares/samples - compoundSum*compoundSum/(samples*samples); math.Abs(variance-32) > 0.03*32 {
+		t.Errorf("compound: expected variance %f got %f", 32.0, variance)
+	}
+
+	expected := `{"count":{"distributionName":"poisson","lambda":4,` +
+		`"source":{"distributionName":"uniform","modulus":4294967296,"source":{"distributionName":"pcg32","seed":42,"sequence":6}}},` +
+		`"distributionName":"compound","summand":{"distributionName":"exponential","rate":0.5,` +
+		`"source":{"distributionName":"uniform","modulus":4294967296,"source":{"distributionName":"pcg32","seed":42,"sequence":5}}}}`
+	if s := compound.String(); s != expected {
+		t.Errorf("compound: expected %s got %s", expected, s)
+	}
+
+	// the state is restored into a mixture of the same structure
+	state, err := mixture.MarshalBinary()
+	if err != nil {
+		t.Fatalf("can't marshal state: %s", err)
+	}
+
+	fast2 := NewZigguratNormalGenerator(newTestUniform(7), 1, 0)
+	slow2 := NewZigguratNormalGenerator(newTestUniform(8), 1, 0)
+	timeout2 := NewExponentialGenerator(newTestUniform(9), 1)
+	restored, _ := NewMixtureGenerator(newTestUniform(10), []Sampler{
+		NewSampler(fast2, fast2.NormFloat64),
+		NewSampler(slow2, slow2.NormFloat64),
+		NewSampler(timeout2, timeout2.ExpFloat64),
+	}, []float64{1, 1, 1})
+
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatalf("can't unmarshal state: %s", err)
+	}
+
+	for i := 0; i < 1000; i += 1 {
+		if expected, got := mixture.Sample(), restored.Sample(); expected != got {
+			t.Fatalf("mixture: step %d: expected %f got %f", i, expected, got)
+		}
+	}
+
+	if _, err := NewMixtureGenerator(newTestUniform(1), []Sampler{NewSampler(fast, fast.NormFloat64)}, []float64{-1}); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("expected invalid parameters error, got %v", err)
+	}
+}
+
+func TestDistributionLaws(t *testing.T) {
+	const samples = 400000
+
+	gamma, _ := NewGammaGenerator(newTestUniform(5), newTestNormal(6), 2.5, 3)
+	beta, _ := NewBetaGenerator(newTestUniform(7), newTestNormal(8), 0.5, 2)
+	studentT, _ := NewStudentTGenerator(newTestUniform(9), 5, 1, 2)
+	logNormal, _ := NewLogNormalGenerator(newTestUniform(10), 0.5, 0.4)
+	weibull, _ := NewWeibullGenerator(newTestUniform(11), 2, 1.5)
+	pareto, _ := NewParetoGenerator(newTestUniform(12), 1, 5)
+	lomax, _ := NewLomaxGenerator(newTestUniform(13), 2, 6)
+
+	cases := []struct {
+		name string
+		d    Distribution
+		next func() float64
+	}{
+		{"uniform", newTestUniform(1), newTestUniform(1).Float64},
+		{"exponential", NewExponentialGenerator(newTestUniform(2), 4), NewExponentialGenerator(newTestUniform(2), 4).ExpFloat64},
+		{"normal", NewNormalGenerator(newTestUniform(3), newTestUniform(4), 2, -1), NewNormalGenerator(newTestUniform(3), newTestUniform(4), 2, -1).NormFloat64},
+		{"ziggurat normal", NewZigguratNormalGenerator(newTestUniform(3), 2, -1), NewZigguratNormalGenerator(newTestUniform(3), 2, -1).NormFloat64},
+		{"ziggurat exponential", NewZigguratExponentialGenerator(newTestUniform(2), 4), NewZigguratExponentialGenerator(newTestUniform(2), 4).ExpFloat64},
+		{"gamma", gamma, gamma.GammaFloat64},
+		{"beta", beta, beta.BetaFloat64},
+		{"student-t", studentT, studentT.StudentTFloat64},
+		{"log-normal", logNormal, logNormal.LogNormalFloat64},
+		{"weibull", weibull, weibull.WeibullFloat64},
+		{"pareto", pareto, pareto.ParetoFloat64},
+		{"lomax", lomax, lomax.LomaxFloat64},
+	}
+
+	for _, c := range cases {
+		// the quantile inverts the distribution function, the density integrates to it
+		for _, p := range []float64{0.05, 0.25, 0.5, 0.75, 0.95} {
+			x := c.d.Quantile(p)
+			if got := c.d.CDF(x); math.Abs(got-p) > 1e-9 {
+				t.Errorf("%s: expected CDF(Quantile(%f)) = %f got %f", c.name, p, p, got)
+			}
+
+			const steps = 2000
+			low := c.d.Quantile(p - 0.01)
+			integral := 0.0
+			for i := 0; i < steps; i += 1 {
+				integral += c.d.PDF(low+(float64(i)+0.5)*(x-low)/steps) * (x - low) / steps
+			}
+
+			if math.Abs(integral-0.01) > 1e-6 {
+				t.Errorf("%s: expected the density to integrate to 0.01 over [%f, %f] got %f", c.name, low, x, integral)
+			}
+		}
+
+		mean, variance := c.d.Mean(), c.d.Variance()
+		checkMoments(t, c.name, c.next, samples, mean, 0.01*math.Max(math.Abs(mean), math.Sqrt(variance)), variance)
+	}
+
+	cauchy, _ := NewCauchyGenerator(newTestUniform(14), 0, 1)
+	if !math.IsNaN(cauchy.Mean()) || !math.IsNaN(cauchy.Variance()) {
+		t.Errorf("cauchy: expected undefined moments")
+	}
+}
+
+func TestParseGenerator(t *testing.T) {
+	gamma, _ := NewGammaGenerator(newTestUniform(1), NewZigguratNormalGenerator(newTestUniform(2), 1, 0), 2.5, 3)
+	truncated, _ := NewTruncatedNormalGenerator(newTestUniform(3), 1, 0, 2, math.Inf(1))
+	stable, _ := NewStableGenerator(NewUniformGenerator(NewMT19937Generator(5), math.MaxUint32+1), 1.5, 0.5, 2, 1)
+	wichmannHill, _ := NewWichmannHillGenerator(1, 2, 3)
+	ranlux, _ := NewRanluxGenerator(2, 7)
+	discrete, _ := NewDiscreteUniformGenerator(NewXoshiro256StarStarGenerator(9), math.MaxInt64, -5, 5)
+	pareto, _ := NewParetoGenerator(newTestUniform(4), 1, 3)
+	truncatedPareto, _ := NewTruncatedGenerator(newTestUniform(5), pareto, 1, 4)
+	copula, _ := NewClaytonCopulaGenerator(
+		NewUniformGenerator(NewPhiloxGenerator(1, 2), math.MaxUint32+1),
+		NewUniformGenerator(wichmannHill, math.MaxUint32+1),
+		NewExponentialGenerator(newTestUniform(6), 0.5),
+		pareto,
+		2,
+	)
+	poisson, _ := NewPoissonGenerator(newTestUniform(7), 4)
+	lomax, _ := NewLomaxGenerator(newTestUniform(8), 2, 6)
+	tail, _ := NewTruncatedNormalGenerator(newTestUniform(12), 2, 1, math.Inf(-1), -3)
+	mixture, _ := NewMixtureGenerator(
+		newTestUniform(9),
+		[]Sampler{NewSampler(lomax, lomax.LomaxFloat64), NewSampler(tail, tail.TruncatedFloat64)},
+		[]float64{1, 3},
+	)
+	exponential := NewExponentialGenerator(NewUniformGenerator(NewCongruentialGenerator(2147483647, 48271, 0, 1), 2147483647), 2)
+	compound, _ := NewCompoundPoissonGenerator(newTestUniform(10), 3, NewSampler(exponential, exponential.ExpFloat64))
+
+	cases := []struct {
+		name string
+		g    DistributionGenerator
+	}{
+		{"gamma", gamma},
+		{"truncated normal", truncated},
+		{"stable", stable},
+		{"ranlux", NewUniformGenerator(ranlux, 1<<24)},
+		{"discrete uniform", discrete},
+		{"discrete uniform bits", func() DistributionGenerator {
+			dg, _ := NewDiscreteUniformBitGenerator(NewBitGeneratorWithBits(NewSplitMix64Generator(3), 63), math.MinInt64, 7)
+			return dg
+		}()},
+		{"truncated", truncatedPareto},
+		{"mixture", mixture},
+		{"compound", compound},
+		{"poisson", poisson},
+		{"uniform bits", NewBitGeneratorWithBits(NewCongruentialGenerator(1<<16, 75, 74, 1), 16)},
+		{"sobol", func() DistributionGenerator {
+			sg, _ := NewSobolGenerator(2)
+			qp, _ := NewQMCProjectionGenerator(sg.WithRandomization(DigitalShift, 11), 1)
+			return NewExponentialGenerator(qp, 2)
+		}()},
+		{"congruential 2^64", NewUniformGenerator(NewCongruentialGenerator64(0, 6364136223846793005, 1442695040888963407, 1), math.MaxInt64)},
+		{"math-rand", NewNormalGeneratorDefault()},
+	}
+
+	for _, c := range cases {
+		parsed, err := ParseGenerator([]byte(c.g.String()))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+
+		if parsed.String() != c.g.String() {
+			t.Errorf("%s: expected %s got %s", c.name, c.g.String(), parsed.String())
+		}
+
+		expected, _ := sampler(c.g)
+		restored, err := sampler(parsed)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+
+		for i := 0; i < 100; i += 1 {
+			if e, r := expected.Sample(), restored.Sample(); e != r {
+				t.Errorf("%s: value %d: expected %v got %v", c.name, i, e, r)
+				break
+			}
+		}
+	}
+
+	// the copula values are pairs
+	parsed, err := ParseGenerator([]byte(copula.String()))
+	if err != nil {
+		t.Fatalf("copula: unexpected error %v", err)
+	}
+
+	for i := 0; i < 100; i += 1 {
+		ex, ey := copula.CopulaFloat64s()
+		rx, ry := parsed.(*CopulaGenerator).CopulaFloat64s()
+		if ex != rx || ey != ry {
+			t.Errorf("copula: value %d: expected (%v, %v) got (%v, %v)", i, ex, ey, rx, ry)
+			break
+		}
+	}
+
+	inverse, _ := NewInverseTransformGenerator(newTestUniform(11), math.Sqrt)
+	if _, err := ParseGenerator([]byte(inverse.String())); errors.Cause(err) != ErrUnknownGenerator {
+		t.Errorf("inverse transform: expected ErrUnknownGenerator got %v", err)
+	}
+
+	// a copy of a shared source would repeat the values of the first one, the
+	// variance of the normal values would double
+	ug := newTestUniform(13)
+	parsed, err = ParseGenerator([]byte(NewNormalGenerator(ug, ug, 1, 0).String()))
+	if err != nil {
+		t.Fatalf("normal shared source: unexpected error %v", err)
+	}
+
+	shared := parsed.(*NormalGenerator)
+	if shared.g != shared.g2 {
+		t.Errorf("normal shared source: expected a single parsed source")
+	}
+
+	checkMoments(t, "normal shared source", shared.NormFloat64, 200000, 0, 0.01, 1)
+
+	// the normal source of a gamma generator can't share its uniform source
+	nested, _ := NewGammaGenerator(ug, NewZigguratNormalGenerator(ug, 1, 0), 0.5, 1)
+	if _, err := ParseGenerator([]byte(nested.String())); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("gamma shared source: expected ErrInvalidParameters got %v", err)
+	}
+
+	invalid := []string{
+		`{"distributionName":"pareto","scale":1,"shape":-1}`,
+		`{"distributionName":"uniform","modulus":0}`,
+		`{"distributionName":"normal","source":{"distributionName":"congruential","modulus":16,"multiplier":5,"additiveComponent":1,"initialValue":0}}`,
+		`{"distributionName":"exponential","rate":"fast"}`,
+		`not json`,
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseGenerator([]byte(s)); errors.Cause(err) != ErrInvalidParameters {
+			t.Errorf("%s: expected ErrInvalidParameters got %v", s, err)
+		}
+	}
+
+	RegisterGenerator("constant", func(description []byte) (DistributionGenerator, error) {
+		return NewUniformGenerator(NewSplitMix64Generator(1), 2), nil
+	})
+
+	if g, err := ParseGenerator([]byte(`{"distributionName":"constant"}`)); err != nil || g.Name() != string(Uniform) {
+		t.Errorf("custom: expected uniform generator got %v, %v", g, err)
+	}
+}
+
+func TestBitGenerator(t *testing.T) {
+	bg, err := NewBitGenerator(NewPCG32Generator(42, 1))
+	if err != nil {
+		t.Fatalf("pcg32: unexpected error %v", err)
+	}
+
+	if bg.SourceBits() != 32 {
+		t.Errorf("pcg32: expected 32 bits got %d", bg.SourceBits())
+	}
+
+	// the first value holds the most significant bits
+	pcg := NewPCG32Generator(42, 1)
+	for i := 0; i < 10; i += 1 {
+		if e, v := pcg.Uint64(), bg.Uint64(); e != v {
+			t.Errorf("pcg32: value %d: expected %d got %d", i, e, v)
+		}
+	}
+
+	ranlux, _ := NewRanluxGenerator(1, 5)
+	twin, _ := NewRanluxGenerator(1, 5)
+	rg, err := NewBitGenerator(ranlux)
+	if err != nil || rg.SourceBits() != 24 {
+		t.Fatalf("ranlux: expected 24 bits got %v, %v", rg, err)
+	}
+
+	for i := 0; i < 10; i += 1 {
+		hi, lo := twin.Uint32(), twin.Uint32()
+		if e, v := hi<<8|lo>>16, rg.Uint32(); e != v {
+			t.Errorf("ranlux: value %d: expected %d got %d", i, e, v)
+		}
+	}
+
+	for _, g := range []IntGenerator{
+		NewCongruentialGenerator(2147483647, 48271, 0, 1),
+		func() IntGenerator { wh, _ := NewWichmannHillGenerator(1, 2, 3); return wh }(),
+		// the values of Int stay below 2^62
+		NewCongruentialGenerator64(1<<63+1, 6364136223846793005, 1, 0),
+	} {
+		if _, err := NewBitGenerator(g); errors.Cause(err) != ErrInvalidParameters {
+			t.Errorf("%T: expected ErrInvalidParameters got %v", g, err)
+		}
+	}
+
+	if b := NewCongruentialGenerator64(0, 6364136223846793005, 1, 0).Bits(); b != 63 {
+		t.Errorf("congruential 2^64: expected 63 bits got %d", b)
+	}
+
+	if b := NewCongruentialGenerator64(1<<63, 6364136223846793005, 1, 0).Bits(); b != 63 {
+		t.Errorf("congruential 2^63: expected 63 bits got %d", b)
+	}
+
+	// the weak low bits of xoroshiro128+ never reach the bit generator
+	xoroshiro, reference := NewXoroshiro128PlusGenerator(7), NewXoroshiro128PlusGenerator(7)
+	xg, err := NewBitGenerator(xoroshiro)
+	if err != nil || xg.SourceBits() != 53 {
+		t.Fatalf("xoroshiro128+: expected 53 bits, got %v", err)
+	}
+
+	for i := 0; i < 100; i += 1 {
+		if e, v := reference.Uint64()>>11, xg.next(53); e != v {
+			t.Fatalf("xoroshiro128+: value %d: expected %d got %d", i, e, v)
+		}
+	}
+
+	// a 4-bit source needs 14 values for every float
+	small := NewBitGeneratorWithBits(NewCongruentialGenerator(16, 5, 1, 0), 4)
+	for i := 0; i < 1000; i += 1 {
+		if x := small.Float64(); x < 0 || x >= 1 {
+			t.Fatalf("float64: %v is out of [0, 1)", x)
+		}
+
+		if x := small.OpenFloat64(); x <= 0 || x >= 1 {
+			t.Fatalf("open float64: %v is out of (0, 1)", x)
+		}
+
+		if x := small.Float64Range(-2, 3); x < -2 || x >= 3 {
+			t.Fatalf("float64 range: %v is out of [-2, 3)", x)
+		}
+	}
+
+	const samples = 600000
+
+	counts := make([]int, 6)
+	for i := 0; i < samples; i += 1 {
+		counts[bg.Intn(6)] += 1
+	}
+
+	for i, c := range counts {
+		if math.Abs(float64(c)-samples/6) > 0.01*samples/6 {
+			t.Errorf("intn: expected %d values %d got %d", samples/6, i, c)
+		}
+	}
+
+	if v := bg.Intn(1); v != 0 {
+		t.Errorf("intn: expected 0 got %d", v)
+	}
+
+	sum := 0.0
+	for i := 0; i < samples; i += 1 {
+		sum += bg.Float64Range(1, 5)
+	}
+
+	if mean := sum / samples; math.Abs(mean-3) > 0.01 {
+		t.Errorf("float64 range: expected mean 3 got %f", mean)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("intn: expected panic for 0")
+			}
+		}()
+
+		bg.Intn(0)
+	}()
+
+	// the first uniform value of the source is exactly 0
+	eg := NewExponentialGenerator(NewUniformGenerator(NewCongruentialGenerator(16, 1, 1, 15), 16), 1)
+	if x := eg.ExpFloat64(); math.IsInf(x, 0) {
+		t.Errorf("exponential: expected a finite value got %v", x)
+	}
+}
+
+func TestQMCGenerators(t *testing.T) {
+	// the first points of Joe and Kuo's reference implementation
+	sobolPoints := [][]float64{
+		{0, 0, 0},
+		{0.5, 0.5, 0.5},
+		{0.75, 0.25, 0.25},
+		{0.25, 0.75, 0.75},
+		{0.375, 0.375, 0.625},
+		{0.875, 0.875, 0.125},
+		{0.625, 0.125, 0.875},
+		{0.125, 0.625, 0.375},
+	}
+
+	sobol, _ := NewSobolGenerator(3)
+	for i, e := range sobolPoints {
+		if p := sobol.Float64s(); !floats.Equal(p, e) {
+			t.Errorf("sobol: point %d: expected %v got %v", i, e, p)
+		}
+	}
+
+	haltonPoints := [][]float64{{0, 0}, {0.5, 1.0 / 3}, {0.25, 2.0 / 3}, {0.75, 1.0 / 9}}
+
+	halton, _ := NewHaltonGenerator(2)
+	for i, e := range haltonPoints {
+		if x, y := halton.Float64(), halton.Float64(); math.Abs(x-e[0]) > 1e-15 || math.Abs(y-e[1]) > 1e-15 {
+			t.Errorf("halton: point %d: expected %v got (%v, %v)", i, e, x, y)
+		}
+	}
+
+	lattice, _ := NewLatticeGenerator(8, []uint64{1, 5})
+	if x := lattice.At(3, 1); x != 7.0/8 {
+		t.Errorf("lattice: expected %v got %v", 7.0/8, x)
+	}
+
+	if x := lattice.At(11, 1); x != 7.0/8 {
+		t.Errorf("lattice: expected the rule to repeat got %v", x)
+	}
+
+	if _, err := NewSobolGenerator(MaxSobolDimension + 1); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("sobol: expected ErrInvalidParameters got %v", err)
+	}
+
+	if MaxSobolDimension != len(joeKuoDirections)+1 {
+		t.Errorf("sobol: expected %d dimensions got %d", len(joeKuoDirections)+1, MaxSobolDimension)
+	}
+
+	// every coordinate of the first 2^m points has one value in every
+	// interval of length 2^-m, the digital randomizations keep the property
+	const m = 10
+
+	for _, r := range []QMCRandomization{NoRandomization, DigitalShift, OwenScrambling} {
+		sg, _ := NewSobolGenerator(MaxSobolDimension)
+		sg.WithRandomization(r, 42)
+
+		for k := 0; k < MaxSobolDimension; k += 1 {
+			seen := make([]bool, 1<<m)
+			for i := uint64(0); i < 1<<m; i += 1 {
+				seen[int(sg.At(i, k)*(1<<m))] = true
+			}
+
+			for j, s := range seen {
+				if !s {
+					t.Errorf("sobol %q: coordinate %d: no value in interval %d", r, k, j)
+					break
+				}
+			}
+		}
+	}
+
+	// the first two coordinates form a (0, m, 2)-net: every elementary
+	// interval of volume 2^-m holds one point
+	owen, _ := NewSobolGenerator(2)
+	owen.WithRandomization(OwenScrambling, 1)
+	for a := uint(0); a <= m; a += 1 {
+		seen := make(map[[2]int]bool)
+		for i := uint64(0); i < 1<<m; i += 1 {
+			seen[[2]int{int(owen.At(i, 0) * float64(uint(1)<<a)), int(owen.At(i, 1) * float64(uint(1)<<(m-a)))}] = true
+		}
+
+		if len(seen) != 1<<m {
+			t.Errorf("sobol: intervals 2^-%d x 2^-%d: expected %d occupied got %d", a, m-a, 1<<m, len(seen))
+		}
+	}
+
+	// the integral of the product of 2x_k over the unit cube is 1
+	integrate := func(g Float64Generator, dimension int, n int) float64 {
+		sum := 0.0
+		for i := 0; i < n; i += 1 {
+			f := 1.0
+			for k := 0; k < dimension; k += 1 {
+				f *= 2 * g.Float64()
+			}
+
+			sum += f
+		}
+
+		return sum / float64(n)
+	}
+
+	const points = 1 << 12
+
+	scrambled, _ := NewSobolGenerator(4)
+	scrambled.WithRandomization(OwenScrambling, 3)
+	shifted, _ := NewKorobovLatticeGenerator(4093, 1397, 4)
+	shifted.WithRandomization(RandomShift, 3)
+	permuted, _ := NewHaltonGenerator(4)
+	permuted.WithRandomization(DigitPermutation, 3)
+
+	qmcErrors := map[string]float64{
+		"sobol":  math.Abs(integrate(scrambled, 4, points) - 1),
+		"halton": math.Abs(integrate(permuted, 4, points) - 1),
+	}
+
+	for name, e := range qmcErrors {
+		// the Monte Carlo standard error is sqrt((4/3)^4 - 1)/sqrt(n) ≈ 0.019
+		if e > 0.003 {
+			t.Errorf("%s: expected integration error below 0.003 got %f", name, e)
+		}
+	}
+
+	// lattice rules are designed for periodic integrands, the integral of the
+	// product of 1 + sin(2πx_k) is 1
+	sum := 0.0
+	for i := 0; i < 4093; i += 1 {
+		f := 1.0
+		for _, x := range shifted.Float64s() {
+			f *= 1 + math.Sin(2*math.Pi*x)
+		}
+
+		sum += f
+	}
+
+	if e := math.Abs(sum/4093 - 1); e > 1e-6 {
+		t.Errorf("lattice: expected integration error below 1e-6 got %g", e)
+	}
+
+	// inverse transforms of the coordinates
+	sg, _ := NewSobolGenerator(12)
+	sg.WithRandomization(OwenScrambling, 9)
+	projection, _ := NewQMCProjectionGenerator(sg, 3)
+
+	eg := NewExponentialGenerator(projection, 2)
+	sum = 0.0
+	for i := 0; i < points; i += 1 {
+		sum += eg.ExpFloat64()
+	}
+
+	if mean := sum / points; math.Abs(mean-0.5) > 0.002 {
+		t.Errorf("exponential: expected mean 0.5 got %f", mean)
+	}
+
+	// one 12-dimensional point for every pair
+	tdg := NewTwoDimensionalGenerator(sg, sg, 1, 2, 3, 4, 0.5)
+	var sx, sy float64
+	for i := 0; i < points; i += 1 {
+		p := tdg.TwoDimensionalFloat64s()
+		sx += p.x
+		sy += p.y
+	}
+
+	if mx, my := sx/points, sy/points; math.Abs(mx-3) > 0.01 || math.Abs(my-4) > 0.01 {
+		t.Errorf("two-dimensional: expected means (3, 4) got (%f, %f)", mx, my)
+	}
+
+	if _, err := NewQMCProjectionGenerator(sg, 12); errors.Cause(err) != ErrInvalidParameters {
+		t.Errorf("projection: expected ErrInvalidParameters got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("lattice: expected panic for digital shift")
+			}
+		}()
+
+		lattice.WithRandomization(DigitalShift, 1)
+	}()
+}
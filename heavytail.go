@@ -0,0 +1,748 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+)
+
+// ParetoGenerator produces Pareto values x_m U^(-1/α) with P(X > x) = (x_m/x)^α
+type ParetoGenerator struct {
+	name  GeneratorName
+	g     Float64Generator
+	scale float64
+	shape float64
+}
+
+func (pg *ParetoGenerator) Name() string {
+	return string(pg.name)
+}
+
+func (pg *ParetoGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = pg.name
+	d["scale"] = pg.scale
+	d["shape"] = pg.shape
+
+	describeSource(d, "source", pg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewParetoGeneratorDefault(scale float64, shape float64) (*ParetoGenerator, error) {
+	return NewParetoGenerator(newDefaultGenerator(), scale, shape)
+}
+
+func NewParetoGenerator(generator Float64Generator, scale float64, shape float64) (*ParetoGenerator, error) {
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	if err := validatePositive("shape", shape); err != nil {
+		return nil, err
+	}
+
+	return &ParetoGenerator{name: Pareto, g: generator, scale: scale, shape: shape}, nil
+}
+
+func (pg *ParetoGenerator) ParetoFloat64() float64 {
+	return pg.scale * math.Pow(openUniform(pg.g), -1/pg.shape)
+}
+
+func (pg *ParetoGenerator) Quantile(p float64) float64 {
+	return pg.scale * math.Pow(1-p, -1/pg.shape)
+}
+
+func (pg *ParetoGenerator) law() distuv.Pareto {
+	return distuv.Pareto{Xm: pg.scale, Alpha: pg.shape}
+}
+
+func (pg *ParetoGenerator) PDF(x float64) float64 {
+	return pg.law().Prob(x)
+}
+
+func (pg *ParetoGenerator) CDF(x float64) float64 {
+	return pg.law().CDF(x)
+}
+
+func (pg *ParetoGenerator) Mean() float64 {
+	return pg.law().Mean()
+}
+
+func (pg *ParetoGenerator) Variance() float64 {
+	return pg.law().Variance()
+}
+
+// LomaxGenerator produces Lomax (Pareto type II) values λ (U^(-1/α) - 1),
+// the Pareto distribution shifted to start at 0
+type LomaxGenerator struct {
+	name  GeneratorName
+	g     Float64Generator
+	scale float64
+	shape float64
+}
+
+func (lg *LomaxGenerator) Name() string {
+	return string(lg.name)
+}
+
+func (lg *LomaxGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = lg.name
+	d["scale"] = lg.scale
+	d["shape"] = lg.shape
+
+	describeSource(d, "source", lg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewLomaxGeneratorDefault(scale float64, shape float64) (*LomaxGenerator, error) {
+	return NewLomaxGenerator(newDefaultGenerator(), scale, shape)
+}
+
+func NewLomaxGenerator(generator Float64Generator, scale float64, shape float64) (*LomaxGenerator, error) {
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	if err := validatePositive("shape", shape); err != nil {
+		return nil, err
+	}
+
+	return &LomaxGenerator{name: Lomax, g: generator, scale: scale, shape: shape}, nil
+}
+
+func (lg *LomaxGenerator) LomaxFloat64() float64 {
+	return lg.scale * math.Expm1(-math.Log(openUniform(lg.g))/lg.shape)
+}
+
+func (lg *LomaxGenerator) Quantile(p float64) float64 {
+	return lg.scale * math.Expm1(-math.Log1p(-p)/lg.shape)
+}
+
+func (lg *LomaxGenerator) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+
+	return lg.shape / lg.scale * math.Pow(1+x/lg.scale, -lg.shape-1)
+}
+
+func (lg *LomaxGenerator) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+
+	return -math.Expm1(-lg.shape * math.Log1p(x/lg.scale))
+}
+
+// Mean is infinite for shapes up to 1
+func (lg *LomaxGenerator) Mean() float64 {
+	if lg.shape <= 1 {
+		return math.Inf(1)
+	}
+
+	return lg.scale / (lg.shape - 1)
+}
+
+// Variance is infinite for shapes up to 2, undefined up to 1
+func (lg *LomaxGenerator) Variance() float64 {
+	switch {
+	case lg.shape <= 1:
+		return math.NaN()
+	case lg.shape <= 2:
+		return math.Inf(1)
+	}
+
+	return lg.scale * lg.scale * lg.shape / ((lg.shape - 1) * (lg.shape - 1) * (lg.shape - 2))
+}
+
+// CauchyGenerator produces Cauchy values x_0 + γ tan(π (U - 1/2))
+type CauchyGenerator struct {
+	name     GeneratorName
+	g        Float64Generator
+	location float64
+	scale    float64
+}
+
+func (cg *CauchyGenerator) Name() string {
+	return string(cg.name)
+}
+
+func (cg *CauchyGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = cg.name
+	d["location"] = cg.location
+	d["scale"] = cg.scale
+
+	describeSource(d, "source", cg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewCauchyGeneratorDefault(location float64, scale float64) (*CauchyGenerator, error) {
+	return NewCauchyGenerator(newDefaultGenerator(), location, scale)
+}
+
+func NewCauchyGenerator(generator Float64Generator, location float64, scale float64) (*CauchyGenerator, error) {
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	return &CauchyGenerator{name: Cauchy, g: generator, location: location, scale: scale}, nil
+}
+
+func (cg *CauchyGenerator) CauchyFloat64() float64 {
+	return cg.Quantile(openUniform(cg.g))
+}
+
+func (cg *CauchyGenerator) Quantile(p float64) float64 {
+	return cg.location + cg.scale*math.Tan(math.Pi*(p-0.5))
+}
+
+func (cg *CauchyGenerator) PDF(x float64) float64 {
+	z := (x - cg.location) / cg.scale
+
+	return 1 / (math.Pi * cg.scale * (1 + z*z))
+}
+
+func (cg *CauchyGenerator) CDF(x float64) float64 {
+	return 0.5 + math.Atan((x-cg.location)/cg.scale)/math.Pi
+}
+
+// Mean is undefined, NaN
+func (cg *CauchyGenerator) Mean() float64 {
+	return math.NaN()
+}
+
+// Variance is undefined, NaN
+func (cg *CauchyGenerator) Variance() float64 {
+	return math.NaN()
+}
+
+// StudentTGenerator produces location + scale·T values, T has the Student-t
+// distribution with ν degrees of freedom, drawn with Bailey's polar method
+type StudentTGenerator struct {
+	name             GeneratorName
+	g                Float64Generator
+	degreesOfFreedom float64
+	location         float64
+	scale            float64
+}
+
+func (sg *StudentTGenerator) Name() string {
+	return string(sg.name)
+}
+
+func (sg *StudentTGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = sg.name
+	d["degreesOfFreedom"] = sg.degreesOfFreedom
+	d["location"] = sg.location
+	d["scale"] = sg.scale
+
+	describeSource(d, "source", sg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewStudentTGeneratorDefault(degreesOfFreedom float64, location float64, scale float64) (*StudentTGenerator, error) {
+	return NewStudentTGenerator(newDefaultGenerator(), degreesOfFreedom, location, scale)
+}
+
+func NewStudentTGenerator(generator Float64Generator, degreesOfFreedom float64, location float64, scale float64) (*StudentTGenerator, error) {
+	if err := validatePositive("degrees of freedom", degreesOfFreedom); err != nil {
+		return nil, err
+	}
+
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	return &StudentTGenerator{name: StudentT, g: generator, degreesOfFreedom: degreesOfFreedom, location: location, scale: scale}, nil
+}
+
+func (sg *StudentTGenerator) StudentTFloat64() float64 {
+	nu := sg.degreesOfFreedom
+
+	for {
+		u := 2*sg.g.Float64() - 1
+		v := 2*sg.g.Float64() - 1
+		w := u*u + v*v
+
+		if w >= 1 || w == 0 {
+			continue
+		}
+
+		t := u * math.Sqrt(nu*math.Expm1(-2/nu*math.Log(w))/w)
+
+		return sg.location + sg.scale*t
+	}
+}
+
+func (sg *StudentTGenerator) law() distuv.StudentsT {
+	return distuv.StudentsT{Mu: sg.location, Sigma: sg.scale, Nu: sg.degreesOfFreedom}
+}
+
+// Quantile returns the inverse of the Student-t distribution function
+func (sg *StudentTGenerator) Quantile(p float64) float64 {
+	return sg.law().Quantile(p)
+}
+
+func (sg *StudentTGenerator) PDF(x float64) float64 {
+	return sg.law().Prob(x)
+}
+
+func (sg *StudentTGenerator) CDF(x float64) float64 {
+	return sg.law().CDF(x)
+}
+
+func (sg *StudentTGenerator) Mean() float64 {
+	return sg.law().Mean()
+}
+
+func (sg *StudentTGenerator) Variance() float64 {
+	return sg.law().Variance()
+}
+
+// LogNormalGenerator produces exp(μ + σZ) values, Z is standard normal drawn
+// with the Ziggurat method from the same source
+type LogNormalGenerator struct {
+	name   GeneratorName
+	g      Float64Generator
+	mu     float64
+	sigma  float64
+	normal ZigguratNormalGenerator
+}
+
+func (lg *LogNormalGenerator) Name() string {
+	return string(lg.name)
+}
+
+func (lg *LogNormalGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = lg.name
+	d["mu"] = lg.mu
+	d["sigma"] = lg.sigma
+
+	describeSource(d, "source", lg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewLogNormalGeneratorDefault(mu float64, sigma float64) (*LogNormalGenerator, error) {
+	return NewLogNormalGenerator(newDefaultGenerator(), mu, sigma)
+}
+
+func NewLogNormalGenerator(generator Float64Generator, mu float64, sigma float64) (*LogNormalGenerator, error) {
+	if err := validatePositive("sigma", sigma); err != nil {
+		return nil, err
+	}
+
+	return &LogNormalGenerator{
+		name:   LogNormal,
+		g:      generator,
+		mu:     mu,
+		sigma:  sigma,
+		normal: ZigguratNormalGenerator{name: ZigguratNormal, g: generator, stdDev: sigma, mean: mu},
+	}, nil
+}
+
+func (lg *LogNormalGenerator) LogNormalFloat64() float64 {
+	return math.Exp(lg.normal.NormFloat64())
+}
+
+func (lg *LogNormalGenerator) Quantile(p float64) float64 {
+	return math.Exp(lg.normal.Quantile(p))
+}
+
+func (lg *LogNormalGenerator) law() distuv.LogNormal {
+	return distuv.LogNormal{Mu: lg.mu, Sigma: lg.sigma}
+}
+
+func (lg *LogNormalGenerator) PDF(x float64) float64 {
+	return lg.law().Prob(x)
+}
+
+func (lg *LogNormalGenerator) CDF(x float64) float64 {
+	return lg.law().CDF(x)
+}
+
+func (lg *LogNormalGenerator) Mean() float64 {
+	return lg.law().Mean()
+}
+
+func (lg *LogNormalGenerator) Variance() float64 {
+	return lg.law().Variance()
+}
+
+// WeibullGenerator produces Weibull values λ (-log U)^(1/k), shapes below 1
+// give tails heavier than exponential
+type WeibullGenerator struct {
+	name  GeneratorName
+	g     Float64Generator
+	scale float64
+	shape float64
+}
+
+func (wg *WeibullGenerator) Name() string {
+	return string(wg.name)
+}
+
+func (wg *WeibullGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = wg.name
+	d["scale"] = wg.scale
+	d["shape"] = wg.shape
+
+	describeSource(d, "source", wg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewWeibullGeneratorDefault(scale float64, shape float64) (*WeibullGenerator, error) {
+	return NewWeibullGenerator(newDefaultGenerator(), scale, shape)
+}
+
+func NewWeibullGenerator(generator Float64Generator, scale float64, shape float64) (*WeibullGenerator, error) {
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	if err := validatePositive("shape", shape); err != nil {
+		return nil, err
+	}
+
+	return &WeibullGenerator{name: Weibull, g: generator, scale: scale, shape: shape}, nil
+}
+
+func (wg *WeibullGenerator) WeibullFloat64() float64 {
+	return wg.scale * math.Pow(-math.Log(openUniform(wg.g)), 1/wg.shape)
+}
+
+func (wg *WeibullGenerator) Quantile(p float64) float64 {
+	return wg.scale * math.Pow(-math.Log1p(-p), 1/wg.shape)
+}
+
+func (wg *WeibullGenerator) law() distuv.Weibull {
+	return distuv.Weibull{K: wg.shape, Lambda: wg.scale}
+}
+
+func (wg *WeibullGenerator) PDF(x float64) float64 {
+	return wg.law().Prob(x)
+}
+
+func (wg *WeibullGenerator) CDF(x float64) float64 {
+	return wg.law().CDF(x)
+}
+
+func (wg *WeibullGenerator) Mean() float64 {
+	return wg.law().Mean()
+}
+
+func (wg *WeibullGenerator) Variance() float64 {
+	return wg.law().Variance()
+}
+
+// StableGenerator produces α-stable values S(α, β, γ, δ) in Nolan's S1
+// parameterization with the Chambers–Mallows–Stuck method
+//
+// α ∈ (0, 2] is the tail index, β ∈ [-1, 1] the skewness, γ > 0 the scale
+// and δ the location; α = 2 is the normal distribution with variance 2γ²,
+// (α, β) = (1, 0) is Cauchy and (1/2, 1) is Lévy
+type StableGenerator struct {
+	name     GeneratorName
+	g        Float64Generator
+	alpha    float64
+	beta     float64
+	scale    float64
+	location float64
+}
+
+func (sg *StableGenerator) Name() string {
+	return string(sg.name)
+}
+
+func (sg *StableGenerator) String() string {
+	d := make(map[string]interface{}, 6)
+
+	d["distributionName"] = sg.name
+	d["alpha"] = sg.alpha
+	d["beta"] = sg.beta
+	d["scale"] = sg.scale
+	d["location"] = sg.location
+
+	describeSource(d, "source", sg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewStableGeneratorDefault(alpha float64, beta float64, scale float64, location float64) (*StableGenerator, error) {
+	return NewStableGenerator(newDefaultGenerator(), alpha, beta, scale, location)
+}
+
+func NewStableGenerator(generator Float64Generator, alpha float64, beta float64, scale float64, location float64) (*StableGenerator, error) {
+	if !(alpha > 0 && alpha <= 2) {
+		return nil, errors.Wrap(ErrInvalidParameters, "alpha is out of range (0, 2]")
+	}
+
+	if !(beta >= -1 && beta <= 1) {
+		return nil, errors.Wrap(ErrInvalidParameters, "beta is out of range [-1, 1]")
+	}
+
+	if err := validatePositive("scale", scale); err != nil {
+		return nil, err
+	}
+
+	return &StableGenerator{name: Stable, g: generator, alpha: alpha, beta: beta, scale: scale, location: location}, nil
+}
+
+func (sg *StableGenerator) StableFloat64() float64 {
+	v := math.Pi * (openUniform(sg.g) - 0.5)
+	w := -math.Log(openUniform(sg.g))
+
+	alpha, beta := sg.alpha, sg.beta
+
+	if alpha == 1 {
+		halfPi := math.Pi / 2
+		x := ((halfPi+beta*v)*math.Tan(v) - beta*math.Log(halfPi*w*math.Cos(v)/(halfPi+beta*v))) / halfPi
+
+		return sg.scale*x + beta*sg.scale*math.Log(sg.scale)/halfPi + sg.location
+	}
+
+	t := beta * math.Tan(math.Pi*alpha/2)
+	b := math.Atan(t) / alpha
+	s := math.Pow(1+t*t, 1/(2*alpha))
+
+	x := s * math.Sin(alpha*(v+b)) / math.Pow(math.Cos(v), 1/alpha) *
+		math.Pow(math.Cos(v-alpha*(v+b))/w, (1-alpha)/alpha)
+
+	return sg.scale*x + sg.location
+}
+
+// heavyTailState is shared by the heavy-tailed generators, only the
+// parameters of the distribution are set
+type heavyTailState struct {
+	Name             GeneratorName   `json:"distributionName"`
+	Scale            float64         `json:"scale,omitempty"`
+	Shape            float64         `json:"shape,omitempty"`
+	Location         float64         `json:"location,omitempty"`
+	DegreesOfFreedom float64         `json:"degreesOfFreedom,omitempty"`
+	Mu               float64         `json:"mu,omitempty"`
+	Sigma            float64         `json:"sigma,omitempty"`
+	Alpha            float64         `json:"alpha,omitempty"`
+	Beta             float64         `json:"beta,omitempty"`
+	Source           json.RawMessage `json:"source"`
+}
+
+func marshalHeavyTailState(s heavyTailState, g Float64Generator) ([]byte, error) {
+	var err error
+
+	if s.Source, err = marshalSource(g); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(s)
+}
+
+func unmarshalHeavyTailState(data []byte, name GeneratorName, g Float64Generator) (heavyTailState, Float64Generator, error) {
+	var s heavyTailState
+	if err := unmarshalState(data, name, &s); err != nil {
+		return s, nil, err
+	}
+
+	g, err := unmarshalFloat64Source(g, s.Source)
+	if err != nil {
+		return s, nil, err
+	}
+
+	return s, g, nil
+}
+
+func (pg *ParetoGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{Name: Pareto, Scale: pg.scale, Shape: pg.shape}, pg.g)
+}
+
+func (pg *ParetoGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, Pareto, pg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewParetoGenerator(g, s.Scale, s.Shape)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*pg = *restored
+
+	return nil
+}
+
+func (lg *LomaxGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{Name: Lomax, Scale: lg.scale, Shape: lg.shape}, lg.g)
+}
+
+func (lg *LomaxGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, Lomax, lg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewLomaxGenerator(g, s.Scale, s.Shape)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*lg = *restored
+
+	return nil
+}
+
+func (cg *CauchyGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{Name: Cauchy, Location: cg.location, Scale: cg.scale}, cg.g)
+}
+
+func (cg *CauchyGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, Cauchy, cg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewCauchyGenerator(g, s.Location, s.Scale)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*cg = *restored
+
+	return nil
+}
+
+func (sg *StudentTGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{
+		Name:             StudentT,
+		DegreesOfFreedom: sg.degreesOfFreedom,
+		Location:         sg.location,
+		Scale:            sg.scale,
+	}, sg.g)
+}
+
+func (sg *StudentTGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, StudentT, sg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewStudentTGenerator(g, s.DegreesOfFreedom, s.Location, s.Scale)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*sg = *restored
+
+	return nil
+}
+
+func (lg *LogNormalGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{Name: LogNormal, Mu: lg.mu, Sigma: lg.sigma}, lg.g)
+}
+
+func (lg *LogNormalGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, LogNormal, lg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewLogNormalGenerator(g, s.Mu, s.Sigma)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*lg = *restored
+
+	return nil
+}
+
+func (wg *WeibullGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{Name: Weibull, Scale: wg.scale, Shape: wg.shape}, wg.g)
+}
+
+func (wg *WeibullGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, Weibull, wg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewWeibullGenerator(g, s.Scale, s.Shape)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*wg = *restored
+
+	return nil
+}
+
+func (sg *StableGenerator) MarshalBinary() ([]byte, error) {
+	return marshalHeavyTailState(heavyTailState{
+		Name:     Stable,
+		Alpha:    sg.alpha,
+		Beta:     sg.beta,
+		Scale:    sg.scale,
+		Location: sg.location,
+	}, sg.g)
+}
+
+func (sg *StableGenerator) UnmarshalBinary(data []byte) error {
+	s, g, err := unmarshalHeavyTailState(data, Stable, sg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewStableGenerator(g, s.Alpha, s.Beta, s.Scale, s.Location)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*sg = *restored
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package generators
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"math/bits"
+)
+
+// Skip advances the generator by n steps in O(log n) time
+//
+// the n-fold composition of x -> m*x + a (mod n) is again an affine map,
+// its coefficients are computed by binary exponentiation
+func (cg *CongruentialGenerator) Skip(n uint64) {
+	mult, plus := affinePower(cg.m, cg.a, n, cg.n)
+
+	cg.current = addMod(mulMod(mult, cg.current, cg.n), plus, cg.n)
+}
+
+// Jump returns an independent copy of the generator advanced by n steps,
+// cg itself is left untouched
+func (cg *CongruentialGenerator) Jump(n uint64) *CongruentialGenerator {
+	j := *cg
+	j.Skip(n)
+	j.initial = j.current
+
+	return &j
+}
+
+// Substreams derives count generators starting at offsets 0, length,
+// 2*length... from the current position of cg
+//
+// the streams are disjoint as long as count*length does not exceed the period
+// of the sequence started from the current position, parameters which exceed
+// it or whose period can't be derived are rejected with ErrInvalidParameters
+func (cg *CongruentialGenerator) Substreams(count int, length uint64) ([]*CongruentialGenerator, error) {
+	if count < 1 {
+		return nil, errors.Wrapf(ErrInvalidParameters, "substreams count %d is less than 1", count)
+	}
+
+	period, exact := cg.period()
+	if !exact {
+		return nil, errors.Wrap(ErrInvalidParameters, "the period of the generator can't be derived")
+	}
+
+	// period 0 stands for 2^64
+	if hi, total := bits.Mul64(uint64(count), length); (period == 0 && (hi > 1 || hi == 1 && total != 0)) ||
+		(period != 0 && (hi != 0 || total > period)) {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%d substreams of length %d exceed period %s", count, length, modulusNumber(period))
+	}
+
+	streams := make([]*CongruentialGenerator, 0, count)
+
+	s := cg.Jump(0)
+	for i := 0; i < count; i += 1 {
+		streams = append(streams, s)
+		s = s.Jump(length)
+	}
+
+	return streams, nil
+}
+
+// period returns the period of the sequence started from the current state,
+// 0 stands for 2^64, the second value is false when the period is unknown
+func (cg *CongruentialGenerator) period() (uint64, bool) {
+	switch {
+	case cg.n == 0 && cg.a != 0:
+		// Hull–Dobell for 2^64
+		return 0, cg.a%2 == 1 && cg.m%4 == 1
+	case cg.n == 0:
+		if cg.m%2 == 0 || cg.current%2 == 0 {
+			return 0, false
+		}
+
+		return powerOfTwoOrder(cg.m, 0), true
+	case cg.n > math.MaxInt64 || cg.m == 0:
+		return 0, false
+	}
+
+	analysis, err := AnalyzeCongruential(int(cg.n), int(cg.m), int(cg.a), int(cg.current))
+	if err != nil {
+		return 0, false
+	}
+
+	return analysis.Period, analysis.Exact
+}
+
+// affinePower returns the coefficients of the n-th power of x -> mult*x + plus (mod modulus)
+func affinePower(mult, plus, n, modulus uint64) (uint64, uint64) {
+	accMult, accPlus := modMod(1, modulus), uint64(0)
+
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			accMult = mulMod(accMult, mult, modulus)
+			accPlus = addMod(mulMod(accPlus, mult, modulus), plus, modulus)
+		}
+
+		plus = mulMod(addMod(mult, 1, modulus), plus, modulus)
+		mult = mulMod(mult, mult, modulus)
+	}
+
+	return accMult, accPlus
+}
+
+// the helpers below operate on values already reduced by the modulus,
+// modulus 0 stands for 2^64
+
+func reduce(v int, modulus uint64) uint64 {
+	if modulus == 0 {
+		return uint64(v)
+	}
+
+	if v >= 0 {
+		return uint64(v) % modulus
+	}
+
+	r := uint64(-(v + 1)) % modulus
+
+	return modulus - 1 - r
+}
+
+func modMod(v, modulus uint64) uint64 {
+	if modulus == 0 {
+		return v
+	}
+
+	return v % modulus
+}
+
+func mulMod(a, b, modulus uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if modulus == 0 {
+		return lo
+	}
+
+	return bits.Rem64(hi, lo, modulus)
+}
+
+func addMod(a, b, modulus uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if modulus != 0 && (carry != 0 || s >= modulus) {
+		s -= modulus
+	}
+
+	return s
+}
@@ -0,0 +1,521 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+type LaggedFibonacciOperation string
+
+const (
+	AdditiveLaggedFibonacci       LaggedFibonacciOperation = "additive"
+	MultiplicativeLaggedFibonacci LaggedFibonacciOperation = "multiplicative"
+)
+
+// seeding LCG of James's RANLUX, L'Ecuyer's first component
+const (
+	lagSeedModulus    = lEcuyerModulus1
+	lagSeedMultiplier = lEcuyerMultiplier1
+)
+
+// FillLagTable fills the lag table with bits-wide words drawn from g, which is
+// expected to produce at least 31 random bits (e.g. a prime modulus LCG)
+//
+// table[0] receives the first draw and is treated as the most recent value,
+// table[len(table)-1] as the oldest one, as in James's RANLUX
+func FillLagTable(g IntGenerator, table []uint32, bits uint) {
+	if bits < 1 || bits > 32 {
+		panic(fmt.Sprintf("bits count %d is out of range [1, 32]", bits))
+	}
+
+	mask := uint32(1<<bits - 1)
+
+	for i := range table {
+		v := uint32(g.Int())
+		if bits > 24 {
+			v = v<<16 | uint32(g.Int())&0xffff
+		}
+
+		table[i] = v & mask
+	}
+}
+
+func newLagSeedGenerator(seed uint64) (*CongruentialGenerator, error) {
+	if seed%lagSeedModulus == 0 {
+		return nil, errors.Wrapf(ErrInvalidParameters, "seed is a multiple of %d", lagSeedModulus)
+	}
+
+	return NewCongruentialGenerator64(lagSeedModulus, lagSeedMultiplier, 0, seed), nil
+}
+
+// lagTable is the circular buffer shared by the lagged generators, i points
+// at x_{n-r} and j at x_{n-s}, both move downwards
+type lagTable struct {
+	values []uint32
+	i      int
+	j      int
+}
+
+func newLagTable(shortLag int, longLag int) lagTable {
+	return lagTable{values: make([]uint32, longLag), i: longLag - 1, j: shortLag - 1}
+}
+
+func (t *lagTable) advance() {
+	t.i -= 1
+	if t.i < 0 {
+		t.i = len(t.values) - 1
+	}
+
+	t.j -= 1
+	if t.j < 0 {
+		t.j = len(t.values) - 1
+	}
+}
+
+func validateLags(shortLag int, longLag int) error {
+	if shortLag < 1 || longLag <= shortLag {
+		return errors.Wrap(ErrInvalidParameters, "lags must satisfy 0 < short lag < long lag")
+	}
+
+	return nil
+}
+
+func validateOperation(operation LaggedFibonacciOperation) error {
+	if operation != AdditiveLaggedFibonacci && operation != MultiplicativeLaggedFibonacci {
+		return errors.Wrapf(ErrInvalidParameters, "unknown operation %q", operation)
+	}
+
+	return nil
+}
+
+// LaggedFibonacciGenerator computes x_n = x_{n-s} ∘ x_{n-r} (mod 2^32) where
+// ∘ is addition or multiplication, s and r are the short and long lags;
+// common lag pairs are (5, 17), (24, 55) and (273, 607)
+type LaggedFibonacciGenerator struct {
+	name      GeneratorName
+	operation LaggedFibonacciOperation
+	shortLag  int
+	longLag   int
+	seed      uint64
+	table     lagTable
+}
+
+// NewLaggedFibonacciGenerator fills the lag table from the seed with the LCG
+// x -> 40014x mod 2147483563, multiplicative generators get odd values only
+func NewLaggedFibonacciGenerator(
+	operation LaggedFibonacciOperation,
+	shortLag int,
+	longLag int,
+	seed uint64,
+) (*LaggedFibonacciGenerator, error) {
+	if err := validateOperation(operation); err != nil {
+		return nil, err
+	}
+
+	if err := validateLags(shortLag, longLag); err != nil {
+		return nil, err
+	}
+
+	lcg, err := newLagSeedGenerator(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &LaggedFibonacciGenerator{
+		name:      LaggedFibonacci,
+		operation: operation,
+		shortLag:  shortLag,
+		longLag:   longLag,
+		seed:      seed,
+		table:     newLagTable(shortLag, longLag),
+	}
+
+	FillLagTable(lcg, g.table.values, 32)
+
+	switch operation {
+	case MultiplicativeLaggedFibonacci:
+		for i := range g.table.values {
+			g.table.values[i] |= 1
+		}
+	case AdditiveLaggedFibonacci:
+		// at least one odd value is required for the maximal period
+		g.table.values[0] |= 1
+	}
+
+	return g, nil
+}
+
+func (g *LaggedFibonacciGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *LaggedFibonacciGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = g.name
+	d["operation"] = g.operation
+	d["shortLag"] = g.shortLag
+	d["longLag"] = g.longLag
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Uint32 returns the next value, multiplicative generators produce odd values
+// only and have 31 random bits
+func (g *LaggedFibonacciGenerator) Uint32() uint32 {
+	t := &g.table
+
+	var v uint32
+	if g.operation == MultiplicativeLaggedFibonacci {
+		v = t.values[t.j] * t.values[t.i]
+	} else {
+		v = t.values[t.j] + t.values[t.i]
+	}
+
+	t.values[t.i] = v
+	t.advance()
+
+	return v
+}
+
+// Int returns values in [0, 2^32), multiplicative generators drop the
+// constant lowest bit and return values in [0, 2^31)
+func (g *LaggedFibonacciGenerator) Int() int {
+	if g.operation == MultiplicativeLaggedFibonacci {
+		return int(g.Uint32() >> 1)
+	}
+
+	return int(g.Uint32())
+}
+
+func (g *LaggedFibonacciGenerator) Bits() uint {
+	if g.operation == MultiplicativeLaggedFibonacci {
+		return 31
+	}
+
+	return 32
+}
+
+// SubtractWithCarryGenerator is Marsaglia and Zaman's subtract-with-carry
+// generator x_n = x_{n-s} - x_{n-r} - c_{n-1} (mod 2^bits), the carry c_n is
+// 1 when the subtraction borrows
+type SubtractWithCarryGenerator struct {
+	name     GeneratorName
+	bits     uint
+	shortLag int
+	longLag  int
+	seed     uint64
+	table    lagTable
+	carry    uint32
+}
+
+// NewSubtractWithCarryGenerator fills the lag table from the seed with the LCG
+// x -> 40014x mod 2147483563; RANLUX uses 24 bits with lags (10, 24)
+func NewSubtractWithCarryGenerator(bits uint, shortLag int, longLag int, seed uint64) (*SubtractWithCarryGenerator, error) {
+	if bits < 1 || bits > 32 {
+		return nil, errors.Wrapf(ErrInvalidParameters, "bits count %d is out of range [1, 32]", bits)
+	}
+
+	if err := validateLags(shortLag, longLag); err != nil {
+		return nil, err
+	}
+
+	lcg, err := newLagSeedGenerator(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &SubtractWithCarryGenerator{
+		name:     SubtractWithCarry,
+		bits:     bits,
+		shortLag: shortLag,
+		longLag:  longLag,
+		seed:     seed,
+		table:    newLagTable(shortLag, longLag),
+	}
+
+	FillLagTable(lcg, g.table.values, bits)
+
+	if g.table.values[longLag-1] == 0 {
+		g.carry = 1
+	}
+
+	return g, nil
+}
+
+func (g *SubtractWithCarryGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *SubtractWithCarryGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = g.name
+	d["bits"] = g.bits
+	d["shortLag"] = g.shortLag
+	d["longLag"] = g.longLag
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Uint32 returns values in [0, 2^bits)
+func (g *SubtractWithCarryGenerator) Uint32() uint32 {
+	t := &g.table
+
+	v := int64(t.values[t.j]) - int64(t.values[t.i]) - int64(g.carry)
+	if v < 0 {
+		v += 1 << g.bits
+		g.carry = 1
+	} else {
+		g.carry = 0
+	}
+
+	t.values[t.i] = uint32(v)
+	t.advance()
+
+	return uint32(v)
+}
+
+func (g *SubtractWithCarryGenerator) Int() int {
+	return int(g.Uint32())
+}
+
+func (g *SubtractWithCarryGenerator) Bits() uint {
+	return g.bits
+}
+
+// Float64 returns values in [0, 1) with bits bits of resolution
+func (g *SubtractWithCarryGenerator) Float64() float64 {
+	return float64(g.Uint32()) / float64(uint64(1)<<g.bits)
+}
+
+// ranluxLuxury holds p, the count of values generated per 24 returned ones,
+// for luxury levels 0..4
+var ranluxLuxury = []int{24, 48, 97, 223, 389}
+
+// RanluxGenerator is Lüscher's RANLUX: a 24-bit subtract-with-carry generator
+// with lags (10, 24) which throws away p-24 of every p values to decorrelate
+// the output, p is selected by the luxury level
+type RanluxGenerator struct {
+	name     GeneratorName
+	level    int
+	swc      *SubtractWithCarryGenerator
+	returned int
+}
+
+// NewRanluxGenerator seeds the generator the same way as James's RLUXGO,
+// level 3 with seed 314159265 is the default of the original implementation
+func NewRanluxGenerator(level int, seed uint64) (*RanluxGenerator, error) {
+	if level < 0 || level >= len(ranluxLuxury) {
+		return nil, errors.Wrapf(ErrInvalidParameters, "luxury level %d is out of range [0, %d]", level, len(ranluxLuxury)-1)
+	}
+
+	swc, err := NewSubtractWithCarryGenerator(24, 10, 24, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RanluxGenerator{name: Ranlux, level: level, swc: swc}, nil
+}
+
+func (g *RanluxGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *RanluxGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = g.name
+	d["luxuryLevel"] = g.level
+	d["seed"] = g.swc.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Uint32 returns values in [0, 2^24)
+func (g *RanluxGenerator) Uint32() uint32 {
+	if g.returned == 24 {
+		for i := 24; i < ranluxLuxury[g.level]; i += 1 {
+			_ = g.swc.Uint32()
+		}
+
+		g.returned = 0
+	}
+
+	g.returned += 1
+
+	return g.swc.Uint32()
+}
+
+func (g *RanluxGenerator) Int() int {
+	return int(g.Uint32())
+}
+
+func (g *RanluxGenerator) Bits() uint {
+	return 24
+}
+
+// Float64 returns values in [0, 1) with 24 bits of resolution
+func (g *RanluxGenerator) Float64() float64 {
+	return float64(g.Uint32()) / (1 << 24)
+}
+
+type laggedState struct {
+	Name     GeneratorName            `json:"distributionName"`
+	Op       LaggedFibonacciOperation `json:"operation,omitempty"`
+	Bits     uint                     `json:"bits,omitempty"`
+	Level    int                      `json:"luxuryLevel,omitempty"`
+	ShortLag int                      `json:"shortLag"`
+	LongLag  int                      `json:"longLag"`
+	Seed     uint64                   `json:"seed"`
+	Table    []uint32                 `json:"table"`
+	I        int                      `json:"i"`
+	J        int                      `json:"j"`
+	Carry    uint32                   `json:"carry"`
+	Returned int                      `json:"returned"`
+}
+
+func (s laggedState) lagTable() (lagTable, error) {
+	if err := validateLags(s.ShortLag, s.LongLag); err != nil {
+		return lagTable{}, errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	if len(s.Table) != s.LongLag || s.I < 0 || s.I >= s.LongLag || s.J < 0 || s.J >= s.LongLag {
+		return lagTable{}, errors.Wrap(ErrInvalidState, "lag table mismatch")
+	}
+
+	return lagTable{values: s.Table, i: s.I, j: s.J}, nil
+}
+
+func (g *LaggedFibonacciGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(laggedState{
+		Name:     LaggedFibonacci,
+		Op:       g.operation,
+		ShortLag: g.shortLag,
+		LongLag:  g.longLag,
+		Seed:     g.seed,
+		Table:    g.table.values,
+		I:        g.table.i,
+		J:        g.table.j,
+	})
+}
+
+func (g *LaggedFibonacciGenerator) UnmarshalBinary(data []byte) error {
+	var s laggedState
+	if err := unmarshalState(data, LaggedFibonacci, &s); err != nil {
+		return err
+	}
+
+	if err := validateOperation(s.Op); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	table, err := s.lagTable()
+	if err != nil {
+		return err
+	}
+
+	g.name = LaggedFibonacci
+	g.operation = s.Op
+	g.shortLag = s.ShortLag
+	g.longLag = s.LongLag
+	g.seed = s.Seed
+	g.table = table
+
+	return nil
+}
+
+func (g *SubtractWithCarryGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(g.state())
+}
+
+func (g *SubtractWithCarryGenerator) state() laggedState {
+	return laggedState{
+		Name:     SubtractWithCarry,
+		Bits:     g.bits,
+		ShortLag: g.shortLag,
+		LongLag:  g.longLag,
+		Seed:     g.seed,
+		Table:    g.table.values,
+		I:        g.table.i,
+		J:        g.table.j,
+		Carry:    g.carry,
+	}
+}
+
+func (g *SubtractWithCarryGenerator) UnmarshalBinary(data []byte) error {
+	var s laggedState
+	if err := unmarshalState(data, SubtractWithCarry, &s); err != nil {
+		return err
+	}
+
+	return g.restore(s)
+}
+
+func (g *SubtractWithCarryGenerator) restore(s laggedState) error {
+	table, err := s.lagTable()
+	if err != nil {
+		return err
+	}
+
+	if s.Bits < 1 || s.Bits > 32 {
+		return errors.Wrap(ErrInvalidState, "bits count is out of range")
+	}
+
+	g.name = SubtractWithCarry
+	g.bits = s.Bits
+	g.shortLag = s.ShortLag
+	g.longLag = s.LongLag
+	g.seed = s.Seed
+	g.table = table
+	g.carry = s.Carry
+
+	return nil
+}
+
+func (g *RanluxGenerator) MarshalBinary() ([]byte, error) {
+	s := g.swc.state()
+	s.Name = Ranlux
+	s.Level = g.level
+	s.Returned = g.returned
+
+	return json.Marshal(s)
+}
+
+func (g *RanluxGenerator) UnmarshalBinary(data []byte) error {
+	var s laggedState
+	if err := unmarshalState(data, Ranlux, &s); err != nil {
+		return err
+	}
+
+	if s.Level < 0 || s.Level >= len(ranluxLuxury) {
+		return errors.Wrap(ErrInvalidState, "luxury level is out of range")
+	}
+
+	swc := &SubtractWithCarryGenerator{}
+	if err := swc.restore(s); err != nil {
+		return err
+	}
+
+	g.name = Ranlux
+	g.level = s.Level
+	g.swc = swc
+	g.returned = s.Returned
+
+	return nil
+}
@@ -27,6 +27,15 @@ func (e *Engine) WithSteps(steps int) *Engine {
 }
 
 func (e Engine) NextImpl() []int {
+	return e.nextImpl(func(g *Generator) int { return g.Next() })
+}
+
+// NextImplFrom is NextImpl drawing the transitions from src
+func (e Engine) NextImplFrom(src Float64Source) []int {
+	return e.nextImpl(func(g *Generator) int { return g.NextFrom(src) })
+}
+
+func (e Engine) nextImpl(next func(g *Generator) int) []int {
 	res := make([]int, 0, e.sc+1)
 
 	curr := e.s
@@ -37,7 +46,7 @@ func (e Engine) NextImpl() []int {
 			return res
 		}
 
-		curr = NewGenerator(row).Next()
+		curr = next(NewGenerator(row))
 		res = append(res, curr)
 	}
 
@@ -10,6 +10,9 @@ type segment struct {
 	v  int
 }
 
+// Float64Source is a generator of uniformly distributed values in [0, 1)
+type Float64Source interface{ Float64() float64 }
+
 type Generator struct {
 	segments []segment
 }
@@ -39,8 +42,15 @@ func NewGenerator(probs []float64) *Generator {
 }
 
 func (g Generator) Next() int {
-	rnd := rand.Float64()
+	return g.pick(rand.Float64())
+}
+
+// NextFrom is Next drawing from src instead of the global source
+func (g Generator) NextFrom(src Float64Source) int {
+	return g.pick(src.Float64())
+}
 
+func (g Generator) pick(rnd float64) int {
 	for _, s := range g.segments {
 		if s.lb <= rnd && s.rb > rnd {
 			return s.v
@@ -2,11 +2,12 @@ package main
 
 import (
 	"flag"
+	"github.com/Sinu5oid/generators"
 	"github.com/Sinu5oid/generators/markov/chain"
 	"github.com/Sinu5oid/generators/markov/cmd/diff"
 	"github.com/Sinu5oid/generators/markov/cmd/html"
 	"log"
-	"math/rand"
+	"math"
 	"os"
 	"runtime"
 	"sync"
@@ -15,10 +16,13 @@ import (
 
 func main() {
 	viewHTML := flag.Bool("html", false, "use html as a result view")
+	seed := flag.Uint64("seed", 0, "seed of the implementation streams, 0 picks one from the clock")
 
 	flag.Parse()
 
-	rand.Seed(time.Now().UTC().UnixNano())
+	if *seed == 0 {
+		*seed = uint64(time.Now().UTC().UnixNano())
+	}
 
 	logger := log.New(os.Stdout, "", 0)
 	started := time.Now()
@@ -47,12 +51,14 @@ func main() {
 	e := chain.NewEngine(tm, s)
 	e = e.WithSteps(sc)
 
-	implsc := make(chan []int, ic)
+	// every implementation index draws from its own stream, so the results
+	// don't depend on the number of cpus or the scheduling
+	impls := make([][]int, ic)
 
 	wg := sync.WaitGroup{}
 	wg.Add(cpus)
 
-	logger.Println("started implementations generation")
+	logger.Println("started implementations generation, seed", *seed)
 	// set up workers pool
 	for runnerIndex := 0; runnerIndex < cpus; runnerIndex++ {
 		its := ic / cpus
@@ -61,38 +67,19 @@ func main() {
 			its = (ic / cpus) + (ic % cpus)
 		}
 
-		go func(wg *sync.WaitGroup, e *chain.Engine, out chan<- []int, its int, index int) {
+		go func(wg *sync.WaitGroup, e *chain.Engine, its int, first int, index int) {
 			defer wg.Done()
 			defer func() {
 				logger.Println("routine #", index, "finished")
 			}()
 
-			for i := 0; i < its; i++ {
-				out <- e.NextImpl()
+			for i := first; i < first+its; i++ {
+				src := generators.NewUniformGenerator(generators.NewPhiloxGenerator(*seed, uint64(i)), math.MaxUint32+1)
+				impls[i] = e.NextImplFrom(src)
 			}
-		}(&wg, e, implsc, its, runnerIndex)
+		}(&wg, e, its, runnerIndex*(ic/cpus), runnerIndex)
 	}
 
-	// discharge generated implementations
-	impls := make([][]int, 0, ic)
-	wg.Add(1)
-	go func() {
-		logger.Println("started discharging generated implementations")
-		defer wg.Done()
-		defer func() {
-			logger.Println("finished discharging generated implementations")
-		}()
-
-		for i := 0; i < ic; i++ {
-			impl, ok := <-implsc
-			if !ok {
-				break
-			}
-			impls = append(impls, impl)
-		}
-		close(implsc)
-	}()
-
 	// get theoretical p(t)
 	logger.Println("started computing theoretical p(t)")
 	tprobs := make([][]float64, 0, sc+1)
@@ -0,0 +1,86 @@
+package mc
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/stat/distuv"
+	"math"
+)
+
+// Method is the sampling scheme of an estimate
+type Method string
+
+const (
+	PlainMethod              Method = "plain"
+	AntitheticMethod         Method = "antithetic"
+	ControlVariateMethod     Method = "control-variate"
+	ImportanceSamplingMethod Method = "importance-sampling"
+	StratifiedMethod         Method = "stratified"
+	LatinHypercubeMethod     Method = "latin-hypercube"
+)
+
+// Estimate is the result of a Monte Carlo estimation
+type Estimate struct {
+	Method Method
+	Value  float64
+	// StandardError is the estimated standard deviation of Value
+	StandardError float64
+	// Evaluations is the count of evaluations of the integrand
+	Evaluations int
+	// PlainVariance estimates the variance of the integrand at a uniform
+	// point, the variance of plain Monte Carlo with one evaluation
+	PlainVariance float64
+}
+
+// ConfidenceInterval returns the normal confidence interval of the value with
+// the given level, e.g. 0.95; panics if the level is out of (0, 1)
+func (e Estimate) ConfidenceInterval(level float64) (float64, float64) {
+	if !(level > 0 && level < 1) {
+		panic(fmt.Sprintf("confidence level %v is out of range (0, 1)", level))
+	}
+
+	h := distuv.UnitNormal.Quantile((1+level)/2) * e.StandardError
+
+	return e.Value - h, e.Value + h
+}
+
+// VarianceReductionFactor returns the ratio of the variance of plain Monte
+// Carlo with the same count of evaluations to the variance of the estimate,
+// factors above 1 mean the method needs that many times fewer evaluations
+func (e Estimate) VarianceReductionFactor() float64 {
+	return e.PlainVariance / float64(e.Evaluations) / (e.StandardError * e.StandardError)
+}
+
+func (e Estimate) String() string {
+	return fmt.Sprintf("%s: %g ± %g (%d evaluations)", e.Method, e.Value, e.StandardError, e.Evaluations)
+}
+
+// moments accumulates the mean and the sum of squared deviations with
+// Welford's algorithm
+type moments struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (m *moments) add(x float64) {
+	m.n += 1
+
+	d := x - m.mean
+	m.mean += d / float64(m.n)
+	m.m2 += d * (x - m.mean)
+}
+
+// variance returns the unbiased sample variance
+func (m *moments) variance() float64 {
+	if m.n < 2 {
+		return math.NaN()
+	}
+
+	return m.m2 / float64(m.n-1)
+}
+
+// plainVariance returns the variance of the integrand from the estimates of
+// its mean and of the mean of its square, negative rounding errors are cut
+func plainVariance(mean float64, meanSquare float64) float64 {
+	return math.Max(meanSquare-mean*mean, 0)
+}
@@ -0,0 +1,357 @@
+package mc
+
+import (
+	"github.com/Sinu5oid/generators"
+	"github.com/pkg/errors"
+	"math"
+)
+
+// Integrand is a function on the unit cube [0, 1)^d, the integrators reuse
+// the point slice between evaluations, so it must not be retained
+type Integrand func(x []float64) float64
+
+// Box maps f on the box [lower, upper) to the unit cube, the integral of the
+// result over the cube is the integral of f over the box
+func Box(f Integrand, lower []float64, upper []float64) Integrand {
+	if len(lower) != len(upper) {
+		panic("box bounds have different dimensions")
+	}
+
+	volume := 1.0
+	for k := range lower {
+		volume *= upper[k] - lower[k]
+	}
+
+	y := make([]float64, len(lower))
+
+	return func(x []float64) float64 {
+		for k := range y {
+			y[k] = lower[k] + (upper[k]-lower[k])*x[k]
+		}
+
+		return volume * f(y)
+	}
+}
+
+// Proposal is the density q of importance sampling
+type Proposal struct {
+	// Sample maps a uniform point of the unit cube to a point drawn from q,
+	// e.g. with the inverse distribution functions of its marginals
+	Sample func(u []float64) []float64
+	// Weight returns the likelihood ratio p(x)/q(x), p is the target density
+	// (1 for the integral over the unit cube)
+	Weight func(x []float64) float64
+}
+
+// Integrator estimates integrals over the unit cube and expectations E[f(U)]
+// of uniform points U, the coordinates of the points are successive values of
+// the generator
+//
+// the standard errors assume independent values, QMC sequences give smaller
+// errors than reported unless they are randomized and replicated
+type Integrator struct {
+	g         generators.Float64Generator
+	dimension int
+}
+
+func NewIntegratorDefault(dimension int) (*Integrator, error) {
+	return NewIntegrator(generators.NewBitGeneratorDefault(), dimension)
+}
+
+// NewIntegrator builds the integrator of the given dimension, generator must
+// produce uniform values in [0, 1)
+func NewIntegrator(generator generators.Float64Generator, dimension int) (*Integrator, error) {
+	if generator == nil {
+		return nil, errors.Wrap(generators.ErrInvalidParameters, "generator is nil")
+	}
+
+	if dimension < 1 {
+		return nil, errors.Wrapf(generators.ErrInvalidParameters, "dimension %d must be positive", dimension)
+	}
+
+	return &Integrator{g: generator, dimension: dimension}, nil
+}
+
+func (i *Integrator) Dimension() int {
+	return i.dimension
+}
+
+// fill draws a uniform point into x
+func (i *Integrator) fill(x []float64) {
+	for k := range x {
+		x[k] = i.g.Float64()
+	}
+}
+
+// uniformInt returns a value in [0, n) for the permutations
+func (i *Integrator) uniformInt(n int) int {
+	if r := int(i.g.Float64() * float64(n)); r < n {
+		return r
+	}
+
+	return n - 1
+}
+
+func validateSamples(n int, min int) error {
+	if n < min {
+		return errors.Wrapf(generators.ErrInvalidParameters, "%d samples, at least %d are required", n, min)
+	}
+
+	return nil
+}
+
+// Plain estimates the integral of f with n independent uniform points
+func (i *Integrator) Plain(f Integrand, n int) (Estimate, error) {
+	if err := validateSamples(n, 2); err != nil {
+		return Estimate{}, err
+	}
+
+	x := make([]float64, i.dimension)
+
+	var m moments
+	for j := 0; j < n; j += 1 {
+		i.fill(x)
+		m.add(f(x))
+	}
+
+	return Estimate{
+		Method:        PlainMethod,
+		Value:         m.mean,
+		StandardError: math.Sqrt(m.variance() / float64(n)),
+		Evaluations:   n,
+		PlainVariance: m.variance(),
+	}, nil
+}
+
+// Antithetic averages f over the pairs of points u and 1 - u, n is the count
+// of evaluations (an odd n is rounded down), the variance is reduced for
+// integrands monotone in their coordinates
+func (i *Integrator) Antithetic(f Integrand, n int) (Estimate, error) {
+	if err := validateSamples(n/2, 2); err != nil {
+		return Estimate{}, err
+	}
+
+	x := make([]float64, i.dimension)
+
+	var pairs, values moments
+	for j := 0; j < n/2; j += 1 {
+		i.fill(x)
+		y := f(x)
+
+		for k := range x {
+			x[k] = 1 - x[k]
+		}
+		y2 := f(x)
+
+		pairs.add((y + y2) / 2)
+		values.add(y)
+		values.add(y2)
+	}
+
+	return Estimate{
+		Method:        AntitheticMethod,
+		Value:         pairs.mean,
+		StandardError: math.Sqrt(pairs.variance() / float64(pairs.n)),
+		Evaluations:   values.n,
+		PlainVariance: values.variance(),
+	}, nil
+}
+
+// ControlVariate estimates the integral of f - β(c - μ) with the control c of
+// known integral μ, the optimal coefficient β = cov(f, c)/var(c) is estimated
+// from the same points
+func (i *Integrator) ControlVariate(f Integrand, control Integrand, controlMean float64, n int) (Estimate, error) {
+	if err := validateSamples(n, 3); err != nil {
+		return Estimate{}, err
+	}
+
+	x := make([]float64, i.dimension)
+	ys := make([]float64, n)
+	cs := make([]float64, n)
+
+	var ym, cm moments
+	for j := 0; j < n; j += 1 {
+		i.fill(x)
+
+		ys[j], cs[j] = f(x), control(x)
+		ym.add(ys[j])
+		cm.add(cs[j])
+	}
+
+	covariance := 0.0
+	for j := range ys {
+		covariance += (ys[j] - ym.mean) * (cs[j] - cm.mean)
+	}
+
+	beta := 0.0
+	if cm.m2 > 0 {
+		beta = covariance / cm.m2
+	}
+
+	// one degree of freedom is spent on β
+	residuals := 0.0
+	for j := range ys {
+		r := ys[j] - ym.mean - beta*(cs[j]-cm.mean)
+		residuals += r * r
+	}
+
+	return Estimate{
+		Method:        ControlVariateMethod,
+		Value:         ym.mean - beta*(cm.mean-controlMean),
+		StandardError: math.Sqrt(residuals / float64(n-2) / float64(n)),
+		Evaluations:   n,
+		PlainVariance: ym.variance(),
+	}, nil
+}
+
+// ImportanceSampling estimates the expectation of f under the target density
+// with n points drawn from the proposal, the variance vanishes when the
+// proposal is proportional to |f|·p
+func (i *Integrator) ImportanceSampling(f Integrand, proposal Proposal, n int) (Estimate, error) {
+	if err := validateSamples(n, 2); err != nil {
+		return Estimate{}, err
+	}
+
+	if proposal.Sample == nil || proposal.Weight == nil {
+		return Estimate{}, errors.Wrap(generators.ErrInvalidParameters, "proposal sample and weight must be set")
+	}
+
+	u := make([]float64, i.dimension)
+
+	var m, squares moments
+	for j := 0; j < n; j += 1 {
+		i.fill(u)
+		x := proposal.Sample(u)
+
+		y, w := f(x), proposal.Weight(x)
+		m.add(y * w)
+		// E_q[f²w] = E_p[f²]
+		squares.add(y * y * w)
+	}
+
+	return Estimate{
+		Method:        ImportanceSamplingMethod,
+		Value:         m.mean,
+		StandardError: math.Sqrt(m.variance() / float64(n)),
+		Evaluations:   n,
+		PlainVariance: plainVariance(m.mean, squares.mean),
+	}, nil
+}
+
+// Stratified splits the axis k of the cube into strata[k] equal intervals and
+// draws perStratum uniform points in every cell of the grid, the estimate
+// averages the cell means
+func (i *Integrator) Stratified(f Integrand, strata []int, perStratum int) (Estimate, error) {
+	if len(strata) != i.dimension {
+		return Estimate{}, errors.Wrapf(generators.ErrInvalidParameters, "%d strata counts for dimension %d", len(strata), i.dimension)
+	}
+
+	if err := validateSamples(perStratum, 2); err != nil {
+		return Estimate{}, err
+	}
+
+	cells := 1
+	for k, s := range strata {
+		if s < 1 {
+			return Estimate{}, errors.Wrapf(generators.ErrInvalidParameters, "strata count %d of axis %d must be positive", s, k)
+		}
+
+		cells *= s
+	}
+
+	x := make([]float64, i.dimension)
+	cell := make([]int, i.dimension)
+
+	value, variance, meanSquare := 0.0, 0.0, 0.0
+	for c := 0; c < cells; c += 1 {
+		var m, squares moments
+		for j := 0; j < perStratum; j += 1 {
+			i.fill(x)
+			for k := range x {
+				x[k] = (float64(cell[k]) + x[k]) / float64(strata[k])
+			}
+
+			y := f(x)
+			m.add(y)
+			squares.add(y * y)
+		}
+
+		value += m.mean
+		variance += m.variance() / float64(perStratum)
+		meanSquare += squares.mean
+
+		// the next cell in the lexicographic order
+		for k := range cell {
+			if cell[k] += 1; cell[k] < strata[k] {
+				break
+			}
+
+			cell[k] = 0
+		}
+	}
+
+	value /= float64(cells)
+
+	return Estimate{
+		Method:        StratifiedMethod,
+		Value:         value,
+		StandardError: math.Sqrt(variance) / float64(cells),
+		Evaluations:   cells * perStratum,
+		PlainVariance: plainVariance(value, meanSquare/float64(cells)),
+	}, nil
+}
+
+// LatinHypercube averages replicates independent Latin hypercube designs of
+// n points: every axis is split into n equal intervals and every interval
+// holds one point of the design; the standard error is estimated from the
+// spread of the replicates
+func (i *Integrator) LatinHypercube(f Integrand, n int, replicates int) (Estimate, error) {
+	if err := validateSamples(n, 1); err != nil {
+		return Estimate{}, err
+	}
+
+	if replicates < 2 {
+		return Estimate{}, errors.Wrapf(generators.ErrInvalidParameters, "%d replicates, at least 2 are required", replicates)
+	}
+
+	x := make([]float64, i.dimension)
+	permutations := make([][]int, i.dimension)
+	for k := range permutations {
+		permutations[k] = make([]int, n)
+	}
+
+	var means, squares moments
+	for r := 0; r < replicates; r += 1 {
+		for _, p := range permutations {
+			for j := range p {
+				p[j] = j
+			}
+
+			for j := n - 1; j > 0; j -= 1 {
+				s := i.uniformInt(j + 1)
+				p[j], p[s] = p[s], p[j]
+			}
+		}
+
+		var m moments
+		for j := 0; j < n; j += 1 {
+			for k := range x {
+				x[k] = (float64(permutations[k][j]) + i.g.Float64()) / float64(n)
+			}
+
+			y := f(x)
+			m.add(y)
+			squares.add(y * y)
+		}
+
+		means.add(m.mean)
+	}
+
+	return Estimate{
+		Method:        LatinHypercubeMethod,
+		Value:         means.mean,
+		StandardError: math.Sqrt(means.variance() / float64(replicates)),
+		Evaluations:   n * replicates,
+		PlainVariance: plainVariance(means.mean, squares.mean),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package mc
+
+import (
+	"github.com/Sinu5oid/generators"
+	"github.com/pkg/errors"
+	"math"
+	"testing"
+)
+
+func newTestIntegrator(t *testing.T, dimension int) *Integrator {
+	g, err := generators.NewBitGenerator(generators.NewPCG64Generator(42, 54))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := NewIntegrator(g, dimension)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return i
+}
+
+func checkEstimate(t *testing.T, e Estimate, err error, want float64, minFactor float64) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s: %v", e.Method, err)
+	}
+
+	if !(e.StandardError > 0) {
+		t.Fatalf("%s: standard error %v must be positive", e.Method, e.StandardError)
+	}
+
+	if lower, upper := e.ConfidenceInterval(0.999); want < lower || want > upper {
+		t.Errorf("%v: 99.9%% interval [%v, %v] misses %v", e, lower, upper, want)
+	}
+
+	if f := e.VarianceReductionFactor(); f < minFactor {
+		t.Errorf("%v: variance reduction factor %v, want at least %v", e, f, minFactor)
+	}
+}
+
+func TestIntegrator(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		i := newTestIntegrator(t, 3)
+
+		// ∫ 8xyz over the cube
+		e, err := i.Plain(func(x []float64) float64 { return 8 * x[0] * x[1] * x[2] }, 100000)
+		checkEstimate(t, e, err, 1, 0.95)
+
+		if f := e.VarianceReductionFactor(); f > 1.05 {
+			t.Errorf("plain variance reduction factor %v, want 1", f)
+		}
+
+		if e.Evaluations != 100000 {
+			t.Errorf("%d evaluations, want 100000", e.Evaluations)
+		}
+	})
+
+	exp := func(x []float64) float64 { return math.Exp(x[0]) }
+
+	t.Run("antithetic", func(t *testing.T) {
+		e, err := newTestIntegrator(t, 1).Antithetic(exp, 20001)
+		checkEstimate(t, e, err, math.E-1, 20)
+
+		if e.Evaluations != 20000 {
+			t.Errorf("%d evaluations, want 20000", e.Evaluations)
+		}
+	})
+
+	t.Run("control variate", func(t *testing.T) {
+		e, err := newTestIntegrator(t, 1).ControlVariate(exp, func(x []float64) float64 { return x[0] }, 0.5, 20000)
+		checkEstimate(t, e, err, math.E-1, 30)
+	})
+
+	t.Run("importance sampling", func(t *testing.T) {
+		// ∫ 20exp(-20x) on [0, 1) with the exponential proposal of rate 15
+		// truncated to [0, 1)
+		rate := 15.0
+		mass := -math.Expm1(-rate)
+		proposal := Proposal{
+			Sample: func(u []float64) []float64 {
+				return []float64{-math.Log1p(-u[0]*mass) / rate}
+			},
+			Weight: func(x []float64) float64 {
+				return mass / (rate * math.Exp(-rate*x[0]))
+			},
+		}
+
+		e, err := newTestIntegrator(t, 1).ImportanceSampling(func(x []float64) float64 {
+			return 20 * math.Exp(-20*x[0])
+		}, proposal, 20000)
+		checkEstimate(t, e, err, -math.Expm1(-20), 10)
+	})
+
+	t.Run("stratified", func(t *testing.T) {
+		e, err := newTestIntegrator(t, 2).Stratified(func(x []float64) float64 {
+			return math.Exp(x[0] + x[1])
+		}, []int{10, 10}, 4)
+		checkEstimate(t, e, err, (math.E-1)*(math.E-1), 20)
+
+		if e.Evaluations != 400 {
+			t.Errorf("%d evaluations, want 400", e.Evaluations)
+		}
+	})
+
+	t.Run("latin hypercube", func(t *testing.T) {
+		// additive integrands lose all of their variance but the one of the
+		// remainders of the strata
+		e, err := newTestIntegrator(t, 5).LatinHypercube(func(x []float64) float64 {
+			s := 0.0
+			for _, v := range x {
+				s += v * v
+			}
+
+			return s
+		}, 200, 20)
+		checkEstimate(t, e, err, 5.0/3, 100)
+	})
+
+	t.Run("box", func(t *testing.T) {
+		f := Box(func(x []float64) float64 { return x[0] * x[1] }, []float64{0, 1}, []float64{2, 3})
+
+		e, err := newTestIntegrator(t, 2).Plain(f, 100000)
+		checkEstimate(t, e, err, 8, 0.95)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		i := newTestIntegrator(t, 2)
+		f := func(x []float64) float64 { return x[0] }
+
+		_, err1 := NewIntegrator(nil, 1)
+		_, err2 := NewIntegrator(generators.NewBitGeneratorDefault(), 0)
+		_, err3 := i.Plain(f, 1)
+		_, err4 := i.Antithetic(f, 3)
+		_, err5 := i.Stratified(f, []int{2}, 2)
+		_, err6 := i.Stratified(f, []int{2, 0}, 2)
+		_, err7 := i.LatinHypercube(f, 10, 1)
+		_, err8 := i.ImportanceSampling(f, Proposal{}, 10)
+
+		for k, err := range []error{err1, err2, err3, err4, err5, err6, err7, err8} {
+			if errors.Cause(err) != generators.ErrInvalidParameters {
+				t.Errorf("case %d: got %v, want ErrInvalidParameters", k+1, err)
+			}
+		}
+	})
+}
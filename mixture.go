@@ -0,0 +1,364 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+)
+
+// IntSampler is a discrete distribution generator drawing its values with a
+// common method, it provides the counts of CompoundGenerator
+type IntSampler interface {
+	DistributionGenerator
+	SampleInt() int
+}
+
+type funcIntSampler struct {
+	DistributionGenerator
+	next func() int
+}
+
+func (fs *funcIntSampler) SampleInt() int {
+	return fs.next()
+}
+
+// NewIntSampler adapts a discrete generator to the IntSampler interface, next
+// is its method producing the values, e.g. NewIntSampler(pg, pg.PoissonInt)
+func NewIntSampler(generator DistributionGenerator, next func() int) IntSampler {
+	return &funcIntSampler{DistributionGenerator: generator, next: next}
+}
+
+// samplerSource returns the generator adapted by NewSampler or NewIntSampler,
+// the state of the adapter is the state of the generator
+func samplerSource(s interface{}) interface{} {
+	switch fs := s.(type) {
+	case *funcSampler:
+		return fs.DistributionGenerator
+	case *funcIntSampler:
+		return fs.DistributionGenerator
+	default:
+		return s
+	}
+}
+
+// MixtureGenerator draws a component with probability proportional to its
+// weight and returns the value of the component, the component is selected
+// with Walker's alias method with a single uniform value
+type MixtureGenerator struct {
+	name       GeneratorName
+	g          Float64Generator
+	components []Sampler
+	weights    []float64
+
+	// probability and alias are the tables of the alias method
+	probability []float64
+	alias       []int
+}
+
+func (mg *MixtureGenerator) Name() string {
+	return string(mg.name)
+}
+
+func (mg *MixtureGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	components := make([]json.RawMessage, len(mg.components))
+	for i, c := range mg.components {
+		components[i] = json.RawMessage(c.String())
+	}
+
+	d["distributionName"] = mg.name
+	d["weights"] = mg.weights
+	d["components"] = components
+
+	describeSource(d, "source", mg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewMixtureGeneratorDefault(components []Sampler, weights []float64) (*MixtureGenerator, error) {
+	return NewMixtureGenerator(newDefaultGenerator(), components, weights)
+}
+
+// NewMixtureGenerator builds the mixture of the components, the weights must
+// be non-negative and are normalized, generator selects the components
+func NewMixtureGenerator(generator Float64Generator, components []Sampler, weights []float64) (*MixtureGenerator, error) {
+	if len(components) == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "no components")
+	}
+
+	if len(weights) != len(components) {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%d weights for %d components", len(weights), len(components))
+	}
+
+	total := 0.0
+	for i, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 0) {
+			return nil, errors.Wrapf(ErrInvalidParameters, "weight %d must be non-negative and finite", i)
+		}
+
+		if components[i] == nil {
+			return nil, errors.Wrapf(ErrInvalidParameters, "component %d is nil", i)
+		}
+
+		total += w
+	}
+
+	if !(total > 0) {
+		return nil, errors.Wrap(ErrInvalidParameters, "weights sum to 0")
+	}
+
+	mg := &MixtureGenerator{
+		name:       Mixture,
+		g:          generator,
+		components: make([]Sampler, len(components)),
+		weights:    make([]float64, len(weights)),
+	}
+
+	copy(mg.components, components)
+	for i, w := range weights {
+		mg.weights[i] = w / total
+	}
+
+	mg.probability, mg.alias = aliasTables(mg.weights)
+
+	return mg, nil
+}
+
+// aliasTables builds the tables of the alias method with Vose's algorithm
+func aliasTables(weights []float64) ([]float64, []int) {
+	n := len(weights)
+	probability := make([]float64, n)
+	alias := make([]int, n)
+
+	scaled := make([]float64, n)
+	small := make([]int, 0, n)
+	large := make([]int, 0, n)
+
+	for i, w := range weights {
+		scaled[i] = w * float64(n)
+
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+
+		probability[s] = scaled[s]
+		alias[s] = l
+
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+
+	// the rest is 1 up to rounding
+	for _, i := range append(small, large...) {
+		probability[i] = 1
+		alias[i] = i
+	}
+
+	return probability, alias
+}
+
+// Components returns the count of the components
+func (mg *MixtureGenerator) Components() int {
+	return len(mg.components)
+}
+
+// SampleComponent returns the index of the selected component and its value
+func (mg *MixtureGenerator) SampleComponent() (int, float64) {
+	n := len(mg.components)
+
+	u := mg.g.Float64() * float64(n)
+	i := int(u)
+	if i >= n {
+		i = n - 1
+	}
+
+	if u-float64(i) >= mg.probability[i] {
+		i = mg.alias[i]
+	}
+
+	return i, mg.components[i].Sample()
+}
+
+func (mg *MixtureGenerator) Sample() float64 {
+	_, x := mg.SampleComponent()
+
+	return x
+}
+
+// CompoundGenerator produces random sums X_1 + ... + X_N, the count N and the
+// independent summands X_i are drawn from the given samplers, e.g. the
+// compound Poisson distribution of the total of exponential claims
+type CompoundGenerator struct {
+	name    GeneratorName
+	count   IntSampler
+	summand Sampler
+}
+
+func (cg *CompoundGenerator) Name() string {
+	return string(cg.name)
+}
+
+func (cg *CompoundGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = cg.name
+	d["count"] = json.RawMessage(cg.count.String())
+	d["summand"] = json.RawMessage(cg.summand.String())
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewCompoundGenerator(count IntSampler, summand Sampler) (*CompoundGenerator, error) {
+	if count == nil || summand == nil {
+		return nil, errors.Wrap(ErrInvalidParameters, "count and summand must be set")
+	}
+
+	return &CompoundGenerator{name: Compound, count: count, summand: summand}, nil
+}
+
+// NewCompoundPoissonGenerator builds the sum of a Poisson count with mean
+// lambda of summands, generator provides the count
+func NewCompoundPoissonGenerator(generator Float64Generator, lambda float64, summand Sampler) (*CompoundGenerator, error) {
+	pg, err := NewPoissonGenerator(generator, lambda)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCompoundGenerator(NewIntSampler(pg, pg.PoissonInt), summand)
+}
+
+func (cg *CompoundGenerator) Sample() float64 {
+	n := cg.count.SampleInt()
+
+	sum := 0.0
+	for i := 0; i < n; i += 1 {
+		sum += cg.summand.Sample()
+	}
+
+	return sum
+}
+
+// the samplers carry the methods producing the values, the states of
+// mixtures and compounds are restored in place into a generator built with
+// the same structure
+
+type mixtureState struct {
+	Name       GeneratorName     `json:"distributionName"`
+	Weights    []float64         `json:"weights"`
+	Source     json.RawMessage   `json:"source"`
+	Components []json.RawMessage `json:"components"`
+}
+
+func (mg *MixtureGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(mg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]json.RawMessage, len(mg.components))
+	for i, c := range mg.components {
+		if components[i], err = marshalSource(samplerSource(c)); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(mixtureState{Name: Mixture, Weights: mg.weights, Source: src, Components: components})
+}
+
+func (mg *MixtureGenerator) UnmarshalBinary(data []byte) error {
+	var s mixtureState
+	if err := unmarshalState(data, Mixture, &s); err != nil {
+		return err
+	}
+
+	if len(mg.components) == 0 {
+		return errors.Wrap(ErrStateUnsupported, "components are not set")
+	}
+
+	if len(s.Components) != len(mg.components) {
+		return errors.Wrapf(ErrInvalidState, "%d components for %d components", len(s.Components), len(mg.components))
+	}
+
+	g, err := unmarshalFloat64Source(mg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	for i, c := range mg.components {
+		if _, err := unmarshalSource(samplerSource(c), s.Components[i]); err != nil {
+			return err
+		}
+	}
+
+	restored, err := NewMixtureGenerator(g, mg.components, s.Weights)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*mg = *restored
+
+	return nil
+}
+
+type compoundState struct {
+	Name    GeneratorName   `json:"distributionName"`
+	Count   json.RawMessage `json:"count"`
+	Summand json.RawMessage `json:"summand"`
+}
+
+func (cg *CompoundGenerator) MarshalBinary() ([]byte, error) {
+	count, err := marshalSource(samplerSource(cg.count))
+	if err != nil {
+		return nil, err
+	}
+
+	summand, err := marshalSource(samplerSource(cg.summand))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(compoundState{Name: Compound, Count: count, Summand: summand})
+}
+
+func (cg *CompoundGenerator) UnmarshalBinary(data []byte) error {
+	var s compoundState
+	if err := unmarshalState(data, Compound, &s); err != nil {
+		return err
+	}
+
+	if cg.count == nil || cg.summand == nil {
+		return errors.Wrap(ErrStateUnsupported, "count and summand are not set")
+	}
+
+	if _, err := unmarshalSource(samplerSource(cg.count), s.Count); err != nil {
+		return err
+	}
+
+	if _, err := unmarshalSource(samplerSource(cg.summand), s.Summand); err != nil {
+		return err
+	}
+
+	cg.name = Compound
+
+	return nil
+}
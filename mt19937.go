@@ -0,0 +1,131 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+const (
+	mtN         = 624
+	mtM         = 397
+	mtMatrixA   = 0x9908b0df
+	mtUpperMask = 0x80000000
+	mtLowerMask = 0x7fffffff
+)
+
+// MT19937Generator is Matsumoto and Nishimura's 32-bit Mersenne Twister
+type MT19937Generator struct {
+	name  GeneratorName
+	seed  uint32
+	mt    [mtN]uint32
+	index int
+}
+
+func NewMT19937Generator(seed uint32) *MT19937Generator {
+	g := &MT19937Generator{name: MT19937}
+	g.Seed(int64(seed))
+
+	return g
+}
+
+func (g *MT19937Generator) Name() string {
+	return string(g.name)
+}
+
+func (g *MT19937Generator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Seed initializes the state with init_genrand, only the low 32 bits are used
+func (g *MT19937Generator) Seed(seed int64) {
+	g.seed = uint32(seed)
+	g.mt[0] = g.seed
+
+	for i := 1; i < mtN; i += 1 {
+		g.mt[i] = 1812433253*(g.mt[i-1]^(g.mt[i-1]>>30)) + uint32(i)
+	}
+
+	g.index = mtN
+}
+
+func (g *MT19937Generator) twist() {
+	for i := 0; i < mtN; i += 1 {
+		y := g.mt[i]&mtUpperMask | g.mt[(i+1)%mtN]&mtLowerMask
+		v := g.mt[(i+mtM)%mtN] ^ (y >> 1)
+
+		if y&1 != 0 {
+			v ^= mtMatrixA
+		}
+
+		g.mt[i] = v
+	}
+
+	g.index = 0
+}
+
+func (g *MT19937Generator) Uint32() uint32 {
+	if g.index >= mtN {
+		g.twist()
+	}
+
+	y := g.mt[g.index]
+	g.index += 1
+
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+
+	return y
+}
+
+func (g *MT19937Generator) Uint64() uint64 {
+	return uint64(g.Uint32())<<32 | uint64(g.Uint32())
+}
+
+func (g *MT19937Generator) Int() int {
+	return int(g.Uint32())
+}
+
+func (g *MT19937Generator) Bits() uint {
+	return 32
+}
+
+type mt19937State struct {
+	Name  GeneratorName `json:"distributionName"`
+	Seed  uint32        `json:"seed"`
+	State []uint32      `json:"state"`
+	Index int           `json:"index"`
+}
+
+func (g *MT19937Generator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(mt19937State{Name: MT19937, Seed: g.seed, State: g.mt[:], Index: g.index})
+}
+
+func (g *MT19937Generator) UnmarshalBinary(data []byte) error {
+	var s mt19937State
+	if err := unmarshalState(data, MT19937, &s); err != nil {
+		return err
+	}
+
+	if len(s.State) != mtN || s.Index < 0 || s.Index > mtN {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	g.name = MT19937
+	g.seed = s.Seed
+	copy(g.mt[:], s.State)
+	g.index = s.Index
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// covarianceTolerance is the relative tolerance used to accept asymmetric
+// (rounding) and slightly negative eigenvalues of a covariance matrix
+const covarianceTolerance = 1e-10
+
+type MultivariateNormFloat64Generator interface {
+	MultivariateNormFloat64s() []float64
+}
+
+// MultivariateNormalGenerator produces samples of the N-dimensional normal
+// distribution N(mean, covariance) as mean + A z, where z is a vector of
+// independent standard normal values and A Aᵀ = covariance
+//
+// A is the Cholesky factor for positive definite matrices, semi-definite
+// matrices are factored by the eigendecomposition A = V sqrt(Λ)
+type MultivariateNormalGenerator struct {
+	name GeneratorName
+	g    NormFloat64Generator
+
+	mean       []float64
+	covariance [][]float64
+	factor     *mat.Dense
+}
+
+func (mg *MultivariateNormalGenerator) Name() string {
+	return string(mg.name)
+}
+
+func (mg *MultivariateNormalGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = mg.name
+	d["mean"] = mg.mean
+	d["covariance"] = mg.covariance
+
+	describeSource(d, "source", mg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewMultivariateNormalGeneratorDefault(mean []float64, covariance [][]float64) (*MultivariateNormalGenerator, error) {
+	return NewMultivariateNormalGenerator(NewNormalGeneratorDefault(), mean, covariance)
+}
+
+// NewMultivariateNormalGenerator validates the covariance matrix: it must be
+// square, match the mean vector, be symmetric and positive semi-definite;
+// generator must produce standard normal values
+func NewMultivariateNormalGenerator(
+	generator NormFloat64Generator,
+	mean []float64,
+	covariance [][]float64,
+) (*MultivariateNormalGenerator, error) {
+	factor, err := covarianceFactor(mean, covariance)
+	if err != nil {
+		return nil, err
+	}
+
+	mg := &MultivariateNormalGenerator{
+		name:       MultivariateNormal,
+		g:          generator,
+		mean:       make([]float64, len(mean)),
+		covariance: make([][]float64, len(covariance)),
+		factor:     factor,
+	}
+
+	copy(mg.mean, mean)
+	for i, row := range covariance {
+		mg.covariance[i] = make([]float64, len(row))
+		copy(mg.covariance[i], row)
+	}
+
+	return mg, nil
+}
+
+// Dimension returns the count of components of every sample
+func (mg *MultivariateNormalGenerator) Dimension() int {
+	return len(mg.mean)
+}
+
+func (mg *MultivariateNormalGenerator) MultivariateNormFloat64s() []float64 {
+	n := len(mg.mean)
+
+	z := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i += 1 {
+		z.SetVec(i, mg.g.NormFloat64())
+	}
+
+	x := mat.NewVecDense(n, nil)
+	x.MulVec(mg.factor, z)
+
+	sample := make([]float64, n)
+	for i := range sample {
+		sample[i] = x.AtVec(i) + mg.mean[i]
+	}
+
+	return sample
+}
+
+// covarianceFactor validates the parameters and returns A with A Aᵀ = covariance
+func covarianceFactor(mean []float64, covariance [][]float64) (*mat.Dense, error) {
+	n := len(mean)
+
+	if n == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "mean vector is empty")
+	}
+
+	if len(covariance) != n {
+		return nil, errors.Wrapf(ErrInvalidParameters, "covariance matrix has %d rows, expected %d", len(covariance), n)
+	}
+
+	scale := 0.0
+	for i, row := range covariance {
+		if len(row) != n {
+			return nil, errors.Wrapf(ErrInvalidParameters, "covariance matrix row %d has %d columns, expected %d", i, len(row), n)
+		}
+
+		if math.IsNaN(mean[i]) || math.IsInf(mean[i], 0) {
+			return nil, errors.Wrapf(ErrInvalidParameters, "mean %d is not finite", i)
+		}
+
+		for _, v := range row {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, errors.Wrapf(ErrInvalidParameters, "covariance matrix row %d is not finite", i)
+			}
+		}
+
+		if row[i] < 0 {
+			return nil, errors.Wrapf(ErrInvalidParameters, "variance %d is negative", i)
+		}
+
+		scale = math.Max(scale, row[i])
+	}
+
+	sym := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i += 1 {
+		for j := i; j < n; j += 1 {
+			if math.Abs(covariance[i][j]-covariance[j][i]) > covarianceTolerance*math.Max(scale, 1) {
+				return nil, errors.Wrapf(ErrInvalidParameters, "covariance matrix is not symmetric at (%d, %d)", i, j)
+			}
+
+			sym.SetSym(i, j, (covariance[i][j]+covariance[j][i])/2)
+		}
+	}
+
+	var chol mat.Cholesky
+	if chol.Factorize(sym) {
+		var l mat.TriDense
+		chol.LTo(&l)
+
+		return mat.DenseCopyOf(&l), nil
+	}
+
+	// semi-definite matrix, the factor is V sqrt(Λ)
+	var eigen mat.EigenSym
+	if !eigen.Factorize(sym, true) {
+		return nil, errors.Wrap(ErrInvalidParameters, "covariance matrix eigendecomposition failed")
+	}
+
+	values := eigen.Values(nil)
+	var vectors mat.Dense
+	eigen.VectorsTo(&vectors)
+
+	for j, v := range values {
+		if v < -covarianceTolerance*math.Max(scale, 1) {
+			return nil, errors.Wrapf(ErrInvalidParameters, "covariance matrix is not positive semi-definite, eigenvalue %g", v)
+		}
+
+		s := math.Sqrt(math.Max(v, 0))
+		for i := 0; i < n; i += 1 {
+			vectors.Set(i, j, vectors.At(i, j)*s)
+		}
+	}
+
+	return &vectors, nil
+}
+
+type multivariateNormalState struct {
+	Name       GeneratorName   `json:"distributionName"`
+	Mean       []float64       `json:"mean"`
+	Covariance [][]float64     `json:"covariance"`
+	Source     json.RawMessage `json:"source"`
+}
+
+func (mg *MultivariateNormalGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(mg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(multivariateNormalState{
+		Name:       MultivariateNormal,
+		Mean:       mg.mean,
+		Covariance: mg.covariance,
+		Source:     src,
+	})
+}
+
+func (mg *MultivariateNormalGenerator) UnmarshalBinary(data []byte) error {
+	var s multivariateNormalState
+	if err := unmarshalState(data, MultivariateNormal, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalNormFloat64Source(mg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewMultivariateNormalGenerator(g, s.Mean, s.Covariance)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*mg = *restored
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	pcgMultiplier64 = 6364136223846793005
+
+	pcgMultiplier128Hi = 2549297995355413924
+	pcgMultiplier128Lo = 4865540595714422341
+)
+
+// PCG32Generator is the PCG XSH RR 64/32 generator (64-bit state, 32-bit output)
+type PCG32Generator struct {
+	name     GeneratorName
+	seed     uint64
+	sequence uint64
+	state    uint64
+	inc      uint64
+}
+
+func NewPCG32Generator(seed uint64, sequence uint64) *PCG32Generator {
+	g := &PCG32Generator{name: PCG32}
+	g.reset(seed, sequence)
+
+	return g
+}
+
+func (g *PCG32Generator) reset(seed uint64, sequence uint64) {
+	g.seed = seed
+	g.sequence = sequence
+	g.state = 0
+	g.inc = sequence<<1 | 1
+	g.Uint32()
+	g.state += seed
+	g.Uint32()
+}
+
+func (g *PCG32Generator) Name() string {
+	return string(g.name)
+}
+
+func (g *PCG32Generator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+	d["sequence"] = g.sequence
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *PCG32Generator) Uint32() uint32 {
+	old := g.state
+	g.state = old*pcgMultiplier64 + g.inc
+
+	xorShifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := int(old >> 59)
+
+	return bits.RotateLeft32(xorShifted, -rot)
+}
+
+func (g *PCG32Generator) Uint64() uint64 {
+	return uint64(g.Uint32())<<32 | uint64(g.Uint32())
+}
+
+func (g *PCG32Generator) Int() int {
+	return int(g.Uint32())
+}
+
+func (g *PCG32Generator) Bits() uint {
+	return 32
+}
+
+func (g *PCG32Generator) Seed(seed int64) {
+	g.reset(uint64(seed), g.sequence)
+}
+
+// PCG64Generator is the PCG XSL RR 128/64 generator (128-bit state, 64-bit output)
+type PCG64Generator struct {
+	name     GeneratorName
+	seed     uint64
+	sequence uint64
+	hi, lo   uint64
+	incHi    uint64
+	incLo    uint64
+}
+
+func NewPCG64Generator(seed uint64, sequence uint64) *PCG64Generator {
+	g := &PCG64Generator{name: PCG64}
+	g.reset(seed, sequence)
+
+	return g
+}
+
+func (g *PCG64Generator) reset(seed uint64, sequence uint64) {
+	g.seed = seed
+	g.sequence = sequence
+	g.hi, g.lo = 0, 0
+	g.incHi, g.incLo = sequence>>63, sequence<<1|1
+	g.step()
+
+	var carry uint64
+	g.lo, carry = bits.Add64(g.lo, seed, 0)
+	g.hi += carry
+	g.step()
+}
+
+func (g *PCG64Generator) step() {
+	hi, lo := bits.Mul64(g.lo, pcgMultiplier128Lo)
+	hi += g.hi*pcgMultiplier128Lo + g.lo*pcgMultiplier128Hi
+
+	var carry uint64
+	g.lo, carry = bits.Add64(lo, g.incLo, 0)
+	g.hi, _ = bits.Add64(hi, g.incHi, carry)
+}
+
+func (g *PCG64Generator) Name() string {
+	return string(g.name)
+}
+
+func (g *PCG64Generator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+	d["sequence"] = g.sequence
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *PCG64Generator) Uint64() uint64 {
+	g.step()
+
+	return bits.RotateLeft64(g.hi^g.lo, -int(g.hi>>58))
+}
+
+func (g *PCG64Generator) Int() int {
+	return int(g.Uint64() >> 1)
+}
+
+func (g *PCG64Generator) Bits() uint {
+	return 63
+}
+
+func (g *PCG64Generator) Seed(seed int64) {
+	g.reset(uint64(seed), g.sequence)
+}
+
+type pcgState struct {
+	Name     GeneratorName `json:"distributionName"`
+	Seed     uint64        `json:"seed"`
+	Sequence uint64        `json:"sequence"`
+	State    [2]uint64     `json:"state"`
+}
+
+func (g *PCG32Generator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(pcgState{Name: PCG32, Seed: g.seed, Sequence: g.sequence, State: [2]uint64{0, g.state}})
+}
+
+func (g *PCG32Generator) UnmarshalBinary(data []byte) error {
+	var s pcgState
+	if err := unmarshalState(data, PCG32, &s); err != nil {
+		return err
+	}
+
+	g.name = PCG32
+	g.seed = s.Seed
+	g.sequence = s.Sequence
+	g.state = s.State[1]
+	g.inc = s.Sequence<<1 | 1
+
+	return nil
+}
+
+func (g *PCG64Generator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(pcgState{Name: PCG64, Seed: g.seed, Sequence: g.sequence, State: [2]uint64{g.hi, g.lo}})
+}
+
+func (g *PCG64Generator) UnmarshalBinary(data []byte) error {
+	var s pcgState
+	if err := unmarshalState(data, PCG64, &s); err != nil {
+		return err
+	}
+
+	g.name = PCG64
+	g.seed = s.Seed
+	g.sequence = s.Sequence
+	g.hi, g.lo = s.State[0], s.State[1]
+	g.incHi, g.incLo = s.Sequence>>63, s.Sequence<<1|1
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package generators
+
+import (
+	"github.com/pkg/errors"
+	"math/bits"
+)
+
+var (
+	ErrInvalidParameters = errors.New("invalid generator parameters")
+	ErrShortPeriod       = errors.New("generator period is not maximal")
+)
+
+// periodSearchLimit bounds the brute force cycle search used when the period
+// can't be derived analytically
+const periodSearchLimit = 1 << 22
+
+// PeriodAnalysis describes the quality of congruential generator parameters
+type PeriodAnalysis struct {
+	// FullPeriod reports whether the Hull–Dobell conditions hold, i.e. the
+	// period equals the modulus for every initial value
+	FullPeriod bool
+	// Period is the period of the sequence started from the initial value
+	Period uint64
+	// Exact is false when Period is only an upper bound or when MaximalPeriod
+	// can't be derived
+	Exact bool
+	// MaximalPeriod is the longest period attainable for this kind of generator,
+	// 0 when the modulus can't be factored
+	MaximalPeriod uint64
+	// Warnings lists known weaknesses of the parameters
+	Warnings []string
+}
+
+// NewCongruentialGeneratorChecked validates the parameters before building the
+// generator, parameters which don't reach the maximal period for their kind
+// of generator (mixed or multiplicative) are rejected with ErrShortPeriod
+//
+// the analysis is returned along with ErrShortPeriod, so the caller can
+// inspect the actual period and the warnings
+func NewCongruentialGeneratorChecked(
+	modulus int,
+	multiplier int,
+	additiveComponent int,
+	initialValue int,
+) (*CongruentialGenerator, PeriodAnalysis, error) {
+	analysis, err := AnalyzeCongruential(modulus, multiplier, additiveComponent, initialValue)
+	if err != nil {
+		return nil, analysis, err
+	}
+
+	if !analysis.Exact || analysis.Period < analysis.MaximalPeriod {
+		return nil, analysis, errors.Wrapf(
+			ErrShortPeriod,
+			"period %d (exact: %t), maximal period %d",
+			analysis.Period,
+			analysis.Exact,
+			analysis.MaximalPeriod,
+		)
+	}
+
+	return NewCongruentialGenerator(modulus, multiplier, additiveComponent, initialValue), analysis, nil
+}
+
+// AnalyzeCongruential checks the Hull–Dobell conditions for x -> (multiplier*x + additiveComponent) mod modulus
+// and computes the period of the sequence started from initialValue
+func AnalyzeCongruential(modulus int, multiplier int, additiveComponent int, initialValue int) (PeriodAnalysis, error) {
+	analysis := PeriodAnalysis{}
+
+	switch {
+	case modulus < 2:
+		return analysis, errors.Wrap(ErrInvalidParameters, "modulus is less than 2")
+	case multiplier <= 0 || multiplier >= modulus:
+		return analysis, errors.Wrap(ErrInvalidParameters, "multiplier is out of range (0, modulus)")
+	case additiveComponent < 0 || additiveComponent >= modulus:
+		return analysis, errors.Wrap(ErrInvalidParameters, "additive component is out of range [0, modulus)")
+	case initialValue < 0 || initialValue >= modulus:
+		return analysis, errors.Wrap(ErrInvalidParameters, "initial value is out of range [0, modulus)")
+	}
+
+	m, a, c, x := uint64(modulus), uint64(multiplier), uint64(additiveComponent), uint64(initialValue)
+	powerOfTwo := m&(m-1) == 0
+
+	analysis.Warnings = congruentialWarnings(m, a, c, powerOfTwo)
+	analysis.FullPeriod = c != 0 && hullDobell(m, a, c)
+
+	switch {
+	case isPrime(m) && a != 1:
+		// Hull–Dobell would require a = 1 for a prime modulus, otherwise
+		// the fixed point of the map is never left or never reached
+		analysis.MaximalPeriod = m - 1
+	case c != 0:
+		analysis.MaximalPeriod = m
+	case powerOfTwo && m >= 8:
+		analysis.MaximalPeriod = m / 4
+	default:
+		// the order of a unit modulo m divides Carmichael's function λ(m)
+		// and some unit reaches it
+		analysis.MaximalPeriod, _ = carmichael(m)
+	}
+
+	switch {
+	case analysis.FullPeriod:
+		analysis.Period, analysis.Exact = m, true
+	case isPrime(m) && a != 1:
+		// x -> a*x + c has the fixed point c/(1-a), the distance to it is
+		// multiplied by a on every step, so the period is the order of a
+		fixed := mulMod(c, invMod(m+1-a, m), m)
+		if x == fixed {
+			analysis.Period, analysis.Exact = 1, true
+		} else {
+			analysis.Period, analysis.Exact = multiplicativeOrder(a, m)
+		}
+	case powerOfTwo && c == 0 && a%2 == 1 && x%2 == 1 && m >= 8:
+		analysis.Period, analysis.Exact = powerOfTwoOrder(a, m), true
+	default:
+		analysis.Period, analysis.Exact = searchPeriod(m, a, c, x)
+	}
+
+	if analysis.MaximalPeriod == 0 {
+		analysis.Exact = false
+	}
+
+	return analysis, nil
+}
+
+func congruentialWarnings(m, a, c uint64, powerOfTwo bool) []string {
+	warnings := make([]string, 0)
+
+	if powerOfTwo {
+		warnings = append(warnings, "power-of-two modulus: bit k of the output has period at most 2^(k+1), low bits are not random")
+
+		if c == 0 {
+			warnings = append(warnings, "multiplicative generator with power-of-two modulus: the lowest bit never changes")
+		}
+
+		if a%2 == 0 {
+			warnings = append(warnings, "even multiplier with power-of-two modulus: the low bits of the state are lost, the sequence falls into a short cycle")
+		}
+	}
+
+	if a == 1 {
+		warnings = append(warnings, "multiplier 1 yields an arithmetic progression")
+	} else if hi, lo := bits.Mul64(a, a); hi == 0 && lo < m {
+		warnings = append(warnings, "multiplier is less than sqrt(modulus): consecutive values are strongly correlated")
+	}
+
+	return warnings
+}
+
+// hullDobell reports whether the mixed generator has full period:
+// c and m are coprime, a-1 is divisible by every prime factor of m and
+// a-1 is divisible by 4 if m is
+func hullDobell(m, a, c uint64) bool {
+	if gcd(c, m) != 1 {
+		return false
+	}
+
+	if m%4 == 0 && (a-1)%4 != 0 {
+		return false
+	}
+
+	factors, complete := primeFactors(m)
+	if !complete {
+		// the conditions can't be verified
+		return false
+	}
+
+	for _, p := range factors {
+		if (a-1)%p != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// multiplicativeOrder returns the order of a modulo the prime p, the second
+// value is false when p-1 could not be factored, p-1 is returned as a bound
+func multiplicativeOrder(a, p uint64) (uint64, bool) {
+	factors, complete := primeFactors(p - 1)
+	if !complete {
+		return p - 1, false
+	}
+
+	order := p - 1
+	for _, q := range factors {
+		for order%q == 0 && powMod(a, order/q, p) == 1 {
+			order /= q
+		}
+	}
+
+	return order, true
+}
+
+// carmichael returns λ(m), the exponent of the multiplicative group modulo m:
+// λ(2) = 1, λ(4) = 2, λ(2^k) = 2^(k-2), λ(p^k) = p^(k-1)(p-1) for odd primes
+// and the least common multiple over the prime powers dividing m, the second
+// value is false when m could not be factored
+func carmichael(m uint64) (uint64, bool) {
+	factors, complete := primeFactors(m)
+	if !complete {
+		return 0, false
+	}
+
+	lambda := uint64(1)
+	for _, p := range factors {
+		power := uint64(1)
+		for m%p == 0 {
+			m /= p
+			power *= p
+		}
+
+		l := power / p * (p - 1)
+		if p == 2 && power >= 8 {
+			l = power / 4
+		}
+
+		lambda = lambda / gcd(lambda, l) * l
+	}
+
+	return lambda, true
+}
+
+// powerOfTwoOrder returns the order of odd a modulo m = 2^k
+func powerOfTwoOrder(a, m uint64) uint64 {
+	order := uint64(1)
+
+	for v := a & (m - 1); v != 1; v = v * v & (m - 1) {
+		order <<= 1
+	}
+
+	return order
+}
+
+// searchPeriod runs Brent's cycle detection for at most periodSearchLimit
+// steps, if no cycle is found the modulus is returned as an upper bound
+func searchPeriod(m, a, c, x uint64) (uint64, bool) {
+	step := func(v uint64) uint64 {
+		return addMod(mulMod(a, v, m), c, m)
+	}
+
+	power, period := uint64(1), uint64(1)
+	tortoise, hare := x, step(x)
+
+	for steps := 0; tortoise != hare; steps += 1 {
+		if steps >= periodSearchLimit {
+			return m, false
+		}
+
+		if power == period {
+			tortoise = hare
+			power <<= 1
+			period = 0
+		}
+
+		hare = step(hare)
+		period += 1
+	}
+
+	return period, true
+}
+
+// primeFactors returns the distinct prime factors of n, found by trial
+// division up to 2^21; the second value is false if a composite cofactor remains
+func primeFactors(n uint64) ([]uint64, bool) {
+	factors := make([]uint64, 0)
+
+	for p := uint64(2); p < 1<<21 && p*p <= n; p += 1 {
+		if n%p != 0 {
+			continue
+		}
+
+		factors = append(factors, p)
+		for n%p == 0 {
+			n /= p
+		}
+	}
+
+	if n == 1 {
+		return factors, true
+	}
+
+	if isPrime(n) {
+		return append(factors, n), true
+	}
+
+	return factors, false
+}
+
+// isPrime is a deterministic Miller–Rabin test for 64-bit integers
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+
+	bases := []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+	for _, p := range bases {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+
+	d, s := n-1, 0
+	for d%2 == 0 {
+		d /= 2
+		s += 1
+	}
+
+	for _, b := range bases {
+		x := powMod(b, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+
+		composite := true
+		for i := 1; i < s; i += 1 {
+			x = mulMod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+
+		if composite {
+			return false
+		}
+	}
+
+	return true
+}
+
+func powMod(b, e, m uint64) uint64 {
+	result := uint64(1) % m
+	b %= m
+
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = mulMod(result, b, m)
+		}
+
+		b = mulMod(b, b, m)
+	}
+
+	return result
+}
+
+// invMod returns the inverse of a modulo the prime p
+func invMod(a, p uint64) uint64 {
+	return powMod(a, p-2, p)
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
@@ -0,0 +1,836 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"math/bits"
+)
+
+// QMCGenerator is a low-discrepancy (quasi-random) sequence of points in the
+// unit cube [0, 1)^d, Float64 returns the coordinates of the successive points
+// one after another, so any Float64Generator consumer runs on QMC points:
+// ExponentialGenerator on a 1-dimensional sequence transforms its points, and
+// a 12-dimensional sequence used as both sources of TwoDimensionalGenerator
+// provides every pair with one point
+//
+// the first point of the sequences without randomization is the origin, the
+// inverse transforms skip the value 0
+type QMCGenerator interface {
+	DistributionGenerator
+	Float64Generator
+	Dimension() int
+	// At returns the coordinate of the point with the given index
+	At(index uint64, coordinate int) float64
+	// Float64s returns the next point, the rest of a partially read point is skipped
+	Float64s() []float64
+}
+
+// QMCRandomization selects the randomization of a QMC sequence, randomized
+// sequences keep their low discrepancy and give unbiased estimates whose
+// error can be measured over independent seeds
+type QMCRandomization string
+
+const (
+	NoRandomization QMCRandomization = ""
+	// RandomShift adds a uniform vector to every point modulo 1 (Cranley–Patterson)
+	RandomShift QMCRandomization = "random-shift"
+	// DigitalShift XORs the binary digits of every coordinate with a random vector
+	DigitalShift QMCRandomization = "digital-shift"
+	// OwenScrambling applies Owen's nested uniform scrambling of the binary
+	// digits, implemented with Laine–Karras hashing as proposed by Burley
+	OwenScrambling QMCRandomization = "owen-scrambling"
+	// DigitPermutation applies independent random permutations to every digit
+	// position of the Halton coordinates
+	DigitPermutation QMCRandomization = "digit-permutation"
+)
+
+// qmcCursor is the position of the sequential reading of a sequence
+type qmcCursor struct {
+	index      uint64
+	coordinate int
+}
+
+func (c *qmcCursor) next(qg QMCGenerator) float64 {
+	x := qg.At(c.index, c.coordinate)
+
+	c.coordinate += 1
+	if c.coordinate == qg.Dimension() {
+		c.index, c.coordinate = c.index+1, 0
+	}
+
+	return x
+}
+
+func (c *qmcCursor) point(qg QMCGenerator) []float64 {
+	if c.coordinate != 0 {
+		c.index, c.coordinate = c.index+1, 0
+	}
+
+	p := make([]float64, qg.Dimension())
+	for k := range p {
+		p[k] = qg.At(c.index, k)
+	}
+
+	c.index += 1
+
+	return p
+}
+
+// qmcRandom returns the generator of the randomization drawn from seed
+func qmcRandom(seed uint64) *BitGenerator {
+	return NewBitGeneratorWithBits(NewSplitMix64Generator(seed), 63)
+}
+
+// randomShifts draws the vector of a random shift
+func randomShifts(seed uint64, dimension int) []float64 {
+	rg := qmcRandom(seed)
+
+	shifts := make([]float64, dimension)
+	for k := range shifts {
+		shifts[k] = rg.Float64()
+	}
+
+	return shifts
+}
+
+// shiftModOne returns x + shift modulo 1
+func shiftModOne(x float64, shift float64) float64 {
+	if x += shift; x >= 1 {
+		x -= 1
+	}
+
+	return x
+}
+
+// HaltonGenerator produces the Halton sequence, coordinate k of the point n
+// is the radical inverse of n in the base of the k-th prime
+type HaltonGenerator struct {
+	name          GeneratorName
+	bases         []uint64
+	randomization QMCRandomization
+	seed          uint64
+
+	shifts []float64
+	// permutations[k][j] permutes the digit j of the coordinate k
+	permutations [][][]uint64
+
+	qmcCursor
+}
+
+func (hg *HaltonGenerator) Name() string {
+	return string(hg.name)
+}
+
+func (hg *HaltonGenerator) String() string {
+	return describeQMC(hg.name, hg.Dimension(), hg.randomization, hg.seed, nil)
+}
+
+// NewHaltonGenerator builds the Halton sequence of the given dimension, the
+// projections on the coordinates with large bases are correlated for the
+// first points, scrambling breaks the correlation
+func NewHaltonGenerator(dimension int) (*HaltonGenerator, error) {
+	if dimension < 1 {
+		return nil, errors.Wrapf(ErrInvalidParameters, "dimension %d must be positive", dimension)
+	}
+
+	return &HaltonGenerator{name: Halton, bases: firstPrimes(dimension)}, nil
+}
+
+// WithRandomization randomizes the sequence with RandomShift or
+// DigitPermutation drawn from seed, NoRandomization restores the plain
+// sequence; panics for other randomizations
+func (hg *HaltonGenerator) WithRandomization(randomization QMCRandomization, seed uint64) *HaltonGenerator {
+	hg.randomization, hg.seed = randomization, seed
+	hg.shifts, hg.permutations = nil, nil
+
+	switch randomization {
+	case NoRandomization:
+		hg.seed = 0
+	case RandomShift:
+		hg.shifts = randomShifts(seed, hg.Dimension())
+	case DigitPermutation:
+		rg := qmcRandom(seed)
+
+		hg.permutations = make([][][]uint64, len(hg.bases))
+		for k, b := range hg.bases {
+			hg.permutations[k] = make([][]uint64, haltonDigits(b))
+
+			for j := range hg.permutations[k] {
+				p := make([]uint64, b)
+				for i := range p {
+					p[i] = uint64(i)
+				}
+
+				for i := len(p) - 1; i > 0; i -= 1 {
+					r := rg.Intn(i + 1)
+					p[i], p[r] = p[r], p[i]
+				}
+
+				hg.permutations[k][j] = p
+			}
+		}
+	default:
+		panic(fmt.Sprintf("randomization %q is not supported by the Halton sequence", randomization))
+	}
+
+	return hg
+}
+
+func (hg *HaltonGenerator) Dimension() int {
+	return len(hg.bases)
+}
+
+func (hg *HaltonGenerator) At(index uint64, coordinate int) float64 {
+	b := hg.bases[coordinate]
+	inverse := 1 / float64(b)
+
+	x, f := 0.0, inverse
+	if hg.permutations != nil {
+		// the permutations apply to the trailing zero digits too
+		for _, p := range hg.permutations[coordinate] {
+			x += float64(p[index%b]) * f
+			index /= b
+			f *= inverse
+		}
+
+		if x >= 1 {
+			x = math.Nextafter(1, 0)
+		}
+	} else {
+		for ; index > 0; index /= b {
+			x += float64(index%b) * f
+			f *= inverse
+		}
+	}
+
+	if hg.shifts != nil {
+		x = shiftModOne(x, hg.shifts[coordinate])
+	}
+
+	return x
+}
+
+func (hg *HaltonGenerator) Float64() float64 {
+	return hg.next(hg)
+}
+
+func (hg *HaltonGenerator) Float64s() []float64 {
+	return hg.point(hg)
+}
+
+// haltonDigits returns the count of base b digits resolved by float64
+func haltonDigits(b uint64) int {
+	return int(math.Ceil(52 / math.Log2(float64(b))))
+}
+
+func firstPrimes(n int) []uint64 {
+	primes := make([]uint64, 0, n)
+
+	for c := uint64(2); len(primes) < n; c += 1 {
+		prime := true
+		for _, p := range primes {
+			if p*p > c {
+				break
+			}
+
+			if c%p == 0 {
+				prime = false
+				break
+			}
+		}
+
+		if prime {
+			primes = append(primes, c)
+		}
+	}
+
+	return primes
+}
+
+// sobolBits is the count of binary digits of the Sobol coordinates, the
+// sequence has 2^sobolBits points
+const sobolBits = 32
+
+// joeKuoDirections are the primitive polynomials (degree s, coefficients a)
+// and the initial direction numbers m of the dimensions 2, 3, ... from Joe and
+// Kuo's new-joe-kuo-6.21201 table, the first dimension is the van der Corput
+// sequence
+var joeKuoDirections = []struct {
+	s uint
+	a uint32
+	m []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+	{3, 2, []uint32{1, 1, 1}},
+	{4, 1, []uint32{1, 1, 3, 3}},
+	{4, 4, []uint32{1, 3, 5, 13}},
+	{5, 2, []uint32{1, 1, 5, 5, 17}},
+	{5, 4, []uint32{1, 1, 5, 5, 5}},
+	{5, 7, []uint32{1, 1, 7, 11, 19}},
+	{5, 11, []uint32{1, 1, 5, 1, 1}},
+	{5, 13, []uint32{1, 1, 1, 3, 11}},
+	{5, 14, []uint32{1, 3, 5, 5, 31}},
+	{6, 1, []uint32{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint32{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint32{1, 3, 1, 13, 27, 49}},
+	{6, 19, []uint32{1, 1, 1, 15, 7, 5}},
+	{6, 22, []uint32{1, 3, 1, 15, 13, 25}},
+	{6, 25, []uint32{1, 1, 5, 5, 19, 61}},
+	{7, 1, []uint32{1, 3, 7, 11, 23, 15, 103}},
+	{7, 4, []uint32{1, 3, 7, 13, 13, 15, 69}},
+	{7, 7, []uint32{1, 1, 3, 13, 7, 35, 63}},
+	{7, 8, []uint32{1, 3, 5, 9, 1, 25, 53}},
+	{7, 14, []uint32{1, 3, 1, 13, 9, 35, 107}},
+	{7, 19, []uint32{1, 3, 1, 5, 27, 61, 31}},
+	{7, 21, []uint32{1, 1, 5, 11, 19, 41, 61}},
+	{7, 28, []uint32{1, 3, 5, 3, 3, 13, 69}},
+	{7, 31, []uint32{1, 1, 7, 13, 1, 19, 1}},
+	{7, 32, []uint32{1, 3, 7, 5, 13, 19, 59}},
+	{7, 37, []uint32{1, 1, 3, 9, 25, 29, 41}},
+	{7, 41, []uint32{1, 3, 5, 13, 23, 1, 55}},
+	{7, 42, []uint32{1, 3, 7, 3, 13, 59, 17}},
+}
+
+// MaxSobolDimension is the count of dimensions with direction numbers
+const MaxSobolDimension = 32
+
+// SobolGenerator produces the Sobol sequence in base 2 with Joe and Kuo's
+// direction numbers, the first 2^m points of every pair of the first
+// dimensions form a net with a low quality parameter, so sample sizes should
+// be powers of 2
+type SobolGenerator struct {
+	name          GeneratorName
+	directions    [][sobolBits]uint32
+	randomization QMCRandomization
+	seed          uint64
+
+	// scramble holds the digital shifts or the scrambling seeds
+	scramble []uint32
+	shifts   []float64
+
+	qmcCursor
+}
+
+func (sg *SobolGenerator) Name() string {
+	return string(sg.name)
+}
+
+func (sg *SobolGenerator) String() string {
+	return describeQMC(sg.name, sg.Dimension(), sg.randomization, sg.seed, nil)
+}
+
+func NewSobolGenerator(dimension int) (*SobolGenerator, error) {
+	if dimension < 1 || dimension > MaxSobolDimension {
+		return nil, errors.Wrapf(ErrInvalidParameters, "dimension %d is out of range [1, %d]", dimension, MaxSobolDimension)
+	}
+
+	directions := make([][sobolBits]uint32, dimension)
+
+	for i := 0; i < sobolBits; i += 1 {
+		directions[0][i] = 1 << (sobolBits - 1 - i)
+	}
+
+	for k := 1; k < dimension; k += 1 {
+		jk := joeKuoDirections[k-1]
+		v := &directions[k]
+
+		for i := uint(0); i < sobolBits; i += 1 {
+			if i < jk.s {
+				v[i] = jk.m[i] << (sobolBits - 1 - i)
+				continue
+			}
+
+			v[i] = v[i-jk.s] ^ v[i-jk.s]>>jk.s
+			for j := uint(1); j < jk.s; j += 1 {
+				if jk.a>>(jk.s-1-j)&1 == 1 {
+					v[i] ^= v[i-j]
+				}
+			}
+		}
+	}
+
+	return &SobolGenerator{name: Sobol, directions: directions}, nil
+}
+
+// WithRandomization randomizes the sequence with RandomShift, DigitalShift or
+// OwenScrambling drawn from seed, NoRandomization restores the plain
+// sequence; panics for other randomizations
+//
+// the digital randomizations keep the net structure of the points
+func (sg *SobolGenerator) WithRandomization(randomization QMCRandomization, seed uint64) *SobolGenerator {
+	sg.randomization, sg.seed = randomization, seed
+	sg.scramble, sg.shifts = nil, nil
+
+	switch randomization {
+	case NoRandomization:
+		sg.seed = 0
+	case RandomShift:
+		sg.shifts = randomShifts(seed, sg.Dimension())
+	case DigitalShift, OwenScrambling:
+		rg := qmcRandom(seed)
+
+		sg.scramble = make([]uint32, sg.Dimension())
+		for k := range sg.scramble {
+			sg.scramble[k] = rg.Uint32()
+		}
+	default:
+		panic(fmt.Sprintf("randomization %q is not supported by the Sobol sequence", randomization))
+	}
+
+	return sg
+}
+
+func (sg *SobolGenerator) Dimension() int {
+	return len(sg.directions)
+}
+
+// At returns the coordinate of the point with the given index, panics if the
+// index exceeds the 2^32 points of the sequence
+func (sg *SobolGenerator) At(index uint64, coordinate int) float64 {
+	if index>>sobolBits != 0 {
+		panic("sobol sequence is exhausted")
+	}
+
+	// the points are ordered by the Gray code of the index
+	gray := index ^ index>>1
+	v := &sg.directions[coordinate]
+
+	x := uint32(0)
+	for i := 0; gray != 0; i, gray = i+1, gray>>1 {
+		if gray&1 == 1 {
+			x ^= v[i]
+		}
+	}
+
+	switch sg.randomization {
+	case DigitalShift:
+		x ^= sg.scramble[coordinate]
+	case OwenScrambling:
+		x = nestedUniformScramble(x, sg.scramble[coordinate])
+	}
+
+	f := float64(x) / (1 << sobolBits)
+	if sg.shifts != nil {
+		f = shiftModOne(f, sg.shifts[coordinate])
+	}
+
+	return f
+}
+
+func (sg *SobolGenerator) Float64() float64 {
+	return sg.next(sg)
+}
+
+func (sg *SobolGenerator) Float64s() []float64 {
+	return sg.point(sg)
+}
+
+// nestedUniformScramble applies Owen's scrambling to the binary digits of x:
+// every digit is flipped depending on the seed and the preceding digits only,
+// after the bits are reversed the Laine–Karras hash has this property
+func nestedUniformScramble(x uint32, seed uint32) uint32 {
+	x = bits.Reverse32(x)
+
+	x += seed
+	x ^= x * 0x6c50b47c
+	x ^= x * 0xb82f1e52
+	x ^= x * 0xc7afe638
+	x ^= x * 0x8d22f6e6
+
+	return bits.Reverse32(x)
+}
+
+// LatticeGenerator produces the points of the rank-1 lattice rule
+// x_n = frac(n·z/N), n = 0, ..., N-1, with the generating vector z; the
+// sequence repeats after N points, so the sample size is the count of points
+type LatticeGenerator struct {
+	name          GeneratorName
+	points        uint64
+	vector        []uint64
+	randomization QMCRandomization
+	seed          uint64
+
+	shifts []float64
+
+	qmcCursor
+}
+
+func (lg *LatticeGenerator) Name() string {
+	return string(lg.name)
+}
+
+func (lg *LatticeGenerator) String() string {
+	return describeQMC(lg.name, lg.Dimension(), lg.randomization, lg.seed, map[string]interface{}{
+		"points":           lg.points,
+		"generatingVector": lg.vector,
+	})
+}
+
+// NewLatticeGenerator builds the lattice rule with the given count of points
+// and generating vector, the components should be coprime with the count
+func NewLatticeGenerator(points uint64, generatingVector []uint64) (*LatticeGenerator, error) {
+	if points == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "count of points must be positive")
+	}
+
+	if len(generatingVector) == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "generating vector is empty")
+	}
+
+	lg := &LatticeGenerator{name: Lattice, points: points, vector: make([]uint64, len(generatingVector))}
+	for k, z := range generatingVector {
+		lg.vector[k] = z % points
+	}
+
+	return lg, nil
+}
+
+// NewKorobovLatticeGenerator builds the lattice rule with the generating
+// vector (1, a, a², ...) modulo the count of points
+func NewKorobovLatticeGenerator(points uint64, a uint64, dimension int) (*LatticeGenerator, error) {
+	if points == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "count of points must be positive")
+	}
+
+	if dimension < 1 {
+		return nil, errors.Wrapf(ErrInvalidParameters, "dimension %d must be positive", dimension)
+	}
+
+	vector := make([]uint64, dimension)
+	z := uint64(1) % points
+	for k := range vector {
+		vector[k] = z
+		z = mulMod(z, a%points, points)
+	}
+
+	return NewLatticeGenerator(points, vector)
+}
+
+// WithRandomization randomizes the rule with RandomShift drawn from seed,
+// NoRandomization restores the plain rule; panics for other randomizations
+func (lg *LatticeGenerator) WithRandomization(randomization QMCRandomization, seed uint64) *LatticeGenerator {
+	lg.randomization, lg.seed = randomization, seed
+	lg.shifts = nil
+
+	switch randomization {
+	case NoRandomization:
+		lg.seed = 0
+	case RandomShift:
+		lg.shifts = randomShifts(seed, lg.Dimension())
+	default:
+		panic(fmt.Sprintf("randomization %q is not supported by lattice rules", randomization))
+	}
+
+	return lg
+}
+
+func (lg *LatticeGenerator) Dimension() int {
+	return len(lg.vector)
+}
+
+// Points returns the count of points of the rule
+func (lg *LatticeGenerator) Points() uint64 {
+	return lg.points
+}
+
+func (lg *LatticeGenerator) At(index uint64, coordinate int) float64 {
+	x := float64(mulMod(index%lg.points, lg.vector[coordinate], lg.points)) / float64(lg.points)
+
+	if lg.shifts != nil {
+		x = shiftModOne(x, lg.shifts[coordinate])
+	}
+
+	return x
+}
+
+func (lg *LatticeGenerator) Float64() float64 {
+	return lg.next(lg)
+}
+
+func (lg *LatticeGenerator) Float64s() []float64 {
+	return lg.point(lg)
+}
+
+// QMCProjectionGenerator produces the successive values of one coordinate of a QMC
+// sequence, projections of the same sequence read the coordinates of the
+// same points independently, e.g. the two sources of TwoDimensionalGenerator
+// or the marginals of a copula
+type QMCProjectionGenerator struct {
+	name       GeneratorName
+	sequence   QMCGenerator
+	coordinate int
+	index      uint64
+}
+
+func (qp *QMCProjectionGenerator) Name() string {
+	return string(qp.name)
+}
+
+func (qp *QMCProjectionGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = qp.name
+	d["coordinate"] = qp.coordinate
+
+	describeSource(d, "sequence", qp.sequence)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewQMCProjectionGenerator(sequence QMCGenerator, coordinate int) (*QMCProjectionGenerator, error) {
+	if sequence == nil {
+		return nil, errors.Wrap(ErrInvalidParameters, "sequence is nil")
+	}
+
+	if coordinate < 0 || coordinate >= sequence.Dimension() {
+		return nil, errors.Wrapf(ErrInvalidParameters, "coordinate %d is out of range [0, %d)", coordinate, sequence.Dimension())
+	}
+
+	return &QMCProjectionGenerator{name: QMCProjection, sequence: sequence, coordinate: coordinate}, nil
+}
+
+func (qp *QMCProjectionGenerator) Float64() float64 {
+	x := qp.sequence.At(qp.index, qp.coordinate)
+	qp.index += 1
+
+	return x
+}
+
+// describeQMC builds the String description of the sequences, the
+// randomization and its seed are omitted for plain sequences
+func describeQMC(
+	name GeneratorName,
+	dimension int,
+	randomization QMCRandomization,
+	seed uint64,
+	parameters map[string]interface{},
+) string {
+	d := make(map[string]interface{}, 4+len(parameters))
+
+	d["distributionName"] = name
+	d["dimension"] = dimension
+
+	for k, v := range parameters {
+		d[k] = v
+	}
+
+	if randomization != NoRandomization {
+		d["randomization"] = randomization
+		d["seed"] = seed
+	}
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// qmcState is shared by the QMC sequences, the randomization is drawn again
+// from its seed
+type qmcState struct {
+	Name             GeneratorName    `json:"distributionName"`
+	Dimension        int              `json:"dimension"`
+	Points           uint64           `json:"points,omitempty"`
+	GeneratingVector []uint64         `json:"generatingVector,omitempty"`
+	Randomization    QMCRandomization `json:"randomization,omitempty"`
+	Seed             uint64           `json:"seed,omitempty"`
+	Index            uint64           `json:"index"`
+	Coordinate       int              `json:"coordinate"`
+}
+
+// restoreCursor restores the position of the sequential reading
+func restoreCursor(c *qmcCursor, s qmcState, dimension int) error {
+	if s.Coordinate < 0 || s.Coordinate >= dimension {
+		return errors.Wrapf(ErrInvalidState, "coordinate %d is out of range [0, %d)", s.Coordinate, dimension)
+	}
+
+	c.index, c.coordinate = s.Index, s.Coordinate
+
+	return nil
+}
+
+// validateRandomization checks the randomization before WithRandomization
+// panics on it
+func validateRandomization(randomization QMCRandomization, supported ...QMCRandomization) error {
+	for _, r := range append(supported, NoRandomization) {
+		if randomization == r {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrInvalidParameters, "unsupported randomization %q", randomization)
+}
+
+func (hg *HaltonGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(qmcState{
+		Name:          Halton,
+		Dimension:     hg.Dimension(),
+		Randomization: hg.randomization,
+		Seed:          hg.seed,
+		Index:         hg.index,
+		Coordinate:    hg.coordinate,
+	})
+}
+
+func (hg *HaltonGenerator) UnmarshalBinary(data []byte) error {
+	var s qmcState
+	if err := unmarshalState(data, Halton, &s); err != nil {
+		return err
+	}
+
+	if err := validateRandomization(s.Randomization, RandomShift, DigitPermutation); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	restored, err := NewHaltonGenerator(s.Dimension)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	restored.WithRandomization(s.Randomization, s.Seed)
+	if err := restoreCursor(&restored.qmcCursor, s, restored.Dimension()); err != nil {
+		return err
+	}
+
+	*hg = *restored
+
+	return nil
+}
+
+func (sg *SobolGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(qmcState{
+		Name:          Sobol,
+		Dimension:     sg.Dimension(),
+		Randomization: sg.randomization,
+		Seed:          sg.seed,
+		Index:         sg.index,
+		Coordinate:    sg.coordinate,
+	})
+}
+
+func (sg *SobolGenerator) UnmarshalBinary(data []byte) error {
+	var s qmcState
+	if err := unmarshalState(data, Sobol, &s); err != nil {
+		return err
+	}
+
+	if err := validateRandomization(s.Randomization, RandomShift, DigitalShift, OwenScrambling); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	restored, err := NewSobolGenerator(s.Dimension)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	restored.WithRandomization(s.Randomization, s.Seed)
+	if err := restoreCursor(&restored.qmcCursor, s, restored.Dimension()); err != nil {
+		return err
+	}
+
+	*sg = *restored
+
+	return nil
+}
+
+func (lg *LatticeGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(qmcState{
+		Name:             Lattice,
+		Dimension:        lg.Dimension(),
+		Points:           lg.points,
+		GeneratingVector: lg.vector,
+		Randomization:    lg.randomization,
+		Seed:             lg.seed,
+		Index:            lg.index,
+		Coordinate:       lg.coordinate,
+	})
+}
+
+func (lg *LatticeGenerator) UnmarshalBinary(data []byte) error {
+	var s qmcState
+	if err := unmarshalState(data, Lattice, &s); err != nil {
+		return err
+	}
+
+	if err := validateRandomization(s.Randomization, RandomShift); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	restored, err := NewLatticeGenerator(s.Points, s.GeneratingVector)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	restored.WithRandomization(s.Randomization, s.Seed)
+	if err := restoreCursor(&restored.qmcCursor, s, restored.Dimension()); err != nil {
+		return err
+	}
+
+	*lg = *restored
+
+	return nil
+}
+
+type qmcProjectionState struct {
+	Name       GeneratorName   `json:"distributionName"`
+	Coordinate int             `json:"coordinate"`
+	Index      uint64          `json:"index"`
+	Sequence   json.RawMessage `json:"sequence"`
+}
+
+func (qp *QMCProjectionGenerator) MarshalBinary() ([]byte, error) {
+	seq, err := marshalSource(qp.sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(qmcProjectionState{
+		Name:       QMCProjection,
+		Coordinate: qp.coordinate,
+		Index:      qp.index,
+		Sequence:   seq,
+	})
+}
+
+// UnmarshalBinary restores the sequence of the projection too, projections
+// sharing a sequence restore it several times to the same state
+func (qp *QMCProjectionGenerator) UnmarshalBinary(data []byte) error {
+	var s qmcProjectionState
+	if err := unmarshalState(data, QMCProjection, &s); err != nil {
+		return err
+	}
+
+	var src interface{}
+	if qp.sequence != nil {
+		src = qp.sequence
+	}
+
+	seq, err := unmarshalSource(src, s.Sequence)
+	if err != nil {
+		return err
+	}
+
+	qg, ok := seq.(QMCGenerator)
+	if !ok {
+		return errors.Wrap(ErrInvalidState, "sequence is not a QMC generator")
+	}
+
+	restored, err := NewQMCProjectionGenerator(qg, s.Coordinate)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*qp = *restored
+	qp.index = s.Index
+
+	return nil
+}
@@ -0,0 +1,1227 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+var ErrUnknownGenerator = errors.New("unknown generator")
+
+// GeneratorParser rebuilds a generator from the JSON description produced by
+// its String method
+type GeneratorParser func(description []byte) (DistributionGenerator, error)
+
+var registry = struct {
+	sync.RWMutex
+	parsers map[GeneratorName]GeneratorParser
+}{parsers: make(map[GeneratorName]GeneratorParser)}
+
+// RegisterGenerator makes the generators named name parseable by
+// ParseGenerator, the parser of a registered name is replaced
+func RegisterGenerator(name GeneratorName, parser GeneratorParser) {
+	if parser == nil {
+		panic("parser is nil")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.parsers[name] = parser
+}
+
+// ParseGenerator rebuilds a generator, including its nested source generators,
+// from the JSON produced by its String method
+//
+// the description records the parameters and the seeds, not the current
+// state, so the rebuilt generator starts its sequence from the beginning
+// (MarshalBinary captures the state), missing sources are replaced by the
+// math/rand sources of the *Default constructors; identical descriptions of
+// the two sources of one generator are rebuilt as one shared source, a source
+// shared with a nested generator can't be told from a copy and is rejected
+//
+// generators built from functions (inverse transform, acceptance-rejection)
+// can't be described and are not registered
+func ParseGenerator(description []byte) (DistributionGenerator, error) {
+	var n struct {
+		Name GeneratorName `json:"distributionName"`
+	}
+	if err := json.Unmarshal(description, &n); err != nil {
+		return nil, errors.Wrap(ErrInvalidParameters, err.Error())
+	}
+
+	registry.RLock()
+	parse, ok := registry.parsers[n.Name]
+	registry.RUnlock()
+
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownGenerator, "%q", n.Name)
+	}
+
+	return parse(description)
+}
+
+// describeSource adds the description of the nested generator g to the String
+// description d, generators without a JSON description are omitted
+func describeSource(d map[string]interface{}, key string, g interface{}) {
+	s, ok := g.(fmt.Stringer)
+	if !ok {
+		return
+	}
+
+	if b := []byte(s.String()); json.Valid(b) {
+		d[key] = json.RawMessage(b)
+	}
+}
+
+func parseDescription(description []byte, v interface{}) error {
+	if err := json.Unmarshal(description, v); err != nil {
+		return errors.Wrap(ErrInvalidParameters, err.Error())
+	}
+
+	return nil
+}
+
+func parseIntSource(description json.RawMessage) (IntGenerator, error) {
+	if len(description) == 0 {
+		return newDefaultGenerator(), nil
+	}
+
+	g, err := ParseGenerator(description)
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := g.(IntGenerator)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%s is not an int generator", g.Name())
+	}
+
+	return i, nil
+}
+
+func parseFloat64Source(description json.RawMessage) (Float64Generator, error) {
+	if len(description) == 0 {
+		return newDefaultGenerator(), nil
+	}
+
+	g, err := ParseGenerator(description)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := g.(Float64Generator)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%s is not a float64 generator", g.Name())
+	}
+
+	return f, nil
+}
+
+// sameSource reports whether two descriptions of nested sources are identical,
+// a source shared by several fields is described once for every field
+func sameSource(description json.RawMessage, other json.RawMessage) bool {
+	if len(description) == 0 || len(other) == 0 {
+		return false
+	}
+
+	var a, b bytes.Buffer
+	if json.Compact(&a, description) != nil || json.Compact(&b, other) != nil {
+		return false
+	}
+
+	return bytes.Equal(a.Bytes(), b.Bytes())
+}
+
+// parseFloat64Sources rebuilds the two sources of a generator, identical
+// descriptions are rebuilt as one source shared by both fields
+func parseFloat64Sources(source json.RawMessage, secondSource json.RawMessage) (Float64Generator, Float64Generator, error) {
+	g, err := parseFloat64Source(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sameSource(source, secondSource) {
+		return g, g, nil
+	}
+
+	g2, err := parseFloat64Source(secondSource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return g, g2, nil
+}
+
+func parseNormFloat64Source(description json.RawMessage) (NormFloat64Generator, error) {
+	if len(description) == 0 {
+		return NewNormalGeneratorDefault(), nil
+	}
+
+	g, err := ParseGenerator(description)
+	if err != nil {
+		return nil, err
+	}
+
+	n, ok := g.(NormFloat64Generator)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%s is not a normal generator", g.Name())
+	}
+
+	return n, nil
+}
+
+func parseQuantileGenerator(description json.RawMessage) (QuantileGenerator, error) {
+	g, err := ParseGenerator(description)
+	if err != nil {
+		return nil, err
+	}
+
+	q, ok := g.(QuantileGenerator)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidParameters, "%s has no quantile function", g.Name())
+	}
+
+	return q, nil
+}
+
+func parseSampler(description json.RawMessage) (Sampler, error) {
+	g, err := ParseGenerator(description)
+	if err != nil {
+		return nil, err
+	}
+
+	return sampler(g)
+}
+
+// sampler adapts the method producing the values of g to the Sampler
+// interface, discrete values are converted to float64
+func sampler(g DistributionGenerator) (Sampler, error) {
+	switch s := g.(type) {
+	case Sampler:
+		return s, nil
+	case *UniformGenerator:
+		return NewSampler(s, s.Float64), nil
+	case *BitGenerator:
+		return NewSampler(s, s.Float64), nil
+	case *QMCProjectionGenerator:
+		return NewSampler(s, s.Float64), nil
+	case *ExponentialGenerator:
+		return NewSampler(s, s.ExpFloat64), nil
+	case *ZigguratExponentialGenerator:
+		return NewSampler(s, s.ExpFloat64), nil
+	case *NormalGenerator:
+		return NewSampler(s, s.NormFloat64), nil
+	case *ZigguratNormalGenerator:
+		return NewSampler(s, s.NormFloat64), nil
+	case *GammaGenerator:
+		return NewSampler(s, s.GammaFloat64), nil
+	case *ErlangGenerator:
+		return NewSampler(s, s.ErlangFloat64), nil
+	case *ChiSquaredGenerator:
+		return NewSampler(s, s.ChiSquaredFloat64), nil
+	case *BetaGenerator:
+		return NewSampler(s, s.BetaFloat64), nil
+	case *ParetoGenerator:
+		return NewSampler(s, s.ParetoFloat64), nil
+	case *LomaxGenerator:
+		return NewSampler(s, s.LomaxFloat64), nil
+	case *CauchyGenerator:
+		return NewSampler(s, s.CauchyFloat64), nil
+	case *StudentTGenerator:
+		return NewSampler(s, s.StudentTFloat64), nil
+	case *LogNormalGenerator:
+		return NewSampler(s, s.LogNormalFloat64), nil
+	case *WeibullGenerator:
+		return NewSampler(s, s.WeibullFloat64), nil
+	case *StableGenerator:
+		return NewSampler(s, s.StableFloat64), nil
+	case *TruncatedNormalGenerator:
+		return NewSampler(s, s.TruncatedFloat64), nil
+	case *TruncatedExponentialGenerator:
+		return NewSampler(s, s.TruncatedFloat64), nil
+	case *TruncatedGenerator:
+		return NewSampler(s, s.TruncatedFloat64), nil
+	}
+
+	if is, err := intSampler(g); err == nil {
+		return NewSampler(g, func() float64 { return float64(is.SampleInt()) }), nil
+	}
+
+	return nil, errors.Wrapf(ErrInvalidParameters, "%s is not a sampler", g.Name())
+}
+
+func parseIntSampler(description json.RawMessage) (IntSampler, error) {
+	g, err := ParseGenerator(description)
+	if err != nil {
+		return nil, err
+	}
+
+	return intSampler(g)
+}
+
+func intSampler(g DistributionGenerator) (IntSampler, error) {
+	switch s := g.(type) {
+	case IntSampler:
+		return s, nil
+	case *PoissonGenerator:
+		return NewIntSampler(s, s.PoissonInt), nil
+	case *BinomialGenerator:
+		return NewIntSampler(s, s.BinomialInt), nil
+	case *GeometricGenerator:
+		return NewIntSampler(s, s.GeometricInt), nil
+	case *NegativeBinomialGenerator:
+		return NewIntSampler(s, s.NegativeBinomialInt), nil
+	case *HypergeometricGenerator:
+		return NewIntSampler(s, s.HypergeometricInt), nil
+	case *DiscreteUniformGenerator:
+		return NewIntSampler(s, s.DiscreteUniformInt), nil
+	default:
+		return nil, errors.Wrapf(ErrInvalidParameters, "%s is not a discrete sampler", g.Name())
+	}
+}
+
+// parseBounds restores the infinite bounds described as null
+func parseBounds(a *float64, b *float64) (float64, float64) {
+	lower, upper := math.Inf(-1), math.Inf(1)
+
+	if a != nil {
+		lower = *a
+	}
+
+	if b != nil {
+		upper = *b
+	}
+
+	return lower, upper
+}
+
+// parseModulus reads the modulus of the congruential generator, 2^64 is
+// represented by 0
+func parseModulus(n json.Number) (uint64, error) {
+	if n == modulusNumber(0) {
+		return 0, nil
+	}
+
+	m, err := strconv.ParseUint(string(n), 10, 64)
+	if err != nil || m == 0 {
+		return 0, errors.Wrapf(ErrInvalidParameters, "modulus %q is out of range", n)
+	}
+
+	return m, nil
+}
+
+func init() {
+	RegisterGenerator(mathRand, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Seed int64 `json:"seed"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		src := newSeededSource(d.Seed)
+
+		return &defaultGenerator{Rand: rand.New(src), src: src}, nil
+	})
+
+	RegisterGenerator(Congruential, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Modulus           json.Number `json:"modulus"`
+			Multiplier        uint64      `json:"multiplier"`
+			AdditiveComponent uint64      `json:"additiveComponent"`
+			InitialValue      uint64      `json:"initialValue"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		n, err := parseModulus(d.Modulus)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewCongruentialGenerator64(n, d.Multiplier, d.AdditiveComponent, d.InitialValue), nil
+	})
+
+	registerSeed(PCG32, func(d seedDescription) DistributionGenerator {
+		return NewPCG32Generator(d.Seed, d.Sequence)
+	})
+	registerSeed(PCG64, func(d seedDescription) DistributionGenerator {
+		return NewPCG64Generator(d.Seed, d.Sequence)
+	})
+	registerSeed(Philox, func(d seedDescription) DistributionGenerator {
+		return NewPhiloxGenerator(d.Seed, d.Stream)
+	})
+	registerSeed(Threefry, func(d seedDescription) DistributionGenerator {
+		return NewThreefryGenerator(d.Seed, d.Stream)
+	})
+	registerSeed(SplitMix64, func(d seedDescription) DistributionGenerator {
+		return NewSplitMix64Generator(d.Seed)
+	})
+	registerSeed(Xoshiro256StarStar, func(d seedDescription) DistributionGenerator {
+		return NewXoshiro256StarStarGenerator(d.Seed)
+	})
+	registerSeed(Xoroshiro128Plus, func(d seedDescription) DistributionGenerator {
+		return NewXoroshiro128PlusGenerator(d.Seed)
+	})
+
+	RegisterGenerator(MT19937, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Seed uint32 `json:"seed"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewMT19937Generator(d.Seed), nil
+	})
+
+	RegisterGenerator(WichmannHill, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Seeds [3]uint64 `json:"seeds"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewWichmannHillGenerator(d.Seeds[0], d.Seeds[1], d.Seeds[2])
+	})
+
+	RegisterGenerator(LEcuyer, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Seeds [2]uint64 `json:"seeds"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewLEcuyerGenerator(d.Seeds[0], d.Seeds[1])
+	})
+
+	RegisterGenerator(MRG32k3a, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Seed [6]uint64 `json:"seed"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewMRG32k3aGenerator(d.Seed)
+	})
+
+	RegisterGenerator(LaggedFibonacci, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Operation LaggedFibonacciOperation `json:"operation"`
+			ShortLag  int                      `json:"shortLag"`
+			LongLag   int                      `json:"longLag"`
+			Seed      uint64                   `json:"seed"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewLaggedFibonacciGenerator(d.Operation, d.ShortLag, d.LongLag, d.Seed)
+	})
+
+	RegisterGenerator(SubtractWithCarry, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Bits     uint   `json:"bits"`
+			ShortLag int    `json:"shortLag"`
+			LongLag  int    `json:"longLag"`
+			Seed     uint64 `json:"seed"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewSubtractWithCarryGenerator(d.Bits, d.ShortLag, d.LongLag, d.Seed)
+	})
+
+	RegisterGenerator(Ranlux, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			LuxuryLevel int    `json:"luxuryLevel"`
+			Seed        uint64 `json:"seed"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return NewRanluxGenerator(d.LuxuryLevel, d.Seed)
+	})
+
+	RegisterGenerator(Uniform, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Modulus int             `json:"modulus"`
+			Source  json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if d.Modulus <= 0 {
+			return nil, errors.Wrap(ErrInvalidParameters, "modulus must be positive")
+		}
+
+		g, err := parseIntSource(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewUniformGenerator(g, d.Modulus), nil
+	})
+
+	RegisterGenerator(UniformBits, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Bits   uint            `json:"bits"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if d.Bits < 1 || d.Bits > 64 {
+			return nil, errors.Wrapf(ErrInvalidParameters, "bits count %d is out of range [1, 64]", d.Bits)
+		}
+
+		g, err := parseIntSource(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBitGeneratorWithBits(g, d.Bits), nil
+	})
+
+	RegisterGenerator(Halton, func(description []byte) (DistributionGenerator, error) {
+		var d qmcState
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if err := validateRandomization(d.Randomization, RandomShift, DigitPermutation); err != nil {
+			return nil, err
+		}
+
+		hg, err := NewHaltonGenerator(d.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		return hg.WithRandomization(d.Randomization, d.Seed), nil
+	})
+
+	RegisterGenerator(Sobol, func(description []byte) (DistributionGenerator, error) {
+		var d qmcState
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if err := validateRandomization(d.Randomization, RandomShift, DigitalShift, OwenScrambling); err != nil {
+			return nil, err
+		}
+
+		sg, err := NewSobolGenerator(d.Dimension)
+		if err != nil {
+			return nil, err
+		}
+
+		return sg.WithRandomization(d.Randomization, d.Seed), nil
+	})
+
+	RegisterGenerator(Lattice, func(description []byte) (DistributionGenerator, error) {
+		var d qmcState
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if err := validateRandomization(d.Randomization, RandomShift); err != nil {
+			return nil, err
+		}
+
+		lg, err := NewLatticeGenerator(d.Points, d.GeneratingVector)
+		if err != nil {
+			return nil, err
+		}
+
+		return lg.WithRandomization(d.Randomization, d.Seed), nil
+	})
+
+	RegisterGenerator(QMCProjection, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Coordinate int             `json:"coordinate"`
+			Sequence   json.RawMessage `json:"sequence"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := ParseGenerator(d.Sequence)
+		if err != nil {
+			return nil, err
+		}
+
+		qg, ok := g.(QMCGenerator)
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidParameters, "%s is not a QMC generator", g.Name())
+		}
+
+		return NewQMCProjectionGenerator(qg, d.Coordinate)
+	})
+
+	RegisterGenerator(Exponential, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Rate   float64         `json:"rate"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewExponentialGenerator(g, d.Rate), nil
+	})
+
+	RegisterGenerator(Normal, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			StandardDeviation float64         `json:"standardDeviation"`
+			Mean              float64         `json:"mean"`
+			Source            json.RawMessage `json:"source"`
+			SecondSource      json.RawMessage `json:"secondSource"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, g2, err := parseFloat64Sources(d.Source, d.SecondSource)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNormalGenerator(g, g2, d.StandardDeviation, d.Mean), nil
+	})
+
+	RegisterGenerator(TwoDimensional, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Method                 TwoDimensionalMethod `json:"method"`
+			StandardDeviationX     float64              `json:"standardDeviationX"`
+			StandardDeviationY     float64              `json:"standardDeviationY"`
+			MeanX                  float64              `json:"meanX"`
+			MeanY                  float64              `json:"meanY"`
+			CorrelationCoefficient float64              `json:"correlationCoefficient"`
+			Source                 json.RawMessage      `json:"source"`
+			SecondSource           json.RawMessage      `json:"secondSource"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if d.Method == "" {
+			d.Method = CentralLimitMethod
+		}
+
+		if d.Method != CentralLimitMethod && d.Method != PolarMethod {
+			return nil, errors.Wrapf(ErrInvalidParameters, "unknown two-dimensional method %q", d.Method)
+		}
+
+		g, g2, err := parseFloat64Sources(d.Source, d.SecondSource)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTwoDimensionalGenerator(
+			g,
+			g2,
+			d.StandardDeviationX,
+			d.StandardDeviationY,
+			d.MeanX,
+			d.MeanY,
+			d.CorrelationCoefficient,
+		).WithMethod(d.Method), nil
+	})
+
+	RegisterGenerator(ZigguratNormal, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			StandardDeviation float64         `json:"standardDeviation"`
+			Mean              float64         `json:"mean"`
+			Source            json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewZigguratNormalGenerator(g, d.StandardDeviation, d.Mean), nil
+	})
+
+	RegisterGenerator(ZigguratExponential, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Rate   float64         `json:"rate"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewZigguratExponentialGenerator(g, d.Rate), nil
+	})
+
+	RegisterGenerator(MultivariateNormal, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Mean       []float64       `json:"mean"`
+			Covariance [][]float64     `json:"covariance"`
+			Source     json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseNormFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMultivariateNormalGenerator(g, d.Mean, d.Covariance)
+	})
+
+	RegisterGenerator(Copula, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Family           CopulaFamily    `json:"family"`
+			Parameter        float64         `json:"parameter"`
+			DegreesOfFreedom float64         `json:"degreesOfFreedom"`
+			MarginalX        json.RawMessage `json:"marginalX"`
+			MarginalY        json.RawMessage `json:"marginalY"`
+			Source           json.RawMessage `json:"source"`
+			SecondSource     json.RawMessage `json:"secondSource"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		if err := validateCopula(d.Family, d.Parameter, d.DegreesOfFreedom); err != nil {
+			return nil, err
+		}
+
+		x, err := parseQuantileGenerator(d.MarginalX)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := parseQuantileGenerator(d.MarginalY)
+		if err != nil {
+			return nil, err
+		}
+
+		g, g2, err := parseFloat64Sources(d.Source, d.SecondSource)
+		if err != nil {
+			return nil, err
+		}
+
+		return newCopulaGenerator(d.Family, g, g2, x, y, d.Parameter, d.DegreesOfFreedom)
+	})
+
+	RegisterGenerator(Gamma, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Shape  float64         `json:"shape"`
+			Scale  float64         `json:"scale"`
+			Source json.RawMessage `json:"source"`
+			Normal json.RawMessage `json:"normal"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, normal, err := parseGammaSources(d.Source, d.Normal)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewGammaGenerator(g, normal, d.Shape, d.Scale)
+	})
+
+	RegisterGenerator(Erlang, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Shape  int             `json:"shape"`
+			Scale  float64         `json:"scale"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewErlangGenerator(g, d.Shape, d.Scale)
+	})
+
+	RegisterGenerator(ChiSquared, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			DegreesOfFreedom float64         `json:"degreesOfFreedom"`
+			Source           json.RawMessage `json:"source"`
+			Normal           json.RawMessage `json:"normal"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, normal, err := parseGammaSources(d.Source, d.Normal)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewChiSquaredGenerator(g, normal, d.DegreesOfFreedom)
+	})
+
+	RegisterGenerator(Beta, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Alpha  float64         `json:"alpha"`
+			Beta   float64         `json:"beta"`
+			Source json.RawMessage `json:"source"`
+			Normal json.RawMessage `json:"normal"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, normal, err := parseGammaSources(d.Source, d.Normal)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBetaGenerator(g, normal, d.Alpha, d.Beta)
+	})
+
+	RegisterGenerator(Dirichlet, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Concentration []float64       `json:"concentration"`
+			Source        json.RawMessage `json:"source"`
+			Normal        json.RawMessage `json:"normal"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, normal, err := parseGammaSources(d.Source, d.Normal)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDirichletGenerator(g, normal, d.Concentration)
+	})
+
+	registerScaleShape(Pareto, func(g Float64Generator, scale float64, shape float64) (DistributionGenerator, error) {
+		return NewParetoGenerator(g, scale, shape)
+	})
+	registerScaleShape(Lomax, func(g Float64Generator, scale float64, shape float64) (DistributionGenerator, error) {
+		return NewLomaxGenerator(g, scale, shape)
+	})
+	registerScaleShape(Weibull, func(g Float64Generator, scale float64, shape float64) (DistributionGenerator, error) {
+		return NewWeibullGenerator(g, scale, shape)
+	})
+
+	RegisterGenerator(Cauchy, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Location float64         `json:"location"`
+			Scale    float64         `json:"scale"`
+			Source   json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewCauchyGenerator(g, d.Location, d.Scale)
+	})
+
+	RegisterGenerator(StudentT, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			DegreesOfFreedom float64         `json:"degreesOfFreedom"`
+			Location         float64         `json:"location"`
+			Scale            float64         `json:"scale"`
+			Source           json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewStudentTGenerator(g, d.DegreesOfFreedom, d.Location, d.Scale)
+	})
+
+	RegisterGenerator(LogNormal, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Mu     float64         `json:"mu"`
+			Sigma  float64         `json:"sigma"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewLogNormalGenerator(g, d.Mu, d.Sigma)
+	})
+
+	RegisterGenerator(Stable, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Alpha    float64         `json:"alpha"`
+			Beta     float64         `json:"beta"`
+			Scale    float64         `json:"scale"`
+			Location float64         `json:"location"`
+			Source   json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewStableGenerator(g, d.Alpha, d.Beta, d.Scale, d.Location)
+	})
+
+	RegisterGenerator(Poisson, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Lambda float64         `json:"lambda"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPoissonGenerator(g, d.Lambda)
+	})
+
+	RegisterGenerator(Binomial, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Trials      int             `json:"trials"`
+			Probability float64         `json:"probability"`
+			Source      json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBinomialGenerator(g, d.Trials, d.Probability)
+	})
+
+	RegisterGenerator(Geometric, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Probability float64         `json:"probability"`
+			Source      json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewGeometricGenerator(g, d.Probability)
+	})
+
+	RegisterGenerator(NegativeBinomial, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Successes   float64         `json:"successes"`
+			Probability float64         `json:"probability"`
+			Source      json.RawMessage `json:"source"`
+			Normal      json.RawMessage `json:"normal"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, normal, err := parseGammaSources(d.Source, d.Normal)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNegativeBinomialGenerator(g, normal, d.Successes, d.Probability)
+	})
+
+	RegisterGenerator(Hypergeometric, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Population int             `json:"population"`
+			Successes  int             `json:"successes"`
+			Draws      int             `json:"draws"`
+			Source     json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewHypergeometricGenerator(g, d.Population, d.Successes, d.Draws)
+	})
+
+	RegisterGenerator(DiscreteUniform, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Modulus int             `json:"modulus"`
+			Min     int             `json:"min"`
+			Max     int             `json:"max"`
+			Source  json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		// generators without modulus draw from a bit generator
+		if d.Modulus == 0 && d.Source == nil {
+			return NewDiscreteUniformGeneratorDefault(d.Min, d.Max)
+		}
+
+		g, err := parseIntSource(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Modulus == 0 {
+			bg, ok := g.(*BitGenerator)
+			if !ok {
+				return nil, errors.Wrap(ErrInvalidParameters, "modulus is required unless the source is a bit generator")
+			}
+
+			return NewDiscreteUniformBitGenerator(bg, d.Min, d.Max)
+		}
+
+		return NewDiscreteUniformGenerator(g, d.Modulus, d.Min, d.Max)
+	})
+
+	RegisterGenerator(TruncatedNormal, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			StandardDeviation float64         `json:"standardDeviation"`
+			Mean              float64         `json:"mean"`
+			LowerBound        *float64        `json:"lowerBound"`
+			UpperBound        *float64        `json:"upperBound"`
+			Source            json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		a, b := parseBounds(d.LowerBound, d.UpperBound)
+
+		return NewTruncatedNormalGenerator(g, d.StandardDeviation, d.Mean, a, b)
+	})
+
+	RegisterGenerator(TruncatedExponential, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Rate       float64         `json:"rate"`
+			LowerBound *float64        `json:"lowerBound"`
+			UpperBound *float64        `json:"upperBound"`
+			Source     json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		a, b := parseBounds(d.LowerBound, d.UpperBound)
+
+		return NewTruncatedExponentialGenerator(g, d.Rate, a, b)
+	})
+
+	RegisterGenerator(Truncated, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Distribution json.RawMessage `json:"distribution"`
+			LowerBound   *float64        `json:"lowerBound"`
+			UpperBound   *float64        `json:"upperBound"`
+			Source       json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		q, err := parseQuantileGenerator(d.Distribution)
+		if err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		a, b := parseBounds(d.LowerBound, d.UpperBound)
+
+		return NewTruncatedGenerator(g, q, a, b)
+	})
+
+	RegisterGenerator(Mixture, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Weights    []float64         `json:"weights"`
+			Components []json.RawMessage `json:"components"`
+			Source     json.RawMessage   `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		components := make([]Sampler, len(d.Components))
+		for i, c := range d.Components {
+			s, err := parseSampler(c)
+			if err != nil {
+				return nil, err
+			}
+
+			components[i] = s
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMixtureGenerator(g, components, d.Weights)
+	})
+
+	RegisterGenerator(Compound, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Count   json.RawMessage `json:"count"`
+			Summand json.RawMessage `json:"summand"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		count, err := parseIntSampler(d.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		summand, err := parseSampler(d.Summand)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewCompoundGenerator(count, summand)
+	})
+}
+
+// seedDescription describes the generators built from their seeds
+type seedDescription struct {
+	Seed     uint64 `json:"seed"`
+	Sequence uint64 `json:"sequence"`
+	Stream   uint64 `json:"stream"`
+}
+
+func registerSeed(name GeneratorName, build func(d seedDescription) DistributionGenerator) {
+	RegisterGenerator(name, func(description []byte) (DistributionGenerator, error) {
+		var d seedDescription
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		return build(d), nil
+	})
+}
+
+func registerScaleShape(name GeneratorName, build func(g Float64Generator, scale float64, shape float64) (DistributionGenerator, error)) {
+	RegisterGenerator(name, func(description []byte) (DistributionGenerator, error) {
+		var d struct {
+			Scale  float64         `json:"scale"`
+			Shape  float64         `json:"shape"`
+			Source json.RawMessage `json:"source"`
+		}
+		if err := parseDescription(description, &d); err != nil {
+			return nil, err
+		}
+
+		g, err := parseFloat64Source(d.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		return build(g, d.Scale, d.Shape)
+	})
+}
+
+// parseGammaSources parses the uniform and the normal sources of the
+// generators built on the gamma generator
+func parseGammaSources(source json.RawMessage, normal json.RawMessage) (Float64Generator, NormFloat64Generator, error) {
+	var nested struct {
+		Source       json.RawMessage `json:"source"`
+		SecondSource json.RawMessage `json:"secondSource"`
+	}
+	if len(normal) > 0 {
+		if err := parseDescription(normal, &nested); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// the normal source would draw from a copy of the uniform source
+	if sameSource(source, nested.Source) || sameSource(source, nested.SecondSource) {
+		return nil, nil, errors.Wrap(ErrInvalidParameters, "the normal source draws from the uniform source")
+	}
+
+	g, err := parseFloat64Source(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n, err := parseNormFloat64Source(normal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return g, n, nil
+}
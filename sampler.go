@@ -0,0 +1,319 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+)
+
+// Sampler is a distribution generator drawing its values with a common
+// method, custom distributions are built on top of Samplers
+type Sampler interface {
+	DistributionGenerator
+	Sample() float64
+}
+
+type funcSampler struct {
+	DistributionGenerator
+	next func() float64
+}
+
+func (fs *funcSampler) Sample() float64 {
+	return fs.next()
+}
+
+// NewSampler adapts a generator to the Sampler interface, next is its method
+// producing the values, e.g. NewSampler(eg, eg.ExpFloat64)
+func NewSampler(generator DistributionGenerator, next func() float64) Sampler {
+	return &funcSampler{DistributionGenerator: generator, next: next}
+}
+
+// InverseTransformGenerator produces Quantile(U) values, U is uniform in (0, 1)
+type InverseTransformGenerator struct {
+	name     GeneratorName
+	g        Float64Generator
+	quantile func(p float64) float64
+	label    string
+}
+
+func (ig *InverseTransformGenerator) Name() string {
+	return string(ig.name)
+}
+
+func (ig *InverseTransformGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = ig.name
+	if ig.label != "" {
+		d["label"] = ig.label
+	}
+
+	describeSource(d, "source", ig.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewInverseTransformGeneratorDefault(quantile func(p float64) float64) (*InverseTransformGenerator, error) {
+	return NewInverseTransformGenerator(newDefaultGenerator(), quantile)
+}
+
+// NewInverseTransformGenerator builds a generator of the distribution with the
+// given quantile function (the inverse of the distribution function)
+func NewInverseTransformGenerator(generator Float64Generator, quantile func(p float64) float64) (*InverseTransformGenerator, error) {
+	if quantile == nil {
+		return nil, errors.Wrap(ErrInvalidParameters, "quantile function must be set")
+	}
+
+	return &InverseTransformGenerator{name: InverseTransform, g: generator, quantile: quantile}, nil
+}
+
+// WithLabel sets the label describing the distribution in String
+func (ig *InverseTransformGenerator) WithLabel(label string) *InverseTransformGenerator {
+	ig.label = label
+	return ig
+}
+
+func (ig *InverseTransformGenerator) Sample() float64 {
+	return ig.quantile(openUniform(ig.g))
+}
+
+func (ig *InverseTransformGenerator) Quantile(p float64) float64 {
+	return ig.quantile(p)
+}
+
+// AcceptanceRejectionGenerator produces values of the target density f with
+// von Neumann's method: a value x of the proposal with density g is accepted
+// when U·M·g(x) <= f(x), the envelope constant M must satisfy f <= M·g
+//
+// the expected acceptance rate is 1/M for normalized densities, the observed
+// rate and the count of proposals violating the envelope are reported
+type AcceptanceRejectionGenerator struct {
+	name            GeneratorName
+	g               Float64Generator
+	target          func(x float64) float64
+	proposal        Sampler
+	proposalDensity func(x float64) float64
+	envelope        float64
+	label           string
+
+	proposed   uint64
+	accepted   uint64
+	violations uint64
+}
+
+func (ag *AcceptanceRejectionGenerator) Name() string {
+	return string(ag.name)
+}
+
+func (ag *AcceptanceRejectionGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = ag.name
+	d["envelope"] = ag.envelope
+	d["proposal"] = json.RawMessage(ag.proposal.String())
+	if ag.label != "" {
+		d["label"] = ag.label
+	}
+
+	describeSource(d, "source", ag.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewAcceptanceRejectionGeneratorDefault(
+	target func(x float64) float64,
+	proposal Sampler,
+	proposalDensity func(x float64) float64,
+	envelope float64,
+) (*AcceptanceRejectionGenerator, error) {
+	return NewAcceptanceRejectionGenerator(newDefaultGenerator(), target, proposal, proposalDensity, envelope)
+}
+
+// NewAcceptanceRejectionGenerator builds a generator of the target density,
+// generator provides the uniform values of the acceptance test, the densities
+// don't have to be normalized
+func NewAcceptanceRejectionGenerator(
+	generator Float64Generator,
+	target func(x float64) float64,
+	proposal Sampler,
+	proposalDensity func(x float64) float64,
+	envelope float64,
+) (*AcceptanceRejectionGenerator, error) {
+	if target == nil || proposal == nil || proposalDensity == nil {
+		return nil, errors.Wrap(ErrInvalidParameters, "target, proposal and proposal density must be set")
+	}
+
+	if err := validatePositive("envelope", envelope); err != nil {
+		return nil, err
+	}
+
+	return &AcceptanceRejectionGenerator{
+		name:            AcceptanceRejection,
+		g:               generator,
+		target:          target,
+		proposal:        proposal,
+		proposalDensity: proposalDensity,
+		envelope:        envelope,
+	}, nil
+}
+
+// WithLabel sets the label describing the distribution in String
+func (ag *AcceptanceRejectionGenerator) WithLabel(label string) *AcceptanceRejectionGenerator {
+	ag.label = label
+	return ag
+}
+
+func (ag *AcceptanceRejectionGenerator) Sample() float64 {
+	for {
+		x := ag.proposal.Sample()
+		bound := ag.envelope * ag.proposalDensity(x)
+		f := ag.target(x)
+
+		ag.proposed += 1
+
+		if f > bound {
+			ag.violations += 1
+		}
+
+		if ag.g.Float64()*bound <= f && f > 0 {
+			ag.accepted += 1
+			return x
+		}
+	}
+}
+
+// AcceptanceRate returns the share of accepted proposals, NaN before the first value
+func (ag *AcceptanceRejectionGenerator) AcceptanceRate() float64 {
+	if ag.proposed == 0 {
+		return math.NaN()
+	}
+
+	return float64(ag.accepted) / float64(ag.proposed)
+}
+
+// Proposed returns the count of values drawn from the proposal
+func (ag *AcceptanceRejectionGenerator) Proposed() uint64 {
+	return ag.proposed
+}
+
+// EnvelopeViolations returns the count of proposals x with f(x) > M·g(x),
+// values are biased when it is not 0 and the envelope must be raised
+func (ag *AcceptanceRejectionGenerator) EnvelopeViolations() uint64 {
+	return ag.violations
+}
+
+// the functions can't be serialized, the state restores the sources and the
+// statistics of a generator built with the same functions
+
+type inverseTransformState struct {
+	Name   GeneratorName   `json:"distributionName"`
+	Label  string          `json:"label,omitempty"`
+	Source json.RawMessage `json:"source"`
+}
+
+func (ig *InverseTransformGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(ig.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(inverseTransformState{Name: InverseTransform, Label: ig.label, Source: src})
+}
+
+func (ig *InverseTransformGenerator) UnmarshalBinary(data []byte) error {
+	if ig.quantile == nil {
+		return errors.Wrap(ErrStateUnsupported, "quantile function is not set")
+	}
+
+	var s inverseTransformState
+	if err := unmarshalState(data, InverseTransform, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(ig.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	ig.g = g
+	ig.label = s.Label
+
+	return nil
+}
+
+type acceptanceRejectionState struct {
+	Name       GeneratorName   `json:"distributionName"`
+	Envelope   float64         `json:"envelope"`
+	Label      string          `json:"label,omitempty"`
+	Proposed   uint64          `json:"proposed"`
+	Accepted   uint64          `json:"accepted"`
+	Violations uint64          `json:"violations"`
+	Source     json.RawMessage `json:"source"`
+	Proposal   json.RawMessage `json:"proposal"`
+}
+
+func (ag *AcceptanceRejectionGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(ag.g)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := marshalSource(samplerSource(ag.proposal))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(acceptanceRejectionState{
+		Name:       AcceptanceRejection,
+		Envelope:   ag.envelope,
+		Label:      ag.label,
+		Proposed:   ag.proposed,
+		Accepted:   ag.accepted,
+		Violations: ag.violations,
+		Source:     src,
+		Proposal:   p,
+	})
+}
+
+func (ag *AcceptanceRejectionGenerator) UnmarshalBinary(data []byte) error {
+	if ag.target == nil || ag.proposal == nil || ag.proposalDensity == nil {
+		return errors.Wrap(ErrStateUnsupported, "target and proposal are not set")
+	}
+
+	var s acceptanceRejectionState
+	if err := unmarshalState(data, AcceptanceRejection, &s); err != nil {
+		return err
+	}
+
+	if err := validatePositive("envelope", s.Envelope); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	g, err := unmarshalFloat64Source(ag.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	// the proposal is restored in place, its sampling method stays bound to it
+	if _, err := unmarshalSource(samplerSource(ag.proposal), s.Proposal); err != nil {
+		return err
+	}
+
+	ag.g = g
+	ag.envelope = s.Envelope
+	ag.label = s.Label
+	ag.proposed = s.Proposed
+	ag.accepted = s.Accepted
+	ag.violations = s.Violations
+
+	return nil
+}
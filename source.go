@@ -0,0 +1,88 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+)
+
+// Seeder is implemented by generators which can be reseeded
+type Seeder interface{ Seed(seed int64) }
+
+// Source adapts an IntGenerator to math/rand.Source and math/rand.Source64
+//
+// bits is the count of uniformly distributed low bits produced by every call
+// of Int(), e.g. 32 for a congruential generator with modulus 2^32; wider
+// values are assembled from several consecutive calls
+type Source struct {
+	g    IntGenerator
+	bits uint
+	mask uint64
+}
+
+func NewSource(generator IntGenerator, bits uint) *Source {
+	if bits < 1 || bits > 64 {
+		panic(fmt.Sprintf("bits count %d is out of range [1, 64]", bits))
+	}
+
+	return &Source{g: generator, bits: bits, mask: math.MaxUint64 >> (64 - bits)}
+}
+
+// NewFloat64Source adapts a Float64Generator producing values in [0, 1)
+// with at least bits bits of resolution
+func NewFloat64Source(generator Float64Generator, bits uint) *Source {
+	if bits < 1 || bits > 53 {
+		panic(fmt.Sprintf("bits count %d is out of range [1, 53]", bits))
+	}
+
+	return NewSource(&float64IntGenerator{g: generator, scale: math.Ldexp(1, int(bits))}, bits)
+}
+
+func (s *Source) Uint64() uint64 {
+	v := uint64(0)
+
+	for n := uint(0); n < 64; n += s.bits {
+		v = v<<s.bits | uint64(s.g.Int())&s.mask
+	}
+
+	return v
+}
+
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() & math.MaxInt64)
+}
+
+// Seed reseeds the underlying generator, panics if it does not implement Seeder
+func (s *Source) Seed(seed int64) {
+	seeder, ok := s.g.(Seeder)
+	if !ok {
+		panic(fmt.Sprintf("%T can't be seeded", s.g))
+	}
+
+	seeder.Seed(seed)
+}
+
+// ExpSource adapts an IntGenerator to golang.org/x/exp/rand.Source
+type ExpSource struct {
+	*Source
+}
+
+func NewExpSource(generator IntGenerator, bits uint) *ExpSource {
+	return &ExpSource{Source: NewSource(generator, bits)}
+}
+
+func NewFloat64ExpSource(generator Float64Generator, bits uint) *ExpSource {
+	return &ExpSource{Source: NewFloat64Source(generator, bits)}
+}
+
+func (s *ExpSource) Seed(seed uint64) {
+	s.Source.Seed(int64(seed))
+}
+
+type float64IntGenerator struct {
+	g     Float64Generator
+	scale float64
+}
+
+func (fg *float64IntGenerator) Int() int {
+	return int(fg.g.Float64() * fg.scale)
+}
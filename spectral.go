@@ -0,0 +1,387 @@
+package generators
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"math/big"
+	"sort"
+)
+
+const (
+	minSpectralDimension = 2
+	maxSpectralDimension = 8
+)
+
+// SpectralDimension is the outcome of the spectral test in a single dimension
+type SpectralDimension struct {
+	// Dimension is t, the count of consecutive outputs forming a point
+	Dimension int
+	// NuSquared is ν_t², the squared length of the shortest nonzero dual
+	// lattice vector, 1/ν_t is the maximal distance between the hyperplanes
+	// covering all t-tuples of outputs
+	NuSquared *big.Int
+	// Nu is ν_t
+	Nu float64
+	// Merit is Knuth's figure of merit μ_t = π^(t/2) ν_t^t / ((t/2)! m),
+	// values above 0.1 are considered to pass and above 1 to be excellent
+	Merit float64
+}
+
+// MultiplierRank holds the spectral test outcome for a candidate multiplier
+type MultiplierRank struct {
+	Multiplier int
+	Dimensions []SpectralDimension
+	// MinMerit is the lowest μ_t over the tested dimensions
+	MinMerit float64
+}
+
+// SpectralTest runs Knuth's spectral test for dimensions 2..maxDimension
+// (maxDimension is at most 8) of the congruential generator parameters,
+// the additive component does not affect the lattice structure
+//
+// ν_t is found as the shortest vector of the dual lattice
+// {s : s_1 + s_2 a + ... + s_t a^(t-1) ≡ 0 (mod m)}, its basis is LLL-reduced
+// and the shortest vector is enumerated exactly, all arithmetic is done on
+// arbitrary precision integers and rationals
+func SpectralTest(modulus int, multiplier int, maxDimension int) ([]SpectralDimension, error) {
+	if modulus < 2 {
+		return nil, errors.Wrap(ErrInvalidParameters, "modulus is less than 2")
+	}
+
+	if multiplier <= 0 || multiplier >= modulus {
+		return nil, errors.Wrap(ErrInvalidParameters, "multiplier is out of range (0, modulus)")
+	}
+
+	return spectralTest(uint64(modulus), uint64(multiplier), maxDimension)
+}
+
+// spectralTest treats modulus 0 as 2^64
+func spectralTest(modulus uint64, multiplier uint64, maxDimension int) ([]SpectralDimension, error) {
+	if maxDimension < minSpectralDimension || maxDimension > maxSpectralDimension {
+		return nil, errors.Wrapf(ErrInvalidParameters, "dimension is out of range [%d, %d]", minSpectralDimension, maxSpectralDimension)
+	}
+
+	logModulus := 64 * math.Ln2
+	if modulus != 0 {
+		logModulus = math.Log(float64(modulus))
+	}
+
+	results := make([]SpectralDimension, 0, maxDimension-1)
+
+	for t := minSpectralDimension; t <= maxDimension; t += 1 {
+		basis := dualLatticeBasis(modulus, multiplier, t)
+		lllReduce(basis)
+
+		nuSquared := shortestVector(basis)
+		nu := math.Sqrt(bigToFloat(nuSquared))
+
+		lgammaHalf, _ := math.Lgamma(float64(t)/2 + 1)
+		logMerit := float64(t)/2*math.Log(math.Pi) + float64(t)*math.Log(nu) - lgammaHalf - logModulus
+
+		results = append(results, SpectralDimension{
+			Dimension: t,
+			NuSquared: nuSquared,
+			Nu:        nu,
+			Merit:     math.Exp(logMerit),
+		})
+	}
+
+	return results, nil
+}
+
+// SpectralTest runs the spectral test for the generator parameters
+func (cg *CongruentialGenerator) SpectralTest(maxDimension int) ([]SpectralDimension, error) {
+	if cg.m == 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "multiplier is 0")
+	}
+
+	return spectralTest(cg.n, cg.m, maxDimension)
+}
+
+// RankMultipliers runs the spectral test for every candidate multiplier and
+// orders them by their lowest figure of merit, best first
+func RankMultipliers(modulus int, multipliers []int, maxDimension int) ([]MultiplierRank, error) {
+	ranks := make([]MultiplierRank, 0, len(multipliers))
+
+	for _, a := range multipliers {
+		dims, err := SpectralTest(modulus, a, maxDimension)
+		if err != nil {
+			return nil, errors.Wrapf(err, "multiplier %d", a)
+		}
+
+		minMerit := math.Inf(1)
+		for _, d := range dims {
+			minMerit = math.Min(minMerit, d.Merit)
+		}
+
+		ranks = append(ranks, MultiplierRank{Multiplier: a, Dimensions: dims, MinMerit: minMerit})
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].MinMerit > ranks[j].MinMerit
+	})
+
+	return ranks, nil
+}
+
+// dualLatticeBasis returns the rows (m, 0, ..., 0) and (-a^(j-1) mod m, 0, .., 1, .., 0),
+// modulus 0 stands for 2^64
+func dualLatticeBasis(m, a uint64, t int) [][]*big.Int {
+	basis := make([][]*big.Int, t)
+	power := uint64(1)
+
+	for i := 0; i < t; i += 1 {
+		row := make([]*big.Int, t)
+		for j := range row {
+			row[j] = new(big.Int)
+		}
+
+		if i == 0 && m == 0 {
+			row[0].SetBit(row[0], 64, 1)
+		} else if i == 0 {
+			row[0].SetUint64(m)
+		} else {
+			power = mulMod(power, a, m)
+			row[0].SetUint64(power)
+			row[0].Neg(row[0])
+			row[i].SetInt64(1)
+		}
+
+		basis[i] = row
+	}
+
+	return basis
+}
+
+// lllReduce performs the exact Lenstra–Lenstra–Lovász reduction (δ = 3/4) in place
+func lllReduce(b [][]*big.Int) {
+	n := len(b)
+	delta := big.NewRat(3, 4)
+	half := big.NewRat(1, 2)
+
+	for k := 1; k < n; {
+		mu, norms := gramSchmidt(b)
+
+		for j := k - 1; j >= 0; j -= 1 {
+			abs := new(big.Rat).Abs(mu[k][j])
+			if abs.Cmp(half) <= 0 {
+				continue
+			}
+
+			q := roundRat(mu[k][j])
+			for i := range b[k] {
+				b[k][i].Sub(b[k][i], new(big.Int).Mul(q, b[j][i]))
+			}
+
+			mu, norms = gramSchmidt(b)
+		}
+
+		// Lovász condition: |b*_k|² >= (δ - μ²_{k,k-1}) |b*_{k-1}|²
+		m2 := new(big.Rat).Mul(mu[k][k-1], mu[k][k-1])
+		rhs := new(big.Rat).Mul(new(big.Rat).Sub(delta, m2), norms[k-1])
+
+		if norms[k].Cmp(rhs) >= 0 {
+			k += 1
+		} else {
+			b[k], b[k-1] = b[k-1], b[k]
+			if k > 1 {
+				k -= 1
+			}
+		}
+	}
+}
+
+// gramSchmidt returns the Gram–Schmidt coefficients μ and the squared norms of
+// the orthogonalized vectors
+func gramSchmidt(b [][]*big.Int) ([][]*big.Rat, []*big.Rat) {
+	n := len(b)
+	ortho := make([][]*big.Rat, n)
+	mu := make([][]*big.Rat, n)
+	norms := make([]*big.Rat, n)
+
+	for i := 0; i < n; i += 1 {
+		ortho[i] = make([]*big.Rat, len(b[i]))
+		for j := range b[i] {
+			ortho[i][j] = new(big.Rat).SetInt(b[i][j])
+		}
+
+		mu[i] = make([]*big.Rat, n)
+		for j := 0; j < i; j += 1 {
+			dot := new(big.Rat)
+			for x := range b[i] {
+				dot.Add(dot, new(big.Rat).Mul(new(big.Rat).SetInt(b[i][x]), ortho[j][x]))
+			}
+
+			mu[i][j] = dot.Quo(dot, norms[j])
+
+			for x := range ortho[i] {
+				ortho[i][x].Sub(ortho[i][x], new(big.Rat).Mul(mu[i][j], ortho[j][x]))
+			}
+		}
+
+		norms[i] = new(big.Rat)
+		for _, v := range ortho[i] {
+			norms[i].Add(norms[i], new(big.Rat).Mul(v, v))
+		}
+	}
+
+	return mu, norms
+}
+
+// shortestVector returns the squared length of the shortest nonzero vector of
+// the lattice spanned by the (reduced) basis b
+//
+// every lattice vector x = Σ z_j b_j has z_j = x·d_j where d_j are the rows of
+// the dual basis (b^-1)ᵀ, so |z_j| <= |x| |d_j| bounds the enumeration
+func shortestVector(b [][]*big.Int) *big.Int {
+	n := len(b)
+	best := new(big.Int)
+
+	for _, row := range b {
+		l := squaredLength(row)
+		if best.Sign() == 0 || l.Cmp(best) < 0 {
+			best = l
+		}
+	}
+
+	dual := dualNorms(b)
+	bounds := make([]int64, n)
+	for j := range bounds {
+		// z_j² <= best * |d_j|²
+		limit := new(big.Rat).Mul(new(big.Rat).SetInt(best), dual[j])
+		bounds[j] = ratSqrtFloor(limit)
+	}
+
+	z := make([]int64, n)
+	vector := make([]*big.Int, len(b[0]))
+	for i := range vector {
+		vector[i] = new(big.Int)
+	}
+
+	var enumerate func(j int)
+	enumerate = func(j int) {
+		if j == n {
+			nonZero := false
+			for i := range vector {
+				vector[i].SetInt64(0)
+			}
+
+			for r, c := range z {
+				if c == 0 {
+					continue
+				}
+
+				nonZero = true
+				factor := big.NewInt(c)
+				for i := range vector {
+					vector[i].Add(vector[i], new(big.Int).Mul(factor, b[r][i]))
+				}
+			}
+
+			if !nonZero {
+				return
+			}
+
+			if l := squaredLength(vector); l.Cmp(best) < 0 {
+				best = l
+			}
+
+			return
+		}
+
+		for c := -bounds[j]; c <= bounds[j]; c += 1 {
+			z[j] = c
+			enumerate(j + 1)
+		}
+	}
+
+	enumerate(0)
+
+	return best
+}
+
+// dualNorms returns |d_j|² for the rows of (b^-1)ᵀ, computed by exact
+// Gauss–Jordan inversion
+func dualNorms(b [][]*big.Int) []*big.Rat {
+	n := len(b)
+	a := make([][]*big.Rat, n)
+	inv := make([][]*big.Rat, n)
+
+	for i := 0; i < n; i += 1 {
+		a[i] = make([]*big.Rat, n)
+		inv[i] = make([]*big.Rat, n)
+		for j := 0; j < n; j += 1 {
+			a[i][j] = new(big.Rat).SetInt(b[i][j])
+			inv[i][j] = new(big.Rat)
+		}
+		inv[i][i].SetInt64(1)
+	}
+
+	for c := 0; c < n; c += 1 {
+		p := c
+		for p < n && a[p][c].Sign() == 0 {
+			p += 1
+		}
+
+		a[c], a[p] = a[p], a[c]
+		inv[c], inv[p] = inv[p], inv[c]
+
+		pivot := new(big.Rat).Set(a[c][c])
+		for j := 0; j < n; j += 1 {
+			a[c][j].Quo(a[c][j], pivot)
+			inv[c][j].Quo(inv[c][j], pivot)
+		}
+
+		for r := 0; r < n; r += 1 {
+			if r == c || a[r][c].Sign() == 0 {
+				continue
+			}
+
+			f := new(big.Rat).Set(a[r][c])
+			for j := 0; j < n; j += 1 {
+				a[r][j].Sub(a[r][j], new(big.Rat).Mul(f, a[c][j]))
+				inv[r][j].Sub(inv[r][j], new(big.Rat).Mul(f, inv[c][j]))
+			}
+		}
+	}
+
+	// the rows of (b^-1)ᵀ are the columns of b^-1
+	norms := make([]*big.Rat, n)
+	for j := 0; j < n; j += 1 {
+		norms[j] = new(big.Rat)
+		for i := 0; i < n; i += 1 {
+			norms[j].Add(norms[j], new(big.Rat).Mul(inv[i][j], inv[i][j]))
+		}
+	}
+
+	return norms
+}
+
+func squaredLength(v []*big.Int) *big.Int {
+	l := new(big.Int)
+	for _, x := range v {
+		l.Add(l, new(big.Int).Mul(x, x))
+	}
+
+	return l
+}
+
+func roundRat(r *big.Rat) *big.Int {
+	// floor(r + 1/2)
+	shifted := new(big.Rat).Add(r, big.NewRat(1, 2))
+	q := new(big.Int).Div(shifted.Num(), shifted.Denom())
+
+	return q
+}
+
+func ratSqrtFloor(r *big.Rat) int64 {
+	q := new(big.Int).Div(r.Num(), r.Denom())
+
+	return q.Sqrt(q).Int64()
+}
+
+func bigToFloat(v *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(v).Float64()
+
+	return f
+}
@@ -20,46 +20,73 @@ func main() {
 	intervalsCount := 100
 	confidenceLevel := 0.05
 
+	// exponential and normal generators run concurrently, so each of them
+	// gets its own disjoint substream of the first generator
 	modulus1 := int(math.Pow(2, 32))
 	cg := generators.NewCongruentialGenerator(modulus1, 1103515245, 12345, 0)
-	ug := generators.NewUniformGenerator(cg, modulus1)
+	streams, err := cg.Substreams(2, uint64(modulus1/2))
+	if err != nil {
+		fmt.Println("invalid substreams:", err)
+		return
+	}
+	ug := generators.NewUniformGenerator(streams[0], modulus1)
 	eg := generators.NewExponentialGenerator(ug, rate)
 
 	modulus2 := int(math.Pow(2, 32))
 	cg2 := generators.NewCongruentialGenerator(modulus2, 134775813, 1, 3)
 	ug2 := generators.NewUniformGenerator(cg2, modulus2)
-	ng := generators.NewNormalGenerator(ug, ug2, stdDev, mean)
+	ng := generators.NewNormalGenerator(generators.NewUniformGenerator(streams[1], modulus1), ug2, stdDev, mean)
 
+	// multiplier 2147483629 with additive component 2147483587 reaches only a
+	// third of the modulus, MINSTD parameters have the maximal period
 	modulus3 := int(math.Pow(2, 31)) - 1
-	cg3 := generators.NewCongruentialGenerator(modulus3, 2147483629, 2147483587, 255)
+	cg3, analysis, err := generators.NewCongruentialGeneratorChecked(modulus3, 48271, 0, 255)
+	if err != nil {
+		fmt.Println("invalid congruential generator parameters:", err)
+		return
+	}
+	fmt.Printf("congruential generator period: %d, warnings: %v\n", analysis.Period, analysis.Warnings)
 	ug3 := generators.NewUniformGenerator(cg3, modulus3)
 
+	// the normal tail beyond 3 standard deviations
+	ug4 := generators.NewUniformGenerator(generators.NewPCG32Generator(42, 54), int(math.Pow(2, 32)))
+	tg, err := generators.NewTruncatedNormalGenerator(ug4, stdDev, mean, mean+3*stdDev, mean+5*stdDev)
+	if err != nil {
+		fmt.Println("invalid truncated normal generator parameters:", err)
+		return
+	}
+
 	e := make(chan []float64, 1)
 	n := make(chan []float64, 1)
 	u := make(chan []float64, 1)
+	t := make(chan []float64, 1)
 
 	go getDistribution(eg.Name(), eg.String(), eg.ExpFloat64, maxIterations, &wg, e)
 	go getDistribution(ng.Name(), ng.String(), ng.NormFloat64, maxIterations, &wg, n)
 	go getDistribution(ug3.Name(), ug3.String(), ug3.Float64, maxIterations, &wg, u)
+	go getDistribution(tg.Name(), tg.String(), tg.TruncatedFloat64, maxIterations, &wg, t)
 
 	wg.Wait()
 
 	nd := <-n
 	ed := <-e
 	ud := <-u
+	td := <-t
 
-	runNormalDistributionAnalysis(nd, stdDev, mean, intervalsCount, confidenceLevel)
+	// the laws are read from the generators
+	runNormalDistributionAnalysis(nd, ng, intervalsCount, confidenceLevel)
 	runExponentialDistributionAnalysis(ed, rate, intervalsCount, confidenceLevel)
 	runUniformDistributionAnalysis(ud, intervalsCount, confidenceLevel)
+	runTruncatedDistributionAnalysis(td, tg, intervalsCount, confidenceLevel)
 
 	wg.Wait()
 }
 
-func runNormalDistributionAnalysis(distribution []float64, stdDev float64, mean float64, intervalsCount int, confidenceLevel float64) {
+func runNormalDistributionAnalysis(distribution []float64, law stat.Distribution, intervalsCount int, confidenceLevel float64) {
 	fmt.Println("###normal distribution test started")
 	analysis := stat.NewStatisticAnalysis(distribution, intervalsCount, confidenceLevel)
 
-	chiObserved, chiCritical := analysis.TestPearsonNormal(stdDev, mean)
+	chiObserved, chiCritical := analysis.TestPearsonDistribution(law)
 	if chiObserved > chiCritical {
 		fmt.Println("[NRM] distribution is not of normal type because", chiObserved, ">", chiCritical)
 	} else {
@@ -94,6 +121,19 @@ func runUniformDistributionAnalysis(distribution []float64, intervalsCount int,
 	fmt.Println("###uniform distribution test finished")
 }
 
+func runTruncatedDistributionAnalysis(distribution []float64, law stat.Distribution, intervalsCount int, confidenceLevel float64) {
+	fmt.Println("###truncated distribution test started")
+	analysis := stat.NewStatisticAnalysis(distribution, intervalsCount, confidenceLevel)
+
+	chiObserved, chiCritical := analysis.TestPearsonDistribution(law)
+	if chiObserved > chiCritical {
+		fmt.Println("[TRN] distribution is not of truncated type because", chiObserved, ">", chiCritical)
+	} else {
+		fmt.Println("[TRN] distribution Pearson test passed", chiObserved, "<=", chiCritical)
+	}
+	fmt.Println("###truncated distribution test finished")
+}
+
 func getDistribution(distributionName, characteristics string, source func() float64, maxIterations int, wg *sync.WaitGroup, result chan []float64) {
 	wg.Add(1)
 
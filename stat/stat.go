@@ -59,127 +59,39 @@ func NewStatisticAnalysis(distribution []float64, intervalsCount int, confidence
 	}
 }
 
-func (s StatisticAnalysis) TestPearsonNormal(sigma float64, alpha float64) (float64, float64) {
-	probabilities := make([]float64, 0, len(s.intervals))
-
-	if len(s.intervals) < 1 {
-		panic("intervals length is less than 1")
-	}
-
-	for i := 1; i < len(s.intervals); i += 1 {
-		p := normalCDF(s.intervals[i].leftBound, sigma, alpha) -
-			normalCDF(s.intervals[i-1].leftBound, sigma, alpha)
-
-		fmt.Printf(
-			"p(%d) = %.6f\t\t\t[%+.6f, %+.6f)\n",
-			i-1,
-			p,
-			s.intervals[i-1].leftBound,
-			s.intervals[i].leftBound,
-		)
-
-		probabilities = append(probabilities, p)
-	}
-
-	lastP := normalCDF(s.intervals[len(s.intervals)-1].rightBound, sigma, alpha) -
-		normalCDF(s.intervals[len(s.intervals)-1].leftBound, sigma, alpha)
-	probabilities = append(
-		probabilities,
-		lastP,
-	)
-
-	fmt.Printf(
-		"p(%d) = %.6f\t\t[%+.6f, %+.6f)\n",
-		len(s.intervals)-1,
-		lastP,
-		s.intervals[len(s.intervals)-1].leftBound,
-		s.intervals[len(s.intervals)-1].rightBound,
-	)
-
-	chiObserved := 0.0
-	cumulativeProbability := 0.0
-
-	for i, p := range probabilities {
-		cumulativeProbability += p
-		chiObserved += math.Pow(
-			float64(len(s.intervals[i].values))/float64(len(s.source))-p,
-			2,
-		) / p
-	}
-
-	fmt.Println("sum(p) = ", cumulativeProbability)
-
-	chiObserved = chiObserved * float64(len(s.source))
+// Distribution is the theoretical law the values are tested against, the
+// generators describing their law implement it
+type Distribution interface {
+	CDF(x float64) float64
+}
 
-	chiCritical := distuv.ChiSquared{
-		K:   float64(len(s.intervals) - 1),
-		Src: rand.NewSource(uint64(time.Now().UnixNano())),
-	}
+// TestPearsonDistribution tests the values against the law of the generator
+// which produced them
+func (s StatisticAnalysis) TestPearsonDistribution(d Distribution) (float64, float64) {
+	return s.TestPearsonCDF(d.CDF)
+}
 
-	return chiObserved, chiCritical.Quantile(1 - s.alpha)
+func (s StatisticAnalysis) TestPearsonNormal(sigma float64, alpha float64) (float64, float64) {
+	return s.TestPearsonCDF(distuv.Normal{Mu: alpha, Sigma: sigma}.CDF)
 }
 
+// TestPearsonExp tests the values against the exponential law with the rate lambda,
+// the probabilities of the far intervals are tiny, so they are printed with 30 digits
 func (s StatisticAnalysis) TestPearsonExp(lambda float64) (float64, float64) {
-	probabilities := make([]float64, 0, len(s.intervals))
-
-	if len(s.intervals) < 1 {
-		panic("intervals length is less than 1")
-	}
-
-	for i := 1; i < len(s.intervals); i += 1 {
-		p := exponentialCDF(s.intervals[i].leftBound, lambda) -
-			exponentialCDF(s.intervals[i-1].leftBound, lambda)
-
-		fmt.Printf(
-			"p(%d) = %.30f\t\t\t[%+.6f, %+.6f)\n",
-			i-1,
-			p,
-			s.intervals[i-1].leftBound,
-			s.intervals[i].leftBound,
-		)
-
-		probabilities = append(probabilities, p)
-	}
-
-	lastP := exponentialCDF(s.intervals[len(s.intervals)-1].rightBound, lambda) -
-		exponentialCDF(s.intervals[len(s.intervals)-1].leftBound, lambda)
-	probabilities = append(
-		probabilities,
-		lastP,
-	)
-
-	fmt.Printf(
-		"p(%d) = %.30f\t\t[%+.6f, %+.6f)\n",
-		len(s.intervals)-1,
-		lastP,
-		s.intervals[len(s.intervals)-1].leftBound,
-		s.intervals[len(s.intervals)-1].rightBound,
-	)
-
-	chiObserved := 0.0
-	cumulativeProbability := 0.0
-
-	for i, p := range probabilities {
-		cumulativeProbability += p
-		chiObserved += math.Pow(
-			float64(len(s.intervals[i].values))/float64(len(s.source))-p,
-			2,
-		) / p
-	}
-
-	fmt.Println("sum(p) = ", cumulativeProbability)
-
-	chiObserved = chiObserved * float64(len(s.source))
+	return s.testPearson(distuv.Exponential{Rate: lambda}.CDF, 30)
+}
 
-	chiCritical := distuv.ChiSquared{
-		K:   float64(len(s.intervals) - 1),
-		Src: rand.NewSource(uint64(time.Now().UnixNano())),
-	}
+func (s StatisticAnalysis) TestPearsonUniform() (float64, float64) {
+	return s.TestPearsonCDF(distuv.Uniform{Min: s.min, Max: s.max}.CDF)
+}
 
-	return chiObserved, chiCritical.Quantile(1 - s.alpha)
+// TestPearsonCDF tests the values against the distribution function cdf
+func (s StatisticAnalysis) TestPearsonCDF(cdf func(x float64) float64) (float64, float64) {
+	return s.testPearson(cdf, 6)
 }
 
-func (s StatisticAnalysis) TestPearsonUniform() (float64, float64) {
+// testPearson prints the interval probabilities with the given count of digits
+func (s StatisticAnalysis) testPearson(cdf func(x float64) float64, digits int) (float64, float64) {
 	probabilities := make([]float64, 0, len(s.intervals))
 
 	if len(s.intervals) < 1 {
@@ -187,12 +99,12 @@ func (s StatisticAnalysis) TestPearsonUniform() (float64, float64) {
 	}
 
 	for i := 1; i < len(s.intervals); i += 1 {
-		p := uniformCDF(s.intervals[i].leftBound, s.min, s.max) -
-			uniformCDF(s.intervals[i-1].leftBound, s.min, s.max)
+		p := cdf(s.intervals[i].leftBound) - cdf(s.intervals[i-1].leftBound)
 
 		fmt.Printf(
-			"p(%d) = %.6f\t\t\t[%+.6f, %+.6f)\n",
+			"p(%d) = %.*f\t\t\t[%+.6f, %+.6f)\n",
 			i-1,
+			digits,
 			p,
 			s.intervals[i-1].leftBound,
 			s.intervals[i].leftBound,
@@ -201,16 +113,16 @@ func (s StatisticAnalysis) TestPearsonUniform() (float64, float64) {
 		probabilities = append(probabilities, p)
 	}
 
-	lastP := uniformCDF(s.intervals[len(s.intervals)-1].rightBound, s.min, s.max) -
-		uniformCDF(s.intervals[len(s.intervals)-1].leftBound, s.min, s.max)
+	lastP := cdf(s.intervals[len(s.intervals)-1].rightBound) - cdf(s.intervals[len(s.intervals)-1].leftBound)
 	probabilities = append(
 		probabilities,
 		lastP,
 	)
 
 	fmt.Printf(
-		"p(%d) = %.6f\t\t[%+.6f, %+.6f)\n",
+		"p(%d) = %.*f\t\t[%+.6f, %+.6f)\n",
 		len(s.intervals)-1,
+		digits,
 		lastP,
 		s.intervals[len(s.intervals)-1].leftBound,
 		s.intervals[len(s.intervals)-1].rightBound,
@@ -1,7 +1,5 @@
 package stat
 
-import "math"
-
 type interval struct {
 	values     []float64
 	leftBound  float64
@@ -91,27 +89,3 @@ func merge(source []interval) []interval {
 
 	return result
 }
-
-func normalCDF(x float64, sigma float64, alpha float64) float64 {
-	return (1 + math.Erf((x-alpha)/(sigma*math.Sqrt2))) / 2
-}
-
-func exponentialCDF(x float64, lambda float64) float64 {
-	if x < 0 {
-		return 0
-	}
-
-	return 1 - math.Pow(math.E, -lambda*x)
-}
-
-func uniformCDF(x float64, a float64, b float64) float64 {
-	if x <= a {
-		return 0
-	}
-
-	if x > b {
-		return 1
-	}
-
-	return (x - a) / (b - a)
-}
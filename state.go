@@ -0,0 +1,783 @@
+package generators
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/rand"
+	"reflect"
+)
+
+var (
+	ErrStateUnsupported = errors.New("generator state is not serializable")
+	ErrInvalidState     = errors.New("invalid generator state")
+)
+
+// StatefulGenerator is implemented by generators whose complete state
+// (including the state of nested source generators) can be captured and
+// restored, so that a restored generator continues the exact same sequence
+//
+// a source passed twice to a constructor (e.g. NewNormalGenerator(g, g, 1, 0))
+// is restored as a single generator, states of generators whose sources share
+// a nested generator can't be marshaled
+type StatefulGenerator interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// seededSourceBlock is the count of values after which seededSource reseeds
+// math/rand, the hidden state of math/rand can't be read, so a restore replays
+// the values drawn since the last reseed
+const seededSourceBlock = 1 << 16
+
+// seededSource is a math/rand source which remembers its seed and the number
+// of steps taken, this is enough to rebuild the hidden state of math/rand
+//
+// block k of seededSourceBlock values is drawn from the seed of block k (the
+// seed itself for the first block, the k-th SplitMix64 value of the seed for
+// the next ones), so a restore replays less than one block
+type seededSource struct {
+	src   rand.Source64
+	seed  int64
+	steps uint64
+}
+
+func newSeededSource(seed int64) *seededSource {
+	return &seededSource{src: rand.NewSource(seed).(rand.Source64), seed: seed}
+}
+
+// blockSeed returns the seed of the block of values
+func blockSeed(seed int64, block uint64) int64 {
+	if block == 0 {
+		return seed
+	}
+
+	return int64(NewSplitMix64Generator(uint64(seed) + (block-1)*0x9e3779b97f4a7c15).Uint64())
+}
+
+// step counts the next value, math/rand is reseeded at the start of a block
+func (s *seededSource) step() {
+	if s.steps > 0 && s.steps%seededSourceBlock == 0 {
+		s.src.Seed(blockSeed(s.seed, s.steps/seededSourceBlock))
+	}
+
+	s.steps += 1
+}
+
+func (s *seededSource) Int63() int64 {
+	s.step()
+	return s.src.Int63()
+}
+
+func (s *seededSource) Uint64() uint64 {
+	s.step()
+	return s.src.Uint64()
+}
+
+func (s *seededSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.steps = 0
+}
+
+func (s *seededSource) restore(seed int64, steps uint64) {
+	s.seed = seed
+	s.steps = steps
+	s.src.Seed(blockSeed(seed, steps/seededSourceBlock))
+
+	for i := uint64(0); i < steps%seededSourceBlock; i += 1 {
+		s.src.Uint64()
+	}
+}
+
+// defaultGenerator is the source used by the *Default constructors
+type defaultGenerator struct {
+	*rand.Rand
+	src *seededSource
+}
+
+func newDefaultGenerator() *defaultGenerator {
+	src := newSeededSource(rand.Int63())
+
+	return &defaultGenerator{Rand: rand.New(src), src: src}
+}
+
+func (dg *defaultGenerator) Name() string {
+	return string(mathRand)
+}
+
+func (dg *defaultGenerator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = mathRand
+	d["seed"] = dg.src.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+// Bits returns the count of random bits of Int values
+func (dg *defaultGenerator) Bits() uint {
+	return 63
+}
+
+type defaultState struct {
+	Name  GeneratorName `json:"distributionName"`
+	Seed  int64         `json:"seed"`
+	Steps uint64        `json:"steps"`
+}
+
+func (dg *defaultGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(defaultState{Name: mathRand, Seed: dg.src.seed, Steps: dg.src.steps})
+}
+
+func (dg *defaultGenerator) UnmarshalBinary(data []byte) error {
+	var s defaultState
+	if err := unmarshalState(data, mathRand, &s); err != nil {
+		return err
+	}
+
+	if dg.src == nil {
+		dg.src = newSeededSource(s.Seed)
+		dg.Rand = rand.New(dg.src)
+	}
+
+	dg.src.restore(s.Seed, s.Steps)
+
+	return nil
+}
+
+type congruentialState struct {
+	Name GeneratorName `json:"distributionName"`
+	// Modulus 0 stands for 2^64
+	Modulus           uint64 `json:"modulus"`
+	Multiplier        uint64 `json:"multiplier"`
+	AdditiveComponent uint64 `json:"additiveComponent"`
+	InitialValue      uint64 `json:"initialValue"`
+	CurrentValue      uint64 `json:"currentValue"`
+}
+
+func (cg *CongruentialGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(congruentialState{
+		Name:              Congruential,
+		Modulus:           cg.n,
+		Multiplier:        cg.m,
+		AdditiveComponent: cg.a,
+		InitialValue:      cg.initial,
+		CurrentValue:      cg.current,
+	})
+}
+
+func (cg *CongruentialGenerator) UnmarshalBinary(data []byte) error {
+	var s congruentialState
+	if err := unmarshalState(data, Congruential, &s); err != nil {
+		return err
+	}
+
+	cg.name = Congruential
+	cg.n = s.Modulus
+	cg.m = s.Multiplier
+	cg.a = s.AdditiveComponent
+	cg.initial = s.InitialValue
+	cg.current = s.CurrentValue
+
+	return nil
+}
+
+type uniformState struct {
+	Name    GeneratorName   `json:"distributionName"`
+	Modulus int             `json:"modulus"`
+	Source  json.RawMessage `json:"source"`
+}
+
+func (ug *UniformGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(ug.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(uniformState{Name: Uniform, Modulus: ug.m, Source: src})
+}
+
+func (ug *UniformGenerator) UnmarshalBinary(data []byte) error {
+	var s uniformState
+	if err := unmarshalState(data, Uniform, &s); err != nil {
+		return err
+	}
+
+	src, err := unmarshalSource(ug.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	g, ok := src.(IntGenerator)
+	if !ok {
+		return errors.Wrap(ErrInvalidState, "source is not an int generator")
+	}
+
+	ug.name = Uniform
+	ug.g = g
+	ug.m = s.Modulus
+
+	return nil
+}
+
+type exponentialState struct {
+	Name   GeneratorName   `json:"distributionName"`
+	Rate   float64         `json:"rate"`
+	Source json.RawMessage `json:"source"`
+}
+
+func (eg *ExponentialGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(eg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(exponentialState{Name: Exponential, Rate: eg.l, Source: src})
+}
+
+func (eg *ExponentialGenerator) UnmarshalBinary(data []byte) error {
+	var s exponentialState
+	if err := unmarshalState(data, Exponential, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(eg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	eg.name = Exponential
+	eg.g = g
+	eg.l = s.Rate
+
+	return nil
+}
+
+type normalState struct {
+	Name              GeneratorName   `json:"distributionName"`
+	StandardDeviation float64         `json:"standardDeviation"`
+	Mean              float64         `json:"mean"`
+	Source            json.RawMessage `json:"source"`
+	SecondSource      json.RawMessage `json:"secondSource"`
+	// Spare is the cached second standard normal value of the polar method
+	Spare *float64 `json:"spare,omitempty"`
+}
+
+func (ng *NormalGenerator) MarshalBinary() ([]byte, error) {
+	var sources sharedSources
+
+	src, err := sources.marshal("source", ng.g)
+	if err != nil {
+		return nil, err
+	}
+
+	src2, err := sources.marshal("secondSource", ng.g2)
+	if err != nil {
+		return nil, err
+	}
+
+	s := normalState{
+		Name:              Normal,
+		StandardDeviation: ng.stdDev,
+		Mean:              ng.mean,
+		Source:            src,
+		SecondSource:      src2,
+	}
+
+	if ng.hasSpare {
+		spare := ng.spare
+		s.Spare = &spare
+	}
+
+	return json.Marshal(s)
+}
+
+func (ng *NormalGenerator) UnmarshalBinary(data []byte) error {
+	var s normalState
+	if err := unmarshalState(data, Normal, &s); err != nil {
+		return err
+	}
+
+	var sources sharedSources
+
+	g, err := sources.unmarshalFloat64("source", ng.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	g2, err := sources.unmarshalFloat64("secondSource", ng.g2, s.SecondSource)
+	if err != nil {
+		return err
+	}
+
+	ng.name = Normal
+	ng.g = g
+	ng.g2 = g2
+	ng.stdDev = s.StandardDeviation
+	ng.mean = s.Mean
+	ng.spare, ng.hasSpare = 0, s.Spare != nil
+
+	if ng.hasSpare {
+		ng.spare = *s.Spare
+	}
+
+	return nil
+}
+
+type twoDimensionalState struct {
+	Name                   GeneratorName        `json:"distributionName"`
+	Method                 TwoDimensionalMethod `json:"method,omitempty"`
+	StandardDeviationX     float64              `json:"standardDeviationX"`
+	StandardDeviationY     float64              `json:"standardDeviationY"`
+	MeanX                  float64              `json:"meanX"`
+	MeanY                  float64              `json:"meanY"`
+	CorrelationCoefficient float64              `json:"correlationCoefficient"`
+	Source                 json.RawMessage      `json:"source"`
+	SecondSource           json.RawMessage      `json:"secondSource"`
+}
+
+func (tdg *TwoDimensionalGenerator) MarshalBinary() ([]byte, error) {
+	var sources sharedSources
+
+	src, err := sources.marshal("source", tdg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	src2, err := sources.marshal("secondSource", tdg.g2)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(twoDimensionalState{
+		Name:                   TwoDimensional,
+		Method:                 tdg.method,
+		StandardDeviationX:     tdg.stdDevX,
+		StandardDeviationY:     tdg.stdDevY,
+		MeanX:                  tdg.meanX,
+		MeanY:                  tdg.meanY,
+		CorrelationCoefficient: tdg.correlationCoefficient,
+		Source:                 src,
+		SecondSource:           src2,
+	})
+}
+
+func (tdg *TwoDimensionalGenerator) UnmarshalBinary(data []byte) error {
+	var s twoDimensionalState
+	if err := unmarshalState(data, TwoDimensional, &s); err != nil {
+		return err
+	}
+
+	// states saved before the method was selectable
+	if s.Method == "" {
+		s.Method = CentralLimitMethod
+	}
+
+	var sources sharedSources
+
+	g, err := sources.unmarshalFloat64("source", tdg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	g2, err := sources.unmarshalFloat64("secondSource", tdg.g2, s.SecondSource)
+	if err != nil {
+		return err
+	}
+
+	tdg.name = TwoDimensional
+	tdg.method = s.Method
+	tdg.g = g
+	tdg.g2 = g2
+	tdg.stdDevX = s.StandardDeviationX
+	tdg.stdDevY = s.StandardDeviationY
+	tdg.meanX = s.MeanX
+	tdg.meanY = s.MeanY
+	tdg.correlationCoefficient = s.CorrelationCoefficient
+
+	return nil
+}
+
+func unmarshalState(data []byte, name GeneratorName, state interface{}) error {
+	if err := json.Unmarshal(data, state); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	var n struct {
+		Name GeneratorName `json:"distributionName"`
+	}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	if n.Name != name {
+		return errors.Wrapf(ErrInvalidState, "expected %q state, got %q", name, n.Name)
+	}
+
+	return nil
+}
+
+func marshalSource(g interface{}) (json.RawMessage, error) {
+	m, ok := g.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errors.Wrapf(ErrStateUnsupported, "%T", g)
+	}
+
+	return m.MarshalBinary()
+}
+
+// unmarshalSource restores the state of the nested generator g, when g is nil
+// a new generator of the recorded type is created
+func unmarshalSource(g interface{}, data json.RawMessage) (interface{}, error) {
+	if g == nil {
+		var n struct {
+			Name GeneratorName `json:"distributionName"`
+		}
+		if err := json.Unmarshal(data, &n); err != nil {
+			return nil, errors.Wrap(ErrInvalidState, err.Error())
+		}
+
+		switch n.Name {
+		case mathRand:
+			g = &defaultGenerator{}
+		case Congruential:
+			g = &CongruentialGenerator{}
+		case Uniform:
+			g = &UniformGenerator{}
+		case UniformBits:
+			g = &BitGenerator{}
+		case Halton:
+			g = &HaltonGenerator{}
+		case Sobol:
+			g = &SobolGenerator{}
+		case Lattice:
+			g = &LatticeGenerator{}
+		case QMCProjection:
+			g = &QMCProjectionGenerator{}
+		case Exponential:
+			g = &ExponentialGenerator{}
+		case Normal:
+			g = &NormalGenerator{}
+		case ZigguratNormal:
+			g = &ZigguratNormalGenerator{}
+		case ZigguratExponential:
+			g = &ZigguratExponentialGenerator{}
+		case Gamma:
+			g = &GammaGenerator{}
+		case Erlang:
+			g = &ErlangGenerator{}
+		case ChiSquared:
+			g = &ChiSquaredGenerator{}
+		case Beta:
+			g = &BetaGenerator{}
+		case Pareto:
+			g = &ParetoGenerator{}
+		case Lomax:
+			g = &LomaxGenerator{}
+		case Cauchy:
+			g = &CauchyGenerator{}
+		case StudentT:
+			g = &StudentTGenerator{}
+		case LogNormal:
+			g = &LogNormalGenerator{}
+		case Weibull:
+			g = &WeibullGenerator{}
+		case Stable:
+			g = &StableGenerator{}
+		case Poisson:
+			g = &PoissonGenerator{}
+		case Binomial:
+			g = &BinomialGenerator{}
+		case Geometric:
+			g = &GeometricGenerator{}
+		case NegativeBinomial:
+			g = &NegativeBinomialGenerator{}
+		case Hypergeometric:
+			g = &HypergeometricGenerator{}
+		case DiscreteUniform:
+			g = &DiscreteUniformGenerator{}
+		case TruncatedNormal:
+			g = &TruncatedNormalGenerator{}
+		case TruncatedExponential:
+			g = &TruncatedExponentialGenerator{}
+		case Truncated:
+			g = &TruncatedGenerator{}
+		case PCG32:
+			g = &PCG32Generator{}
+		case PCG64:
+			g = &PCG64Generator{}
+		case SplitMix64:
+			g = &SplitMix64Generator{}
+		case Xoshiro256StarStar:
+			g = &Xoshiro256StarStarGenerator{}
+		case Xoroshiro128Plus:
+			g = &Xoroshiro128PlusGenerator{}
+		case MT19937:
+			g = &MT19937Generator{}
+		case WichmannHill:
+			g = &WichmannHillGenerator{}
+		case LEcuyer:
+			g = &LEcuyerGenerator{}
+		case MRG32k3a:
+			g = &MRG32k3aGenerator{}
+		case LaggedFibonacci:
+			g = &LaggedFibonacciGenerator{}
+		case SubtractWithCarry:
+			g = &SubtractWithCarryGenerator{}
+		case Ranlux:
+			g = &RanluxGenerator{}
+		case Philox:
+			g = &PhiloxGenerator{}
+		case Threefry:
+			g = &ThreefryGenerator{}
+		default:
+			return nil, errors.Wrapf(ErrStateUnsupported, "unknown source %q", n.Name)
+		}
+	}
+
+	u, ok := g.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, errors.Wrapf(ErrStateUnsupported, "%T", g)
+	}
+
+	if err := u.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func unmarshalFloat64Source(g Float64Generator, data json.RawMessage) (Float64Generator, error) {
+	return toFloat64Source(unmarshalSource(g, data))
+}
+
+func unmarshalNormFloat64Source(g NormFloat64Generator, data json.RawMessage) (NormFloat64Generator, error) {
+	return toNormFloat64Source(unmarshalSource(g, data))
+}
+
+func toFloat64Source(src interface{}, err error) (Float64Generator, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := src.(Float64Generator)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidState, "source is not a float64 generator")
+	}
+
+	return f, nil
+}
+
+func toNormFloat64Source(src interface{}, err error) (NormFloat64Generator, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	n, ok := src.(NormFloat64Generator)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidState, "source is not a normal generator")
+	}
+
+	return n, nil
+}
+
+// sharedSources keeps the identity of the sources of a state with several
+// sources: a source identical to one marshaled before is stored as the JSON
+// string with the key of the first one and restored as the same generator,
+// e.g. {"source": {...}, "secondSource": "source"}
+//
+// sources sharing a nested generator (a normal generator drawing from the
+// uniform source of the same gamma generator) would be restored as
+// independent copies, so they are rejected with ErrStateUnsupported
+type sharedSources struct {
+	keys    []string
+	sources []interface{}
+}
+
+func (ss *sharedSources) marshal(key string, g interface{}) (json.RawMessage, error) {
+	for i, src := range ss.sources {
+		if sameGenerator(src, g) {
+			return json.Marshal(ss.keys[i])
+		}
+
+		if sharesSource(src, g) {
+			return nil, errors.Wrapf(ErrStateUnsupported, "%s and %s share a nested source", ss.keys[i], key)
+		}
+	}
+
+	data, err := marshalSource(g)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.keys = append(ss.keys, key)
+	ss.sources = append(ss.sources, g)
+
+	return data, nil
+}
+
+func (ss *sharedSources) unmarshal(key string, g interface{}, data json.RawMessage) (interface{}, error) {
+	var ref string
+	if err := json.Unmarshal(data, &ref); err == nil {
+		for i, k := range ss.keys {
+			if k == ref {
+				return ss.sources[i], nil
+			}
+		}
+
+		return nil, errors.Wrapf(ErrInvalidState, "%s refers to unknown source %q", key, ref)
+	}
+
+	src, err := unmarshalSource(g, data)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.keys = append(ss.keys, key)
+	ss.sources = append(ss.sources, src)
+
+	return src, nil
+}
+
+func (ss *sharedSources) unmarshalFloat64(key string, g Float64Generator, data json.RawMessage) (Float64Generator, error) {
+	return toFloat64Source(ss.unmarshal(key, g, data))
+}
+
+func (ss *sharedSources) unmarshalNormFloat64(key string, g NormFloat64Generator, data json.RawMessage) (NormFloat64Generator, error) {
+	return toNormFloat64Source(ss.unmarshal(key, g, data))
+}
+
+// sameGenerator compares generators by identity, values of incomparable types
+// are never the same
+func sameGenerator(a interface{}, b interface{}) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// sharesSource reports whether a generator is reachable from both a and b
+// through the sources the values are drawn from
+func sharesSource(a interface{}, b interface{}) bool {
+	reachable := make([]interface{}, 0)
+
+	var collect func(g interface{})
+	collect = func(g interface{}) {
+		reachable = append(reachable, g)
+		for _, src := range nestedSources(g) {
+			collect(src)
+		}
+	}
+	collect(a)
+
+	var reaches func(g interface{}) bool
+	reaches = func(g interface{}) bool {
+		for _, r := range reachable {
+			if sameGenerator(r, g) {
+				return true
+			}
+		}
+
+		for _, src := range nestedSources(g) {
+			if reaches(src) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return reaches(b)
+}
+
+// nestedSources returns the generators g draws its values from, the
+// marginals of copulas and truncated generators only supply quantiles and are
+// not listed
+func nestedSources(g interface{}) []interface{} {
+	switch s := g.(type) {
+	case *UniformGenerator:
+		return []interface{}{s.g}
+	case *BitGenerator:
+		return []interface{}{s.g}
+	case *ExponentialGenerator:
+		return []interface{}{s.g}
+	case *NormalGenerator:
+		return []interface{}{s.g, s.g2}
+	case *TwoDimensionalGenerator:
+		return []interface{}{s.g, s.g2}
+	case *MultivariateNormalGenerator:
+		return []interface{}{s.g}
+	case *CopulaGenerator:
+		return []interface{}{s.g, s.g2}
+	case *ZigguratNormalGenerator:
+		return []interface{}{s.g}
+	case *ZigguratExponentialGenerator:
+		return []interface{}{s.g}
+	case *GammaGenerator:
+		return []interface{}{s.g, s.normal}
+	case *ErlangGenerator:
+		return []interface{}{s.g}
+	case *ChiSquaredGenerator:
+		return []interface{}{s.g, s.normal}
+	case *BetaGenerator:
+		return []interface{}{s.g, s.normal}
+	case *DirichletGenerator:
+		return []interface{}{s.g, s.normal}
+	case *ParetoGenerator:
+		return []interface{}{s.g}
+	case *LomaxGenerator:
+		return []interface{}{s.g}
+	case *CauchyGenerator:
+		return []interface{}{s.g}
+	case *StudentTGenerator:
+		return []interface{}{s.g}
+	case *LogNormalGenerator:
+		return []interface{}{s.g}
+	case *WeibullGenerator:
+		return []interface{}{s.g}
+	case *StableGenerator:
+		return []interface{}{s.g}
+	case *PoissonGenerator:
+		return []interface{}{s.g}
+	case *BinomialGenerator:
+		return []interface{}{s.g}
+	case *GeometricGenerator:
+		return []interface{}{s.g}
+	case *NegativeBinomialGenerator:
+		return []interface{}{s.g, s.normal}
+	case *HypergeometricGenerator:
+		return []interface{}{s.g}
+	case *DiscreteUniformGenerator:
+		return []interface{}{s.g}
+	case *TruncatedNormalGenerator:
+		return []interface{}{s.g}
+	case *TruncatedExponentialGenerator:
+		return []interface{}{s.g}
+	case *TruncatedGenerator:
+		return []interface{}{s.g}
+	case *InverseTransformGenerator:
+		return []interface{}{s.g}
+	case *AcceptanceRejectionGenerator:
+		return []interface{}{s.g, samplerSource(s.proposal)}
+	case *MixtureGenerator:
+		sources := []interface{}{s.g}
+		for _, c := range s.components {
+			sources = append(sources, samplerSource(c))
+		}
+
+		return sources
+	case *CompoundGenerator:
+		return []interface{}{samplerSource(s.count), samplerSource(s.summand)}
+	case *QMCProjectionGenerator:
+		return []interface{}{s.sequence}
+	default:
+		return nil
+	}
+}
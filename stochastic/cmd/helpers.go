@@ -69,6 +69,7 @@ func startRunner(
 	mFn stochastic.MeanFn,
 	kFn stochastic.CorrelationFn,
 	trySafeMath, useSingleTemplate bool,
+	seed uint64,
 ) {
 	defer wg.Done()
 	ensureFolderCreated(outputFolder)
@@ -84,5 +85,6 @@ func startRunner(
 		cpusAvailable,
 		trySafeMath,
 		useSingleTemplate,
+		seed,
 	)
 }
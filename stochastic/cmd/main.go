@@ -1,17 +1,23 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/Sinu5oid/generators/stochastic"
 	"math"
-	"math/rand"
 	"runtime"
 	"sync"
 	"time"
 )
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	seed := flag.Uint64("seed", 0, "seed of the implementation streams, 0 picks one from the clock")
+
+	flag.Parse()
+
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
 
 	globalStarted := time.Now()
 	m := 0.5
@@ -50,6 +56,7 @@ func main() {
 		kFn,
 		true,
 		true,
+		*seed,
 	)
 
 	wg.Wait()
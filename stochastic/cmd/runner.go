@@ -19,11 +19,15 @@ func run(
 	numRunners int,
 	trySafeMath bool,
 	useSingleTemplate bool,
+	seed uint64,
 ) {
 	fmt.Println("allowed to use", numRunners, "routines")
+	fmt.Println("seed", seed)
 
-	implsInChan := make(chan struct{}, N)
-	implsChan := make(chan *[]float64, N)
+	// every implementation index draws from its own stream, so the results
+	// don't depend on the number of routines or their scheduling
+	implsInChan := make(chan int, N)
+	impls := make([][]float64, N)
 
 	started := time.Now()
 
@@ -32,42 +36,36 @@ func run(
 
 	wg.Add(numRunners)
 	if useSingleTemplate {
-		implementationGenerator := stochastic.BuildImplementationGenerator(mFn, kFn, n, trySafeMath)
+		implementationGenerator := stochastic.BuildStreamImplementationGenerator(mFn, kFn, n, trySafeMath, seed)
 
 		for i := 0; i < numRunners; i += 1 {
-			go func(inCh chan struct{}, outCh chan *[]float64, wg *sync.WaitGroup) {
+			go func(inCh chan int, impls [][]float64, wg *sync.WaitGroup) {
 				defer wg.Done()
 
-				for range inCh {
-					outCh <- implementationGenerator()
+				for index := range inCh {
+					impls[index] = *implementationGenerator(index)
 				}
-			}(implsInChan, implsChan, &wg)
+			}(implsInChan, impls, &wg)
 		}
 	} else {
 		for i := 0; i < numRunners; i += 1 {
-			go func(inCh chan struct{}, outCh chan *[]float64, wg *sync.WaitGroup) {
+			go func(inCh chan int, impls [][]float64, wg *sync.WaitGroup) {
 				defer wg.Done()
 
-				for range inCh {
-					outCh <- stochastic.BuildImplementation(mFn, kFn, n, trySafeMath)
+				for index := range inCh {
+					impls[index] = *stochastic.BuildStreamImplementation(mFn, kFn, n, trySafeMath, seed, index)
 				}
-			}(implsInChan, implsChan, &wg)
+			}(implsInChan, impls, &wg)
 		}
 	}
 
 	// discharge channels
 	for i := 0; i < N; i += 1 {
-		implsInChan <- struct{}{}
+		implsInChan <- i
 	}
 
 	close(implsInChan)
 	wg.Wait()
-	close(implsChan)
-
-	impls := make([][]float64, 0, N)
-	for impl := range implsChan {
-		impls = append(impls, *impl)
-	}
 
 	fmt.Println("[implementation gens] finished", numRunners, "implementation generators in", time.Since(started))
 
@@ -1,6 +1,7 @@
 package stochastic
 
 import (
+	"github.com/Sinu5oid/generators"
 	"math"
 	"math/rand"
 )
@@ -15,21 +16,16 @@ type MeanFn func(int) float64
 // k - correlation function K(t, t')
 //
 // n - time steps count
-func buildImplementation(m MeanFn, k CorrelationFn, n int, trySafeMath bool) *[]float64 {
-	devs := make([]float64, 0, n)
-	funcs := make([][]float64, 0, n)
-
-	for i := 0; i < n; i += 1 {
-		devs = append(devs, *getDev(k, i, &devs, &funcs, trySafeMath))
-		funcs = append(funcs, *getFuncRow(k, i, n, &devs, &funcs, trySafeMath))
-	}
-
-	randoms := make([]float64, 0, n)
-	for i := 0; i < n; i += 1 {
-		randoms = append(randoms, rand.NormFloat64()*math.Sqrt((devs)[i]))
-	}
+func buildImplementation(
+	m MeanFn,
+	k CorrelationFn,
+	n int,
+	trySafeMath bool,
+	normFloat64 func() float64,
+) *[]float64 {
+	devs, funcs := buildImplementationTemplate(k, n, trySafeMath)
 
-	return getImpl(m, n, &funcs, &randoms)
+	return getImpl(m, n, funcs, getRandoms(n, devs, normFloat64))
 }
 
 // buildImplementationTemplate
@@ -118,6 +114,23 @@ func getFunc(
 	}
 }
 
+func getRandoms(n int, devs *[]float64, normFloat64 func() float64) *[]float64 {
+	randoms := make([]float64, 0, n)
+	for i := 0; i < n; i += 1 {
+		randoms = append(randoms, normFloat64()*math.Sqrt((*devs)[i]))
+	}
+
+	return &randoms
+}
+
+// newStreamNormalGenerator returns the standard normal generator drawing
+// from the Philox stream (seed, index)
+func newStreamNormalGenerator(seed uint64, index int) *generators.NormalGenerator {
+	ug := generators.NewUniformGenerator(generators.NewPhiloxGenerator(seed, uint64(index)), math.MaxUint32+1)
+
+	return generators.NewNormalGenerator(ug, ug, 1, 0)
+}
+
 func getImpl(
 	m MeanFn,
 	n int,
@@ -148,17 +161,43 @@ func BuildImplementationGenerator(
 	devs, funcs := buildImplementationTemplate(kFn, n, trySafeMath)
 
 	return func() *[]float64 {
-		randoms := make([]float64, 0, n)
-		for i := 0; i < n; i += 1 {
-			randoms = append(randoms, rand.NormFloat64()*math.Sqrt((*devs)[i]))
-		}
+		return getImpl(mFn, n, funcs, getRandoms(n, devs, rand.NormFloat64))
+	}
+}
+
+// BuildStreamImplementationGenerator is BuildImplementationGenerator drawing
+// the implementation #index from its own counter-based stream (seed, index),
+// so every implementation depends only on the seed and its index, not on the
+// goroutine or the order it is built in
+func BuildStreamImplementationGenerator(
+	mFn MeanFn,
+	kFn CorrelationFn,
+	n int,
+	trySafeMath bool,
+	seed uint64,
+) func(index int) *[]float64 {
+	devs, funcs := buildImplementationTemplate(kFn, n, trySafeMath)
 
-		return getImpl(mFn, n, funcs, &randoms)
+	return func(index int) *[]float64 {
+		return getImpl(mFn, n, funcs, getRandoms(n, devs, newStreamNormalGenerator(seed, index).NormFloat64))
 	}
 }
 
 func BuildImplementation(mFn MeanFn, kFn CorrelationFn, n int, trySafeMath bool) *[]float64 {
-	return buildImplementation(mFn, kFn, n, trySafeMath)
+	return buildImplementation(mFn, kFn, n, trySafeMath, rand.NormFloat64)
+}
+
+// BuildStreamImplementation is BuildImplementation drawing from the stream
+// (seed, index), see BuildStreamImplementationGenerator
+func BuildStreamImplementation(
+	mFn MeanFn,
+	kFn CorrelationFn,
+	n int,
+	trySafeMath bool,
+	seed uint64,
+	index int,
+) *[]float64 {
+	return buildImplementation(mFn, kFn, n, trySafeMath, newStreamNormalGenerator(seed, index).NormFloat64)
 }
 
 func GetT(i int, h float64) float64 {
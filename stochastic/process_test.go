@@ -50,3 +50,31 @@ func BenchmarkBuildImplementationGenerator(b *testing.B) {
 		_ = implementationGenerator()
 	}
 }
+
+func TestBuildStreamImplementation(t *testing.T) {
+	h := 0.025
+	n := 12
+
+	mFn := func(i int) float64 { return 0.5 }
+	kFn := func(i int, i2 int) float64 {
+		return 1 / (1 + math.Pow(GetT(i, h)-GetT(i2, h), 2))
+	}
+
+	implementationGenerator := BuildStreamImplementationGenerator(mFn, kFn, n, true, 42)
+
+	// implementations are built in reverse order, each must only depend on its index
+	for index := 9; index >= 0; index -= 1 {
+		expected := *BuildStreamImplementation(mFn, kFn, n, true, 42, index)
+		got := *implementationGenerator(index)
+
+		for i := range expected {
+			if math.Abs(expected[i]-got[i]) > 1e-12 {
+				t.Fatalf("implementation %d: step %d: expected %f got %f", index, i, expected[i], got[i])
+			}
+		}
+	}
+
+	if a, b := *implementationGenerator(0), *implementationGenerator(1); a[0] == b[0] {
+		t.Error("implementations 0 and 1 are equal")
+	}
+}
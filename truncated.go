@@ -0,0 +1,581 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+)
+
+// truncatedNormalMethod is the method of TruncatedNormalGenerator selected
+// for the standardized interval
+type truncatedNormalMethod int
+
+const (
+	// normalRejection draws normal values until one falls into the interval,
+	// used for wide intervals containing 0
+	normalRejection truncatedNormalMethod = iota
+	// uniformRejection proposes uniform values of the interval
+	uniformRejection
+	// exponentialRejection is Robert's translated exponential proposal for
+	// intervals in the tail
+	exponentialRejection
+)
+
+// TruncatedNormalGenerator produces normal values conditioned on [a, b] with
+// Robert's algorithms (Simulation of truncated normal variables, 1995), the
+// acceptance rate stays bounded for intervals far in the tail, where the
+// naive rejection of normal values stalls
+//
+// a may be -Inf and b may be +Inf
+type TruncatedNormalGenerator struct {
+	name   GeneratorName
+	g      Float64Generator
+	stdDev float64
+	mean   float64
+	a      float64
+	b      float64
+
+	// the standardized interval, mirrored to the positive tail when b <= 0
+	alpha    float64
+	beta     float64
+	mirrored bool
+	method   truncatedNormalMethod
+	lambda   float64
+	normal   ZigguratNormalGenerator
+}
+
+func (tg *TruncatedNormalGenerator) Name() string {
+	return string(tg.name)
+}
+
+func (tg *TruncatedNormalGenerator) String() string {
+	d := make(map[string]interface{}, 6)
+
+	d["distributionName"] = tg.name
+	d["standardDeviation"] = tg.stdDev
+	d["mean"] = tg.mean
+	d["lowerBound"] = boundNumber(tg.a)
+	d["upperBound"] = boundNumber(tg.b)
+
+	describeSource(d, "source", tg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewTruncatedNormalGeneratorDefault(standardDeviation float64, mean float64, a float64, b float64) (*TruncatedNormalGenerator, error) {
+	return NewTruncatedNormalGenerator(newDefaultGenerator(), standardDeviation, mean, a, b)
+}
+
+func NewTruncatedNormalGenerator(
+	generator Float64Generator,
+	standardDeviation float64,
+	mean float64,
+	a float64,
+	b float64,
+) (*TruncatedNormalGenerator, error) {
+	if err := validatePositive("standard deviation", standardDeviation); err != nil {
+		return nil, err
+	}
+
+	if err := validateBounds(a, b); err != nil {
+		return nil, err
+	}
+
+	tg := &TruncatedNormalGenerator{
+		name:   TruncatedNormal,
+		g:      generator,
+		stdDev: standardDeviation,
+		mean:   mean,
+		a:      a,
+		b:      b,
+		alpha:  (a - mean) / standardDeviation,
+		beta:   (b - mean) / standardDeviation,
+		normal: ZigguratNormalGenerator{name: ZigguratNormal, g: generator, stdDev: 1},
+	}
+
+	if tg.beta <= 0 {
+		tg.alpha, tg.beta, tg.mirrored = -tg.beta, -tg.alpha, true
+	}
+
+	alpha, beta := tg.alpha, tg.beta
+
+	switch {
+	case alpha <= 0:
+		// the interval contains 0
+		if beta-alpha > math.Sqrt(2*math.Pi) {
+			tg.method = normalRejection
+		} else {
+			tg.method = uniformRejection
+		}
+	default:
+		// Robert's optimal rate of the exponential proposal and the bound of
+		// the intervals for which the uniform proposal is more efficient
+		root := math.Sqrt(alpha*alpha + 4)
+		tg.lambda = (alpha + root) / 2
+
+		if beta > alpha+2*math.Sqrt(math.E)/(alpha+root)*math.Exp((alpha*alpha-alpha*root)/4) {
+			tg.method = exponentialRejection
+		} else {
+			tg.method = uniformRejection
+		}
+	}
+
+	return tg, nil
+}
+
+// Bounds returns the interval [a, b] of the values
+func (tg *TruncatedNormalGenerator) Bounds() (float64, float64) {
+	return tg.a, tg.b
+}
+
+func (tg *TruncatedNormalGenerator) TruncatedFloat64() float64 {
+	z := tg.standardFloat64()
+	if tg.mirrored {
+		z = -z
+	}
+
+	// rounding must not move the value out of the interval
+	return math.Min(math.Max(tg.mean+tg.stdDev*z, tg.a), tg.b)
+}
+
+func (tg *TruncatedNormalGenerator) standardFloat64() float64 {
+	alpha, beta := tg.alpha, tg.beta
+
+	switch tg.method {
+	case normalRejection:
+		for {
+			if z := tg.normal.NormFloat64(); z >= alpha && z <= beta {
+				return z
+			}
+		}
+	case exponentialRejection:
+		for {
+			z := alpha - math.Log(openUniform(tg.g))/tg.lambda
+			if z > beta {
+				continue
+			}
+
+			if tg.g.Float64() <= math.Exp(-(z-tg.lambda)*(z-tg.lambda)/2) {
+				return z
+			}
+		}
+	default:
+		for {
+			z := alpha + (beta-alpha)*tg.g.Float64()
+
+			var rho float64
+			if alpha > 0 {
+				rho = math.Exp((alpha*alpha - z*z) / 2)
+			} else {
+				rho = math.Exp(-z * z / 2)
+			}
+
+			if tg.g.Float64() <= rho {
+				return z
+			}
+		}
+	}
+}
+
+// CDF returns the distribution function of the truncated values
+func (tg *TruncatedNormalGenerator) CDF(x float64) float64 {
+	if x <= tg.a {
+		return 0
+	}
+
+	if x >= tg.b {
+		return 1
+	}
+
+	z := (x - tg.mean) / tg.stdDev
+	if tg.mirrored {
+		// P(X <= x) is P(-Z >= -z) of the mirrored interval
+		return 1 - standardTruncatedNormalCDF(-z, tg.alpha, tg.beta)
+	}
+
+	return standardTruncatedNormalCDF(z, tg.alpha, tg.beta)
+}
+
+// standardTruncatedNormalCDF is P(Z <= z | alpha <= Z <= beta), beta > 0,
+// the tail is computed with log survival functions to avoid the cancellation
+func standardTruncatedNormalCDF(z float64, alpha float64, beta float64) float64 {
+	if alpha <= 0 {
+		return (standardNormalCDF(z) - standardNormalCDF(alpha)) / (standardNormalCDF(beta) - standardNormalCDF(alpha))
+	}
+
+	qa := logNormalSurvival(alpha)
+
+	return math.Expm1(logNormalSurvival(z)-qa) / math.Expm1(logNormalSurvival(beta)-qa)
+}
+
+// logNormalSurvival returns log P(Z > z) of the standard normal Z, z >= 0,
+// large z use the asymptotic expansion of the Mills ratio
+func logNormalSurvival(z float64) float64 {
+	if math.IsInf(z, 1) {
+		return math.Inf(-1)
+	}
+
+	if z < 30 {
+		return math.Log(math.Erfc(z/math.Sqrt2) / 2)
+	}
+
+	z2 := z * z
+	series := 1 - 1/z2 + 3/(z2*z2) - 15/(z2*z2*z2)
+
+	return -z2/2 - math.Log(z*math.Sqrt(2*math.Pi)) + math.Log(series)
+}
+
+// TruncatedExponentialGenerator produces exponential values with rate l
+// conditioned on [a, b], 0 <= a, the values are drawn by inversion without
+// rejection, b may be +Inf
+type TruncatedExponentialGenerator struct {
+	name GeneratorName
+	g    Float64Generator
+	l    float64
+	a    float64
+	b    float64
+}
+
+func (tg *TruncatedExponentialGenerator) Name() string {
+	return string(tg.name)
+}
+
+func (tg *TruncatedExponentialGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = tg.name
+	d["rate"] = tg.l
+	d["lowerBound"] = boundNumber(tg.a)
+	d["upperBound"] = boundNumber(tg.b)
+
+	describeSource(d, "source", tg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewTruncatedExponentialGeneratorDefault(rate float64, a float64, b float64) (*TruncatedExponentialGenerator, error) {
+	return NewTruncatedExponentialGenerator(newDefaultGenerator(), rate, a, b)
+}
+
+func NewTruncatedExponentialGenerator(generator Float64Generator, rate float64, a float64, b float64) (*TruncatedExponentialGenerator, error) {
+	if err := validatePositive("rate", rate); err != nil {
+		return nil, err
+	}
+
+	if err := validateBounds(a, b); err != nil {
+		return nil, err
+	}
+
+	if a < 0 {
+		return nil, errors.Wrap(ErrInvalidParameters, "lower bound is negative")
+	}
+
+	return &TruncatedExponentialGenerator{name: TruncatedExponential, g: generator, l: rate, a: a, b: b}, nil
+}
+
+// Bounds returns the interval [a, b] of the values
+func (tg *TruncatedExponentialGenerator) Bounds() (float64, float64) {
+	return tg.a, tg.b
+}
+
+// TruncatedFloat64 uses the lack of memory: the value is a plus the exponential
+// value truncated to [0, b - a]
+func (tg *TruncatedExponentialGenerator) TruncatedFloat64() float64 {
+	return tg.Quantile(tg.g.Float64())
+}
+
+func (tg *TruncatedExponentialGenerator) Quantile(p float64) float64 {
+	width := (tg.b - tg.a) * tg.l
+	x := tg.a - math.Log1p(p*math.Expm1(-width))/tg.l
+
+	return math.Min(x, tg.b)
+}
+
+// CDF returns the distribution function of the truncated values
+func (tg *TruncatedExponentialGenerator) CDF(x float64) float64 {
+	if x <= tg.a {
+		return 0
+	}
+
+	if x >= tg.b {
+		return 1
+	}
+
+	return math.Expm1(-(x-tg.a)*tg.l) / math.Expm1(-(tg.b-tg.a)*tg.l)
+}
+
+// truncatedSearchSteps is the count of bisection steps locating the
+// probabilities of the bounds, enough for the 2^-64 resolution
+const truncatedSearchSteps = 64
+
+// TruncatedGenerator conditions any QuantileGenerator on [a, b] by inversion:
+// the value is Quantile(F(a) + U (F(b) - F(a))), F(a) and F(b) are found by
+// bisection of the quantile function once
+//
+// the probability of the interval must be resolved by float64 probabilities
+// near 0 and 1, far tails need a dedicated generator such as
+// TruncatedNormalGenerator
+type TruncatedGenerator struct {
+	name GeneratorName
+	g    Float64Generator
+	d    QuantileGenerator
+	a    float64
+	b    float64
+
+	pa float64
+	pb float64
+}
+
+func (tg *TruncatedGenerator) Name() string {
+	return string(tg.name)
+}
+
+func (tg *TruncatedGenerator) String() string {
+	d := make(map[string]interface{}, 5)
+
+	d["distributionName"] = tg.name
+	d["distribution"] = json.RawMessage(tg.d.String())
+	d["lowerBound"] = boundNumber(tg.a)
+	d["upperBound"] = boundNumber(tg.b)
+
+	describeSource(d, "source", tg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewTruncatedGeneratorDefault(distribution QuantileGenerator, a float64, b float64) (*TruncatedGenerator, error) {
+	return NewTruncatedGenerator(newDefaultGenerator(), distribution, a, b)
+}
+
+// NewTruncatedGenerator builds the truncation of distribution, generator
+// provides the uniform values which are transformed by its quantile function
+func NewTruncatedGenerator(generator Float64Generator, distribution QuantileGenerator, a float64, b float64) (*TruncatedGenerator, error) {
+	if err := validateBounds(a, b); err != nil {
+		return nil, err
+	}
+
+	pa := quantileCDF(distribution, a)
+	pb := quantileCDF(distribution, b)
+
+	if !(pb > pa) {
+		return nil, errors.Wrapf(ErrInvalidParameters, "interval [%g, %g] has no probability", a, b)
+	}
+
+	return &TruncatedGenerator{name: Truncated, g: generator, d: distribution, a: a, b: b, pa: pa, pb: pb}, nil
+}
+
+// Bounds returns the interval [a, b] of the values
+func (tg *TruncatedGenerator) Bounds() (float64, float64) {
+	return tg.a, tg.b
+}
+
+func (tg *TruncatedGenerator) TruncatedFloat64() float64 {
+	// the quantile of 0 is the lower bound, -Inf for unbounded intervals
+	return tg.Quantile(openUniform(tg.g))
+}
+
+func (tg *TruncatedGenerator) Quantile(p float64) float64 {
+	x := tg.d.Quantile(tg.pa + p*(tg.pb-tg.pa))
+
+	return math.Min(math.Max(x, tg.a), tg.b)
+}
+
+// CDF returns the distribution function of the truncated values, every call
+// bisects the quantile function
+func (tg *TruncatedGenerator) CDF(x float64) float64 {
+	if x <= tg.a {
+		return 0
+	}
+
+	if x >= tg.b {
+		return 1
+	}
+
+	return (quantileCDF(tg.d, x) - tg.pa) / (tg.pb - tg.pa)
+}
+
+// quantileCDF inverts the quantile function of d, the result is the largest
+// p with Quantile(p) <= x
+func quantileCDF(d QuantileGenerator, x float64) float64 {
+	if math.IsInf(x, -1) {
+		return 0
+	}
+
+	if math.IsInf(x, 1) {
+		return 1
+	}
+
+	low, high := 0.0, 1.0
+	for i := 0; i < truncatedSearchSteps; i += 1 {
+		middle := (low + high) / 2
+		if middle == low || middle == high {
+			break
+		}
+
+		if d.Quantile(middle) <= x {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return low
+}
+
+func validateBounds(a float64, b float64) error {
+	if math.IsNaN(a) || math.IsNaN(b) || !(a < b) {
+		return errors.Wrapf(ErrInvalidParameters, "bounds [%g, %g] don't form an interval", a, b)
+	}
+
+	return nil
+}
+
+// boundNumber formats the bound for JSON, infinite bounds are null
+func boundNumber(x float64) interface{} {
+	if math.IsInf(x, 0) {
+		return nil
+	}
+
+	return x
+}
+
+// truncatedState is shared by the truncated generators, infinite bounds are
+// stored as null
+type truncatedState struct {
+	Name              GeneratorName   `json:"distributionName"`
+	StandardDeviation float64         `json:"standardDeviation,omitempty"`
+	Mean              float64         `json:"mean,omitempty"`
+	Rate              float64         `json:"rate,omitempty"`
+	LowerBound        *float64        `json:"lowerBound"`
+	UpperBound        *float64        `json:"upperBound"`
+	Source            json.RawMessage `json:"source"`
+	Distribution      json.RawMessage `json:"distribution,omitempty"`
+}
+
+func marshalTruncatedState(s truncatedState, a float64, b float64, g Float64Generator) ([]byte, error) {
+	var err error
+
+	if s.Source, err = marshalSource(g); err != nil {
+		return nil, err
+	}
+
+	if !math.IsInf(a, 0) {
+		s.LowerBound = &a
+	}
+
+	if !math.IsInf(b, 0) {
+		s.UpperBound = &b
+	}
+
+	return json.Marshal(s)
+}
+
+func unmarshalTruncatedState(data []byte, name GeneratorName, g Float64Generator) (truncatedState, float64, float64, Float64Generator, error) {
+	var s truncatedState
+	if err := unmarshalState(data, name, &s); err != nil {
+		return s, 0, 0, nil, err
+	}
+
+	a, b := math.Inf(-1), math.Inf(1)
+	if s.LowerBound != nil {
+		a = *s.LowerBound
+	}
+
+	if s.UpperBound != nil {
+		b = *s.UpperBound
+	}
+
+	g, err := unmarshalFloat64Source(g, s.Source)
+	if err != nil {
+		return s, 0, 0, nil, err
+	}
+
+	return s, a, b, g, nil
+}
+
+func (tg *TruncatedNormalGenerator) MarshalBinary() ([]byte, error) {
+	return marshalTruncatedState(truncatedState{
+		Name:              TruncatedNormal,
+		StandardDeviation: tg.stdDev,
+		Mean:              tg.mean,
+	}, tg.a, tg.b, tg.g)
+}
+
+func (tg *TruncatedNormalGenerator) UnmarshalBinary(data []byte) error {
+	s, a, b, g, err := unmarshalTruncatedState(data, TruncatedNormal, tg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewTruncatedNormalGenerator(g, s.StandardDeviation, s.Mean, a, b)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*tg = *restored
+
+	return nil
+}
+
+func (tg *TruncatedExponentialGenerator) MarshalBinary() ([]byte, error) {
+	return marshalTruncatedState(truncatedState{Name: TruncatedExponential, Rate: tg.l}, tg.a, tg.b, tg.g)
+}
+
+func (tg *TruncatedExponentialGenerator) UnmarshalBinary(data []byte) error {
+	s, a, b, g, err := unmarshalTruncatedState(data, TruncatedExponential, tg.g)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewTruncatedExponentialGenerator(g, s.Rate, a, b)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*tg = *restored
+
+	return nil
+}
+
+func (tg *TruncatedGenerator) MarshalBinary() ([]byte, error) {
+	d, err := marshalSource(tg.d)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalTruncatedState(truncatedState{Name: Truncated, Distribution: d}, tg.a, tg.b, tg.g)
+}
+
+func (tg *TruncatedGenerator) UnmarshalBinary(data []byte) error {
+	s, a, b, g, err := unmarshalTruncatedState(data, Truncated, tg.g)
+	if err != nil {
+		return err
+	}
+
+	d, err := unmarshalQuantileSource(tg.d, s.Distribution)
+	if err != nil {
+		return err
+	}
+
+	restored, err := NewTruncatedGenerator(g, d, a, b)
+	if err != nil {
+		return errors.Wrap(ErrInvalidState, err.Error())
+	}
+
+	*tg = *restored
+
+	return nil
+}
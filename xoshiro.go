@@ -0,0 +1,254 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/bits"
+)
+
+// SplitMix64Generator is Steele, Lea and Flood's SplitMix64, mostly used to
+// expand a single seed into the state of other generators
+type SplitMix64Generator struct {
+	name  GeneratorName
+	seed  uint64
+	state uint64
+}
+
+func NewSplitMix64Generator(seed uint64) *SplitMix64Generator {
+	return &SplitMix64Generator{name: SplitMix64, seed: seed, state: seed}
+}
+
+func (g *SplitMix64Generator) Name() string {
+	return string(g.name)
+}
+
+func (g *SplitMix64Generator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *SplitMix64Generator) Uint64() uint64 {
+	g.state += 0x9e3779b97f4a7c15
+
+	z := g.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return z ^ (z >> 31)
+}
+
+func (g *SplitMix64Generator) Int() int {
+	return int(g.Uint64() >> 1)
+}
+
+func (g *SplitMix64Generator) Bits() uint {
+	return 63
+}
+
+func (g *SplitMix64Generator) Seed(seed int64) {
+	g.seed = uint64(seed)
+	g.state = g.seed
+}
+
+// Xoshiro256StarStarGenerator is Blackman and Vigna's xoshiro256**
+type Xoshiro256StarStarGenerator struct {
+	name GeneratorName
+	seed uint64
+	s    [4]uint64
+}
+
+// NewXoshiro256StarStarGenerator expands seed into the state with SplitMix64
+func NewXoshiro256StarStarGenerator(seed uint64) *Xoshiro256StarStarGenerator {
+	g := &Xoshiro256StarStarGenerator{name: Xoshiro256StarStar}
+	g.Seed(int64(seed))
+
+	return g
+}
+
+func (g *Xoshiro256StarStarGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *Xoshiro256StarStarGenerator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *Xoshiro256StarStarGenerator) Uint64() uint64 {
+	s := &g.s
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+
+	return result
+}
+
+func (g *Xoshiro256StarStarGenerator) Int() int {
+	return int(g.Uint64() >> 1)
+}
+
+func (g *Xoshiro256StarStarGenerator) Bits() uint {
+	return 63
+}
+
+func (g *Xoshiro256StarStarGenerator) Seed(seed int64) {
+	g.seed = uint64(seed)
+
+	sm := NewSplitMix64Generator(g.seed)
+	for i := range g.s {
+		g.s[i] = sm.Uint64()
+	}
+}
+
+// Xoroshiro128PlusGenerator is Blackman and Vigna's xoroshiro128+, its lowest
+// bits have low linear complexity, Int() keeps the upper 53 bits only, as the
+// authors recommend for floating-point values
+type Xoroshiro128PlusGenerator struct {
+	name GeneratorName
+	seed uint64
+	s    [2]uint64
+}
+
+// NewXoroshiro128PlusGenerator expands seed into the state with SplitMix64
+func NewXoroshiro128PlusGenerator(seed uint64) *Xoroshiro128PlusGenerator {
+	g := &Xoroshiro128PlusGenerator{name: Xoroshiro128Plus}
+	g.Seed(int64(seed))
+
+	return g
+}
+
+func (g *Xoroshiro128PlusGenerator) Name() string {
+	return string(g.name)
+}
+
+func (g *Xoroshiro128PlusGenerator) String() string {
+	d := make(map[string]interface{}, 2)
+
+	d["distributionName"] = g.name
+	d["seed"] = g.seed
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func (g *Xoroshiro128PlusGenerator) Uint64() uint64 {
+	s0, s1 := g.s[0], g.s[1]
+	result := s0 + s1
+
+	s1 ^= s0
+	g.s[0] = bits.RotateLeft64(s0, 24) ^ s1 ^ (s1 << 16)
+	g.s[1] = bits.RotateLeft64(s1, 37)
+
+	return result
+}
+
+func (g *Xoroshiro128PlusGenerator) Int() int {
+	return int(g.Uint64() >> 11)
+}
+
+func (g *Xoroshiro128PlusGenerator) Bits() uint {
+	return 53
+}
+
+func (g *Xoroshiro128PlusGenerator) Seed(seed int64) {
+	g.seed = uint64(seed)
+
+	sm := NewSplitMix64Generator(g.seed)
+	g.s[0] = sm.Uint64()
+	g.s[1] = sm.Uint64()
+}
+
+type xoshiroState struct {
+	Name  GeneratorName `json:"distributionName"`
+	Seed  uint64        `json:"seed"`
+	State []uint64      `json:"state"`
+}
+
+func (g *SplitMix64Generator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(xoshiroState{Name: SplitMix64, Seed: g.seed, State: []uint64{g.state}})
+}
+
+func (g *SplitMix64Generator) UnmarshalBinary(data []byte) error {
+	var s xoshiroState
+	if err := unmarshalState(data, SplitMix64, &s); err != nil {
+		return err
+	}
+
+	if len(s.State) != 1 {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	g.name = SplitMix64
+	g.seed = s.Seed
+	g.state = s.State[0]
+
+	return nil
+}
+
+func (g *Xoshiro256StarStarGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(xoshiroState{Name: Xoshiro256StarStar, Seed: g.seed, State: g.s[:]})
+}
+
+func (g *Xoshiro256StarStarGenerator) UnmarshalBinary(data []byte) error {
+	var s xoshiroState
+	if err := unmarshalState(data, Xoshiro256StarStar, &s); err != nil {
+		return err
+	}
+
+	if len(s.State) != len(g.s) {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	g.name = Xoshiro256StarStar
+	g.seed = s.Seed
+	copy(g.s[:], s.State)
+
+	return nil
+}
+
+func (g *Xoroshiro128PlusGenerator) MarshalBinary() ([]byte, error) {
+	return json.Marshal(xoshiroState{Name: Xoroshiro128Plus, Seed: g.seed, State: g.s[:]})
+}
+
+func (g *Xoroshiro128PlusGenerator) UnmarshalBinary(data []byte) error {
+	var s xoshiroState
+	if err := unmarshalState(data, Xoroshiro128Plus, &s); err != nil {
+		return err
+	}
+
+	if len(s.State) != len(g.s) {
+		return errors.Wrap(ErrInvalidState, "state length mismatch")
+	}
+
+	g.name = Xoroshiro128Plus
+	g.seed = s.Seed
+	copy(g.s[:], s.State)
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+const (
+	zigguratNormalLayers = 128
+	// zigguratNormalR is the start of the tail, zigguratNormalV is the area of every layer
+	zigguratNormalR = 3.442619855899
+	zigguratNormalV = 9.91256303526217e-3
+
+	zigguratExponentialLayers = 256
+	zigguratExponentialR      = 7.697117470131487
+	zigguratExponentialV      = 3.949659822581572e-3
+)
+
+// zigguratTable holds the right edges x[i] of the layers and f(x[i]), the
+// layer i covers [0, x[i]) × [f(x[i]), f(x[i+1])), x[0] is the width of the
+// base layer which includes the tail beyond x[1] = r
+type zigguratTable struct {
+	x []float64
+	f []float64
+}
+
+var (
+	zigguratNormal = newZigguratTable(zigguratNormalLayers, zigguratNormalR, zigguratNormalV,
+		func(x float64) float64 { return math.Exp(-x * x / 2) },
+		func(y float64) float64 { return math.Sqrt(-2 * math.Log(y)) },
+	)
+	zigguratExponential = newZigguratTable(zigguratExponentialLayers, zigguratExponentialR, zigguratExponentialV,
+		func(x float64) float64 { return math.Exp(-x) },
+		func(y float64) float64 { return -math.Log(y) },
+	)
+)
+
+func newZigguratTable(layers int, r float64, v float64, f func(float64) float64, inverse func(float64) float64) zigguratTable {
+	t := zigguratTable{x: make([]float64, layers+1), f: make([]float64, layers+1)}
+
+	t.x[0] = v / f(r)
+	t.x[1] = r
+
+	for i := 1; i < layers-1; i += 1 {
+		t.x[i+1] = inverse(v/t.x[i] + f(t.x[i]))
+	}
+
+	t.x[layers] = 0
+
+	for i := range t.x {
+		t.f[i] = f(t.x[i])
+	}
+
+	return t
+}
+
+// ZigguratNormalGenerator produces normal values with Marsaglia and Tsang's
+// Ziggurat method, about 98% of the values cost a single uniform value, a
+// multiplication and a comparison
+//
+// the top 8 bits of every uniform value select the sign and the layer, the
+// rest is the position inside the layer, so the source must provide at least
+// 32 bits of resolution (IntGenerators are plugged in with UniformGenerator)
+type ZigguratNormalGenerator struct {
+	name   GeneratorName
+	g      Float64Generator
+	stdDev float64
+	mean   float64
+}
+
+func (zg *ZigguratNormalGenerator) Name() string {
+	return string(zg.name)
+}
+
+func (zg *ZigguratNormalGenerator) String() string {
+	d := make(map[string]interface{}, 4)
+
+	d["distributionName"] = zg.name
+	d["standardDeviation"] = zg.stdDev
+	d["mean"] = zg.mean
+
+	describeSource(d, "source", zg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewZigguratNormalGeneratorDefault() *ZigguratNormalGenerator {
+	return &ZigguratNormalGenerator{name: ZigguratNormal, g: newDefaultGenerator(), stdDev: 1, mean: 0}
+}
+
+func NewZigguratNormalGenerator(generator Float64Generator, standardDeviation float64, mean float64) *ZigguratNormalGenerator {
+	return &ZigguratNormalGenerator{name: ZigguratNormal, g: generator, stdDev: standardDeviation, mean: mean}
+}
+
+func (zg *ZigguratNormalGenerator) NormFloat64() float64 {
+	t := &zigguratNormal
+
+	for {
+		u := zg.g.Float64() * 2 * zigguratNormalLayers
+		k := int(u)
+		i := k & (zigguratNormalLayers - 1)
+		// the top bit is the sign, branch-free as it is unpredictable
+		sign := float64(1 - 2*(k/zigguratNormalLayers))
+
+		x := (u - float64(k)) * t.x[i]
+
+		if x < t.x[i+1] {
+			return sign*x*zg.stdDev + zg.mean
+		}
+
+		if i == 0 {
+			// tail beyond r, Marsaglia's method
+			for {
+				a := -math.Log(openUniform(zg.g)) / zigguratNormalR
+				b := -math.Log(openUniform(zg.g))
+
+				if 2*b >= a*a {
+					return sign*(zigguratNormalR+a)*zg.stdDev + zg.mean
+				}
+			}
+		}
+
+		// the wedge between the layer and the curve
+		if t.f[i]+zg.g.Float64()*(t.f[i+1]-t.f[i]) < math.Exp(-x*x/2) {
+			return sign*x*zg.stdDev + zg.mean
+		}
+	}
+}
+
+// Quantile returns the inverse of the normal distribution function
+func (zg *ZigguratNormalGenerator) Quantile(p float64) float64 {
+	return zg.mean + zg.stdDev*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+func (zg *ZigguratNormalGenerator) PDF(x float64) float64 {
+	return normalPDF(x, zg.stdDev, zg.mean)
+}
+
+func (zg *ZigguratNormalGenerator) CDF(x float64) float64 {
+	return normalCDF(x, zg.stdDev, zg.mean)
+}
+
+func (zg *ZigguratNormalGenerator) Mean() float64 {
+	return zg.mean
+}
+
+func (zg *ZigguratNormalGenerator) Variance() float64 {
+	return zg.stdDev * zg.stdDev
+}
+
+// ZigguratExponentialGenerator produces exponential values with the Ziggurat
+// method, the values are distributed as the ones of ExponentialGenerator with
+// the same rate
+//
+// the top 8 bits of every uniform value select the layer, the source must
+// provide at least 32 bits of resolution
+type ZigguratExponentialGenerator struct {
+	name GeneratorName
+	g    Float64Generator
+	l    float64
+}
+
+func (zg *ZigguratExponentialGenerator) Name() string {
+	return string(zg.name)
+}
+
+func (zg *ZigguratExponentialGenerator) String() string {
+	d := make(map[string]interface{}, 3)
+
+	d["distributionName"] = zg.name
+	d["rate"] = zg.l
+
+	describeSource(d, "source", zg.g)
+
+	if b, err := json.Marshal(d); err != nil {
+		return fmt.Sprintf("%v", d)
+	} else {
+		return string(b)
+	}
+}
+
+func NewZigguratExponentialGeneratorDefault() *ZigguratExponentialGenerator {
+	return &ZigguratExponentialGenerator{name: ZigguratExponential, g: newDefaultGenerator(), l: 1}
+}
+
+func NewZigguratExponentialGenerator(generator Float64Generator, rate float64) *ZigguratExponentialGenerator {
+	return &ZigguratExponentialGenerator{name: ZigguratExponential, g: generator, l: rate}
+}
+
+func (zg *ZigguratExponentialGenerator) ExpFloat64() float64 {
+	t := &zigguratExponential
+	offset := 0.0
+
+	for {
+		u := zg.g.Float64() * zigguratExponentialLayers
+		i := int(u)
+		x := (u - float64(i)) * t.x[i]
+
+		if x < t.x[i+1] {
+			return (offset + x) / zg.l
+		}
+
+		if i == 0 {
+			// the tail beyond r is the exponential distribution shifted by r
+			offset += zigguratExponentialR
+			continue
+		}
+
+		if t.f[i]+zg.g.Float64()*(t.f[i+1]-t.f[i]) < math.Exp(-x) {
+			return (offset + x) / zg.l
+		}
+	}
+}
+
+// Quantile returns the inverse of the distribution function of ExpFloat64 values
+func (zg *ZigguratExponentialGenerator) Quantile(p float64) float64 {
+	return -math.Log1p(-p) / zg.l
+}
+
+func (zg *ZigguratExponentialGenerator) PDF(x float64) float64 {
+	return exponentialPDF(x, zg.l)
+}
+
+func (zg *ZigguratExponentialGenerator) CDF(x float64) float64 {
+	return exponentialCDF(x, zg.l)
+}
+
+func (zg *ZigguratExponentialGenerator) Mean() float64 {
+	return 1 / zg.l
+}
+
+func (zg *ZigguratExponentialGenerator) Variance() float64 {
+	return 1 / (zg.l * zg.l)
+}
+
+func (zg *ZigguratNormalGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(zg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(normalState{Name: ZigguratNormal, StandardDeviation: zg.stdDev, Mean: zg.mean, Source: src})
+}
+
+func (zg *ZigguratNormalGenerator) UnmarshalBinary(data []byte) error {
+	var s normalState
+	if err := unmarshalState(data, ZigguratNormal, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(zg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	zg.name = ZigguratNormal
+	zg.g = g
+	zg.stdDev = s.StandardDeviation
+	zg.mean = s.Mean
+
+	return nil
+}
+
+func (zg *ZigguratExponentialGenerator) MarshalBinary() ([]byte, error) {
+	src, err := marshalSource(zg.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(exponentialState{Name: ZigguratExponential, Rate: zg.l, Source: src})
+}
+
+func (zg *ZigguratExponentialGenerator) UnmarshalBinary(data []byte) error {
+	var s exponentialState
+	if err := unmarshalState(data, ZigguratExponential, &s); err != nil {
+		return err
+	}
+
+	g, err := unmarshalFloat64Source(zg.g, s.Source)
+	if err != nil {
+		return err
+	}
+
+	zg.name = ZigguratExponential
+	zg.g = g
+	zg.l = s.Rate
+
+	return nil
+}